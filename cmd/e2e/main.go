@@ -0,0 +1,71 @@
+// Command e2e runs the declarative scenarios in scenarios/ (or -scenarios)
+// against a deployed server (-base-url) or, by default, against a full
+// server started in-process from the environment configuration.
+package main
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"cleanarch/internal/app"
+	"cleanarch/internal/config"
+	"cleanarch/internal/e2e"
+	"cleanarch/internal/httpclient"
+)
+
+//go:embed scenarios/*.json
+var builtin embed.FS
+
+func main() {
+	baseURL := flag.String("base-url", "", "server to test; empty starts one in-process")
+	dir := flag.String("scenarios", "", "directory of scenario files; empty uses the built-in scenarios")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for the whole run")
+	flag.Parse()
+
+	var fsys fs.FS = builtin
+	root := "scenarios"
+	if *dir != "" {
+		fsys, root = os.DirFS(*dir), "."
+	}
+	scenarios, err := e2e.LoadScenarios(fsys, root)
+	if err != nil {
+		log.Fatalf("load scenarios: %v", err)
+	}
+
+	if *baseURL == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			log.Fatalf("config error: %v", err)
+		}
+		log.SetOutput(io.Discard) // keep request logs out of the report
+		ts := httptest.NewServer(app.NewServer(cfg, app.ServerOptions{}).HTTP.Handler)
+		defer ts.Close()
+		*baseURL = ts.URL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	runner := &e2e.Runner{BaseURL: *baseURL, Client: httpclient.New(httpclient.Options{Name: "e2e"})}
+	failed := 0
+	for _, s := range scenarios {
+		res := runner.Run(ctx, s)
+		if res.Passed {
+			fmt.Printf("PASS %s (%s)\n", res.Scenario, res.Duration.Round(time.Millisecond))
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s: %s\n", res.Scenario, res.Failure)
+	}
+	fmt.Printf("%d passed, %d failed\n", len(scenarios)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,50 +2,52 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"net/http"
+	"log/slog"
 	"os/signal"
 	"syscall"
-	"time"
 
-	httpadapter "cleanarch/internal/adapter/http"
 	"cleanarch/internal/app"
-	"cleanarch/internal/repository/memory"
-	"cleanarch/internal/usecase"
+	"cleanarch/internal/config"
+	"cleanarch/internal/usecase/fake"
 )
 
 func main() {
-	// Initialize dependencies
-	repo := memory.NewInMemoryUserRepository()
-	service := usecase.NewUserService(repo)
-	handler := httpadapter.NewUserHandler(service)
-
-	mux := app.NewRouter(handler)
-
-	srv := &http.Server{
-		Addr:         ":8080",
-		Handler:      app.WithLogging(mux),
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	mock := flag.Bool("mock", false, "serve deterministic fake data instead of a real repository")
+	mockLatency := flag.Duration("mock-latency", 0, "latency added to every call in mock mode")
+	mockErrorRate := flag.Float64("mock-error-rate", 0, "fraction of calls (0..1) failing in mock mode")
+	mockSeed := flag.Int64("mock-seed", 1, "seed for generated mock data")
+	recordDir := flag.String("record", "", "record request/response fixtures into this directory")
+	replayDir := flag.String("replay", "", "serve recorded fixtures from this directory instead of the API")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config error: %v", err)
 	}
 
+	opts := app.ServerOptions{RecordDir: *recordDir, ReplayDir: *replayDir}
+	if *mock {
+		log.Printf("mock mode: latency=%s error-rate=%g seed=%d", *mockLatency, *mockErrorRate, *mockSeed)
+		opts.Mock = &fake.Options{Latency: *mockLatency, ErrorRate: *mockErrorRate, Seed: *mockSeed}
+	}
+	server := app.NewServer(cfg, opts)
+	server.Diagnostics.Log(slog.Default())
+
 	// Start server
-	go func() {
-		log.Printf("HTTP server listening on %s", srv.Addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
-		}
-	}()
+	if err := server.Start(context.Background()); err != nil {
+		log.Fatalf("startup failed: %v", err)
+	}
 
 	// Graceful shutdown
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 	<-shutdownCtx.Done()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := server.Stop(ctx); err != nil {
 		log.Printf("graceful shutdown failed: %v", err)
 	} else {
 		log.Println("server shutdown complete")
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// AuditHandler exposes the audit log at /audit.
+type AuditHandler struct {
+	service   usecase.AuditUsecase
+	cursors   *Cursors
+	sensitive domain.SensitiveFields
+}
+
+// NewAuditHandler accepts WithCursors and WithSensitiveFields; other
+// options are ignored.
+func NewAuditHandler(service usecase.AuditUsecase, opts ...HandlerOption) *AuditHandler {
+	o := newHandlerOptions(opts)
+	return &AuditHandler{service: service, cursors: o.cursors, sensitive: o.sensitive}
+}
+
+// ListAudit handles GET /audit?entity_id=&limit=&offset=&cursor=, listing
+// entries newest first as a ListResponse.
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var query domain.AuditQuery
+	if v := q.Get("entity_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, r, fmt.Errorf("%w: entity_id must be a positive integer", domain.ErrInvalidFilter))
+			return
+		}
+		query.EntityID = id
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := h.cursors.Open(v)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		query.Cursor = cursor
+	}
+	page, err := parsePageRequest(q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query.PageRequest = page
+
+	entries, err := h.service.ListAudit(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	items := entries.Items
+	if mask := maskFor(w, r, h.sensitive); !mask.Empty() {
+		items = make([]*domain.AuditEntry, len(entries.Items))
+		for i, e := range entries.Items {
+			masked := *e
+			masked.Before, masked.After = mask.Mask(e.Before), mask.Mask(e.After)
+			items[i] = &masked
+		}
+	}
+	writeList(w, r, items, entries.Total, entries.NextCursor, query.Limit, h.cursors)
+}
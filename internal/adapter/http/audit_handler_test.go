@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+	"cleanarch/internal/usecase"
+)
+
+func TestAuditHandler(t *testing.T) {
+	log := memory.NewInMemoryAuditRepository()
+	for _, id := range []int64{1, 2, 1} {
+		_, _ = log.Append(context.Background(), &domain.AuditEntry{Action: domain.AuditCreate, EntityID: id})
+	}
+	h := NewAuditHandler(usecase.NewAuditService(log))
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ListAudit(rec, httptest.NewRequest("GET", target, nil))
+		return rec
+	}
+
+	t.Run("Pages through an entity's entries", func(t *testing.T) {
+		rec := serve("/audit?entity_id=1&limit=1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var list ListResponse[domain.AuditEntry]
+		_ = json.NewDecoder(rec.Body).Decode(&list)
+		if len(list.Items) != 1 || list.Items[0].ID != 3 {
+			t.Fatalf("expected entry 3, got %+v", list.Items)
+		}
+		if list.Total != 2 || list.Limit != 1 || list.NextCursor == "" {
+			t.Errorf("expected a total of 2, limit 1 and a cursor, got %+v", list)
+		}
+
+		rec = serve("/audit?entity_id=1&limit=1&cursor=" + list.NextCursor)
+		list = ListResponse[domain.AuditEntry]{}
+		_ = json.NewDecoder(rec.Body).Decode(&list)
+		if len(list.Items) != 1 || list.Items[0].ID != 1 || list.NextCursor != "" {
+			t.Errorf("expected only entry 1 on the last page, got %+v", list)
+		}
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, target := range []string{"/audit?entity_id=x", "/audit?limit=x", "/audit?cursor=forged"} {
+			if rec := serve(target); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400 for %s, got %d", target, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// BulkHandler exposes bulk user changes and the operations tracking them.
+type BulkHandler struct {
+	service usecase.BulkUsecase
+}
+
+func NewBulkHandler(service usecase.BulkUsecase) *BulkHandler {
+	return &BulkHandler{service: service}
+}
+
+// BulkUpdate handles POST /users:bulkUpdate. The filter uses the list
+// endpoint's expression syntax. Dry runs answer 200 with the match count;
+// otherwise the update runs in the background and the response is 202 with
+// the operation to poll.
+func (h *BulkHandler) BulkUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Filter string            `json:"filter"`
+		Patch  usecase.UserPatch `json:"patch"`
+		DryRun bool              `json:"dry_run"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	filter, err := parseBulkFilter(req.Filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result, err := h.service.BulkUpdate(r.Context(), usecase.BulkUpdate{Filter: filter, Patch: req.Patch, DryRun: req.DryRun})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, bulkStatus(result), result)
+}
+
+// BulkDelete handles POST /users:bulkDelete. A dry run answers 200 with
+// the match count and a confirmation token; repeating the request with the
+// same filter and cascade and "confirm" set to the token starts the delete
+// and answers 202 with the operation. Like DELETE /users/{id}, referenced
+// users are only deleted with "cascade": true.
+func (h *BulkHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Filter  string `json:"filter"`
+		Cascade bool   `json:"cascade"`
+		DryRun  bool   `json:"dry_run"`
+		Confirm string `json:"confirm"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	filter, err := parseBulkFilter(req.Filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result, err := h.service.BulkDelete(r.Context(), usecase.BulkDelete{Filter: filter, Cascade: req.Cascade, DryRun: req.DryRun, Confirm: req.Confirm})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, bulkStatus(result), result)
+}
+
+// GetOperation handles GET /operations/{id}.
+func (h *BulkHandler) GetOperation(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	op, err := h.service.Operation(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if op.State == usecase.OperationRunning {
+		w.Header().Set("Retry-After", "1")
+	}
+	writeJSON(w, r, http.StatusOK, op)
+}
+
+// parseBulkFilter parses a bulk request's filter expression. An empty
+// filter is passed on so the use case can reject it.
+func parseBulkFilter(src string) (domain.Filter, error) {
+	if src == "" {
+		return domain.Filter{}, nil
+	}
+	expr, err := domain.ParseExpr(src)
+	if err != nil {
+		return domain.Filter{}, fmt.Errorf("%w: %w", domain.ErrInvalidFilter, err)
+	}
+	return domain.Filter{Expr: expr}, nil
+}
+
+func bulkStatus(result *usecase.BulkResult) int {
+	if result.Operation == nil {
+		return http.StatusOK
+	}
+	return http.StatusAccepted
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func serveBulk(h *BulkHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users:bulkUpdate", h.BulkUpdate)
+	mux.HandleFunc("POST /users:bulkDelete", h.BulkDelete)
+	mux.HandleFunc("GET /operations/{id}", h.GetOperation)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestBulkHandler(t *testing.T) {
+	var got domain.Filter
+	users := &mocks.UserUsecaseMock{
+		ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{Items: []*domain.User{{ID: 1, Status: domain.StatusSuspended}, {ID: 2, Status: domain.StatusSuspended}}}, nil
+		},
+	}
+	bulk := usecase.NewBulkService(users)
+	defer bulk.Stop(context.Background())
+	h := NewBulkHandler(bulk)
+
+	t.Run("Dry run", func(t *testing.T) {
+		rec := serveBulk(h, "POST", "/users:bulkUpdate", `{"filter":"email endsWith \"@corp.com\"","patch":{"status":"suspended"},"dry_run":true}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		var result usecase.BulkResult
+		_ = json.NewDecoder(rec.Body).Decode(&result)
+		if result.Matched != 2 || result.Operation != nil {
+			t.Errorf("expected 2 matches and no operation, got %+v", result)
+		}
+		if got.Expr == nil || got.Expr.String() != `email endsWith "@corp.com"` {
+			t.Errorf("expected the filter expression to be passed on, got %v", got.Expr)
+		}
+	})
+
+	t.Run("Accepted update is pollable", func(t *testing.T) {
+		rec := serveBulk(h, "POST", "/users:bulkUpdate", `{"filter":"id > 0","patch":{"status":"suspended"}}`)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body)
+		}
+		var result usecase.BulkResult
+		_ = json.NewDecoder(rec.Body).Decode(&result)
+		if result.Operation == nil || result.Operation.Total != 2 {
+			t.Fatalf("expected an operation over 2 users, got %+v", result)
+		}
+
+		rec = serveBulk(h, "GET", "/operations/1", "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		rec := serveBulk(h, "POST", "/users:bulkUpdate", `{"filter":"bogus ==","patch":{"status":"suspended"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Missing filter", func(t *testing.T) {
+		rec := serveBulk(h, "POST", "/users:bulkUpdate", `{"patch":{"status":"suspended"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Delete without confirmation", func(t *testing.T) {
+		rec := serveBulk(h, "POST", "/users:bulkDelete", `{"filter":"id > 0"}`)
+		if rec.Code != http.StatusPreconditionRequired {
+			t.Errorf("expected status 428, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Delete after a dry run", func(t *testing.T) {
+		users.DeleteUserFunc = func(ctx context.Context, id int64, version int64, cascade bool) error { return nil }
+		rec := serveBulk(h, "POST", "/users:bulkDelete", `{"filter":"id > 0","dry_run":true}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		var preview usecase.BulkResult
+		_ = json.NewDecoder(rec.Body).Decode(&preview)
+		if preview.Confirmation == nil {
+			t.Fatal("expected a confirmation token")
+		}
+
+		body, _ := json.Marshal(map[string]string{"filter": "id > 0", "confirm": preview.Confirmation.Token})
+		if rec := serveBulk(h, "POST", "/users:bulkDelete", string(body)); rec.Code != http.StatusAccepted {
+			t.Errorf("expected status 202, got %d: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("Delete passes cascade", func(t *testing.T) {
+		cascades := make(chan bool, 2)
+		bulk := usecase.NewBulkService(&mocks.UserUsecaseMock{
+			ListUsersFunc: users.ListUsersFunc,
+			DeleteUserFunc: func(ctx context.Context, id int64, version int64, cascade bool) error {
+				cascades <- cascade
+				return nil
+			},
+		})
+		defer bulk.Stop(context.Background())
+		h := NewBulkHandler(bulk)
+
+		rec := serveBulk(h, "POST", "/users:bulkDelete", `{"filter":"id > 0","cascade":true,"dry_run":true}`)
+		var preview usecase.BulkResult
+		_ = json.NewDecoder(rec.Body).Decode(&preview)
+		if preview.Confirmation == nil {
+			t.Fatalf("expected a confirmation token, got %d: %s", rec.Code, rec.Body)
+		}
+
+		body, _ := json.Marshal(map[string]any{"filter": "id > 0", "cascade": true, "confirm": preview.Confirmation.Token})
+		if rec := serveBulk(h, "POST", "/users:bulkDelete", string(body)); rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body)
+		}
+		for range 2 {
+			if cascade := <-cascades; !cascade {
+				t.Error("expected a cascading delete")
+			}
+		}
+	})
+
+	t.Run("Unknown operation", func(t *testing.T) {
+		if rec := serveBulk(h, "GET", "/operations/99", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// DefaultListCacheTTL is how long a coalesced list result is reused.
+const DefaultListCacheTTL = 250 * time.Millisecond
+
+// listCoalescer collapses identical list queries. The first request for a
+// key runs the query; requests for the same key that arrive while it runs,
+// or within ttl after it succeeds, share its result. Keys include the
+// service's last-modified time, so a write is visible to the next request
+// rather than after the TTL. Errors are handed to waiters but not cached.
+type listCoalescer struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*listCall
+}
+
+type listCall struct {
+	done    chan struct{}
+	page    *domain.Page[domain.User]
+	err     error
+	expires time.Time
+}
+
+func newListCoalescer(ttl time.Duration) *listCoalescer {
+	if ttl <= 0 {
+		ttl = DefaultListCacheTTL
+	}
+	return &listCoalescer{ttl: ttl, now: time.Now, entries: make(map[string]*listCall)}
+}
+
+// listKey normalizes a list query: parameters are sorted, and ones that
+// only change how the response renders are dropped.
+func listKey(lastModified time.Time, query url.Values) string {
+	q := make(url.Values, len(query))
+	for k, v := range query {
+		if k == "tz" {
+			continue
+		}
+		q[k] = v
+	}
+	return lastModified.UTC().Format(time.RFC3339Nano) + "?" + q.Encode()
+}
+
+// do returns the result for key, calling fn if no call for key is running
+// or fresh. The shared call is detached from the caller's cancellation so
+// one client going away doesn't fail everyone waiting on it.
+func (c *listCoalescer) do(ctx context.Context, key string, fn func(context.Context) (*domain.Page[domain.User], error)) (*domain.Page[domain.User], error) {
+	c.mu.Lock()
+	now := c.now()
+	call, ok := c.entries[key]
+	if ok && (call.expires.IsZero() || now.Before(call.expires)) {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.page, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c.sweep(now)
+	call = &listCall{done: make(chan struct{})}
+	c.entries[key] = call
+	c.mu.Unlock()
+
+	call.page, call.err = fn(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	if call.err != nil {
+		delete(c.entries, key)
+	} else {
+		call.expires = c.now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(call.done)
+	return call.page, call.err
+}
+
+// sweep drops expired entries. The caller holds c.mu.
+func (c *listCoalescer) sweep(now time.Time) {
+	for key, call := range c.entries {
+		if !call.expires.IsZero() && !now.Before(call.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/metrics"
+)
+
+// Serialization metrics, labeled by route ("POST /api/v1/users").
+var (
+	requestBodyBytes  = metrics.NewHistogramVec("http_request_body_bytes", metrics.SizeBuckets)
+	responseBodyBytes = metrics.NewHistogramVec("http_response_body_bytes", metrics.SizeBuckets)
+	decodeSeconds     = metrics.NewHistogramVec("http_json_decode_seconds", metrics.LatencyBuckets)
+	encodeSeconds     = metrics.NewHistogramVec("http_json_encode_seconds", metrics.LatencyBuckets)
+)
+
+// SetHistogramBuckets replaces the serialization histograms' bucket bounds:
+// latency in seconds for the JSON timings, size in bytes for the bodies.
+// Call it before serving requests; observations made so far are discarded.
+func SetHistogramBuckets(latency, size []float64) {
+	requestBodyBytes.SetBounds(size)
+	responseBodyBytes.SetBounds(size)
+	decodeSeconds.SetBounds(latency)
+	encodeSeconds.SetBounds(latency)
+}
+
+// routeLabel identifies the matched route rather than the concrete path so
+// metric cardinality stays bounded.
+func routeLabel(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return r.Method + " unmatched"
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	route := routeLabel(r)
+	start := time.Now()
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(v)
+	encodeSeconds.With(route).Observe(time.Since(start).Seconds())
+	responseBodyBytes.With(route).Observe(float64(buf.Len()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	route := routeLabel(r)
+	body := &countingReader{r: r.Body}
+	start := time.Now()
+	err := json.NewDecoder(body).Decode(v)
+	decodeSeconds.With(route).Observe(time.Since(start).Seconds())
+	requestBodyBytes.With(route).Observe(float64(body.n))
+	return err
+}
+
+// decodeRequest decodes the body into v and checks it against v's validate
+// tags. On failure it writes the error response and returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := decodeJSON(r, v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, r, http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)})
+			return false
+		}
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return false
+	}
+	if err := domain.Validate(v); err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodecMetrics(t *testing.T) {
+	t.Run("Observations are labeled by route pattern", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /echo/{id}", func(w http.ResponseWriter, r *http.Request) {
+			var v map[string]string
+			_ = decodeJSON(r, &v)
+			writeJSON(w, r, http.StatusOK, v)
+		})
+		route := "POST /echo/{id}"
+		decodes := decodeSeconds.With(route).Count()
+		encodes := encodeSeconds.With(route).Count()
+		bytesIn := requestBodyBytes.With(route).Sum()
+
+		body := `{"name":"John Doe"}`
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/echo/42", strings.NewReader(body)))
+
+		if got := decodeSeconds.With(route).Count() - decodes; got != 1 {
+			t.Errorf("expected 1 decode observation, got %d", got)
+		}
+		if got := encodeSeconds.With(route).Count() - encodes; got != 1 {
+			t.Errorf("expected 1 encode observation, got %d", got)
+		}
+		if got := requestBodyBytes.With(route).Sum() - bytesIn; got != float64(len(body)) {
+			t.Errorf("expected %d request bytes, got %g", len(body), got)
+		}
+	})
+}
+
+func TestDecodeRequest(t *testing.T) {
+	decode := func(body string, limit int64) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Body = http.MaxBytesReader(rec, req.Body, limit)
+		var v struct {
+			Name string `json:"name" validate:"required"`
+		}
+		if decodeRequest(rec, req, &v) {
+			return http.StatusOK
+		}
+		return rec.Code
+	}
+
+	t.Run("Valid body is accepted", func(t *testing.T) {
+		if code := decode(`{"name":"John Doe"}`, 1024); code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", code)
+		}
+	})
+
+	t.Run("Invalid JSON and fields are 400", func(t *testing.T) {
+		if code := decode(`{`, 1024); code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", code)
+		}
+		if code := decode(`{"name":""}`, 1024); code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", code)
+		}
+	})
+
+	t.Run("Oversized body is 413", func(t *testing.T) {
+		if code := decode(`{"name":"John Doe"}`, 8); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status 413, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+
+	"cleanarch/internal/usecase"
+)
+
+// ConsistencyHandler exposes admin endpoints for the orphaned record check.
+type ConsistencyHandler struct {
+	checks usecase.ConsistencyUsecase
+}
+
+func NewConsistencyHandler(checks usecase.ConsistencyUsecase) *ConsistencyHandler {
+	return &ConsistencyHandler{checks: checks}
+}
+
+// Report handles GET /admin/consistency, returning the latest report.
+func (h *ConsistencyHandler) Report(w http.ResponseWriter, r *http.Request) {
+	report := h.checks.LastReport()
+	if report == nil {
+		writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "no consistency check has run yet"})
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// Run handles POST /admin/consistency, running a check now.
+func (h *ConsistencyHandler) Run(w http.ResponseWriter, r *http.Request) {
+	report, err := h.checks.Run(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/usecase"
+)
+
+// stubConsistency reports one orphan per run.
+type stubConsistency struct {
+	last *usecase.ConsistencyReport
+}
+
+func (s *stubConsistency) Run(ctx context.Context) (*usecase.ConsistencyReport, error) {
+	s.last = &usecase.ConsistencyReport{
+		Checked: 3,
+		Orphans: []usecase.Orphan{{UserRecord: usecase.UserRecord{Kind: "profile", ID: 7, UserID: 7}}},
+	}
+	return s.last, nil
+}
+
+func (s *stubConsistency) LastReport() *usecase.ConsistencyReport {
+	return s.last
+}
+
+func TestConsistencyHandler(t *testing.T) {
+	h := NewConsistencyHandler(&stubConsistency{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/consistency", h.Report)
+	mux.HandleFunc("POST /admin/consistency", h.Run)
+	serveConsistency := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, "/admin/consistency", nil))
+		return rec
+	}
+
+	if rec := serveConsistency("GET"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 before a run, got %d", rec.Code)
+	}
+	rec := serveConsistency("POST")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"user_id":7`) {
+		t.Errorf("expected a report with the orphan, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serveConsistency("GET"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"checked":3`) {
+		t.Errorf("expected the last report, got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"cleanarch/internal/usecase"
+)
+
+// CredentialHandler exposes a user's password at /users/{id}/password. It
+// only accepts passwords; hashes never leave the service.
+type CredentialHandler struct {
+	service usecase.CredentialUsecase
+}
+
+func NewCredentialHandler(service usecase.CredentialUsecase) *CredentialHandler {
+	return &CredentialHandler{service: service}
+}
+
+// SetPassword handles PUT /users/{id}/password with {"password": "..."}.
+func (h *CredentialHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		Password string `json:"password" validate:"required"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := h.service.SetPassword(r.Context(), id, req.Password); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CredentialHandler) DeletePassword(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	if err := h.service.DeletePassword(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/password"
+	"cleanarch/internal/repository/memory"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func serveCredentials(h *CredentialHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /users/{id}/password", h.SetPassword)
+	mux.HandleFunc("DELETE /users/{id}/password", h.DeletePassword)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestCredentialHandler(t *testing.T) {
+	users := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id != 1 {
+				return nil, domain.ErrUserNotFound
+			}
+			return &domain.User{ID: 1}, nil
+		},
+	}
+	service := usecase.NewCredentialService(memory.NewInMemoryCredentialRepository(), users, password.PBKDF2{Iterations: 1000})
+	h := NewCredentialHandler(service)
+
+	t.Run("Set password", func(t *testing.T) {
+		rec := serveCredentials(h, "PUT", "/users/1/password", `{"password":"correct horse"}`)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d: %s", rec.Code, rec.Body)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("expected no body, got %s", rec.Body)
+		}
+		if err := service.VerifyPassword(context.Background(), 1, "correct horse"); err != nil {
+			t.Errorf("expected the password to verify, got %v", err)
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		if rec := serveCredentials(h, "PUT", "/users/1/password", `{"password":"short"}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		if rec := serveCredentials(h, "PUT", "/users/2/password", `{"password":"correct horse"}`); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Delete password", func(t *testing.T) {
+		if rec := serveCredentials(h, "DELETE", "/users/1/password", ""); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		if rec := serveCredentials(h, "DELETE", "/users/1/password", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
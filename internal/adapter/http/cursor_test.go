@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+func TestCursors(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newCursors := func(key string) *Cursors {
+		c := NewCursors([]byte(key), time.Hour)
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		c := newCursors("secret")
+		got, err := c.Open(c.Seal("abc"))
+		if err != nil || got != "abc" {
+			t.Errorf("expected abc, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("Tampered cursors are rejected", func(t *testing.T) {
+		c := newCursors("secret")
+		sealed := c.Seal("abc")
+		payload, mac, _ := strings.Cut(sealed, ".")
+		for _, bad := range []string{
+			"",
+			"abc",
+			payload + "x." + mac,
+			payload + "." + strings.Repeat("A", len(mac)),
+			newCursors("other").Seal("abc"),
+		} {
+			if _, err := c.Open(bad); !errors.Is(err, domain.ErrInvalidFilter) {
+				t.Errorf("expected ErrInvalidFilter for %q, got %v", bad, err)
+			}
+		}
+	})
+
+	t.Run("Expired cursors are rejected", func(t *testing.T) {
+		c := newCursors("secret")
+		sealed := c.Seal("abc")
+		now = now.Add(time.Hour + time.Second)
+		if _, err := c.Open(sealed); !errors.Is(err, domain.ErrCursorExpired) {
+			t.Errorf("expected ErrCursorExpired, got %v", err)
+		}
+	})
+}
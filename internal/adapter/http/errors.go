@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// errorStatus maps domain and use case errors to HTTP status codes.
+// Anything unrecognised is a 500.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrViewNotFound),
+		errors.Is(err, domain.ErrOrgNotFound),
+		errors.Is(err, domain.ErrProfileNotFound),
+		errors.Is(err, domain.ErrCredentialNotFound),
+		errors.Is(err, domain.ErrMemberNotFound),
+		errors.Is(err, usecase.ErrOperationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrDuplicateEmail),
+		errors.Is(err, domain.ErrDuplicateExternalID),
+		errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrAlreadyMember),
+		errors.Is(err, domain.ErrUserReferenced):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrCursorExpired):
+		return http.StatusGone
+	case errors.Is(err, usecase.ErrConfirmationRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, domain.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, domain.ErrInvalidInput),
+		errors.Is(err, domain.ErrInvalidFilter),
+		errors.Is(err, usecase.ErrRuleViolation):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError responds with the status for err. Internal errors are logged
+// and reported without detail.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("%s error: %v", routeLabel(r), err)
+		writeJSON(w, r, status, map[string]string{"error": "internal error"})
+		return
+	}
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		writeJSON(w, r, status, validationResponse{Error: err.Error(), Errors: invalid.Fields})
+		return
+	}
+	writeJSON(w, r, status, map[string]string{"error": err.Error()})
+}
+
+// validationResponse keeps the "error" summary other errors have and adds
+// one entry per invalid field.
+type validationResponse struct {
+	Error  string              `json:"error"`
+	Errors []domain.FieldError `json:"errors"`
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// OrganizationHandler exposes HTTP endpoints for organizations and their members.
+type OrganizationHandler struct {
+	service   usecase.OrganizationUsecase
+	sensitive domain.SensitiveFields
+}
+
+// NewOrganizationHandler accepts WithSensitiveFields; other options are
+// ignored.
+func NewOrganizationHandler(service usecase.OrganizationUsecase, opts ...HandlerOption) *OrganizationHandler {
+	o := newHandlerOptions(opts)
+	return &OrganizationHandler{service: service, sensitive: o.sensitive}
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	org, err := h.service.CreateOrganization(r.Context(), req.Name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.service.ListOrganizations(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	org, err := h.service.GetOrganization(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	if err := h.service.DeleteOrganization(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMembers handles GET /orgs/{id}/members.
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	users, err := h.service.Members(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, maskUsers(maskFor(w, r, h.sensitive), users))
+}
+
+// AddMember handles POST /orgs/{id}/members with {"user_id": n}.
+func (h *OrganizationHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		UserID int64 `json:"user_id" validate:"required,min=1"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := h.service.AddMember(r.Context(), id, req.UserID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember handles DELETE /orgs/{id}/members/{user_id}.
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+		return
+	}
+	if err := h.service.RemoveMember(r.Context(), id, userID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func serveOrgs(h *OrganizationHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /orgs", h.CreateOrganization)
+	mux.HandleFunc("GET /orgs", h.ListOrganizations)
+	mux.HandleFunc("GET /orgs/{id}", h.GetOrganization)
+	mux.HandleFunc("DELETE /orgs/{id}", h.DeleteOrganization)
+	mux.HandleFunc("GET /orgs/{id}/members", h.ListMembers)
+	mux.HandleFunc("POST /orgs/{id}/members", h.AddMember)
+	mux.HandleFunc("DELETE /orgs/{id}/members/{user_id}", h.RemoveMember)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestOrganizationHandler(t *testing.T) {
+	users := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id != 7 {
+				return nil, domain.ErrUserNotFound
+			}
+			return &domain.User{ID: 7, Name: "Ann"}, nil
+		},
+	}
+	h := NewOrganizationHandler(usecase.NewOrganizationService(memory.NewInMemoryOrganizationRepository(), users))
+
+	t.Run("Create organization", func(t *testing.T) {
+		rec := serveOrgs(h, "POST", "/orgs", `{"name":"Corp"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body)
+		}
+		var org domain.Organization
+		_ = json.NewDecoder(rec.Body).Decode(&org)
+		if org.ID != 1 || org.Name != "Corp" {
+			t.Errorf("unexpected organization %+v", org)
+		}
+	})
+
+	t.Run("Add and remove a member", func(t *testing.T) {
+		if rec := serveOrgs(h, "POST", "/orgs/1/members", `{"user_id":7}`); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d: %s", rec.Code, rec.Body)
+		}
+		rec := serveOrgs(h, "GET", "/orgs/1/members", "")
+		var members []domain.User
+		_ = json.NewDecoder(rec.Body).Decode(&members)
+		if len(members) != 1 || members[0].Name != "Ann" {
+			t.Errorf("expected Ann, got %v", members)
+		}
+		if rec := serveOrgs(h, "DELETE", "/orgs/1/members/7", ""); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			method, target, body string
+			status               int
+		}{
+			{"POST", "/orgs", `{"name":""}`, http.StatusBadRequest},
+			{"GET", "/orgs/99", "", http.StatusNotFound},
+			{"POST", "/orgs/1/members", `{}`, http.StatusBadRequest},
+			{"POST", "/orgs/1/members", `{"user_id":8}`, http.StatusNotFound},
+			{"DELETE", "/orgs/1/members/7", "", http.StatusNotFound},
+			{"DELETE", "/orgs/1/members/x", "", http.StatusBadRequest},
+		}
+		for _, c := range cases {
+			if rec := serveOrgs(h, c.method, c.target, c.body); rec.Code != c.status {
+				t.Errorf("%s %s: expected status %d, got %d", c.method, c.target, c.status, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"cleanarch/internal/domain"
+)
+
+// ListResponse is the body of every paginated list endpoint. Total counts
+// all matches, not just Items; NextCursor is a sealed cursor for the next
+// page, omitted on the last one; Limit is the page size that was applied.
+type ListResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// writeList writes items as a ListResponse. It also sets X-Total-Count
+// and, when more remain, X-Next-Cursor, which clients that only read
+// headers still rely on, and a Link header to the next page.
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T, total int, nextCursor string, limit int, cursors *Cursors) {
+	resp := ListResponse[T]{Items: items, Total: total, Limit: limit}
+	if resp.Items == nil {
+		resp.Items = []T{}
+	}
+	if nextCursor != "" {
+		resp.NextCursor = cursors.Seal(nextCursor)
+		w.Header().Set("X-Next-Cursor", resp.NextCursor)
+		w.Header().Set("Link", "<"+nextPageURL(r, resp.NextCursor)+`>; rel="next"`)
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// nextPageURL is the request's URL with cursor in place of any cursor or
+// offset, so the filters, sort and limit carry over. It is relative to
+// the request, as RFC 8288 allows.
+func nextPageURL(r *http.Request, cursor string) string {
+	q := r.URL.Query()
+	q.Del("offset")
+	q.Set("cursor", cursor)
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// parsePageRequest reads the limit and offset query parameters. The limit
+// defaults to DefaultPageLimit; range checks are left to the use case.
+func parsePageRequest(q url.Values) (domain.PageRequest, error) {
+	var page domain.PageRequest
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return page, fmt.Errorf("%w: limit must be an integer", domain.ErrInvalidFilter)
+		}
+		page.Limit = limit
+	}
+	if page.Limit == 0 {
+		page.Limit = domain.DefaultPageLimit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			return page, fmt.Errorf("%w: offset must be an integer", domain.ErrInvalidFilter)
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
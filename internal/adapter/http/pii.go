@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+
+	"cleanarch/internal/domain"
+)
+
+// WithSensitiveFields masks fields in the users a handler returns unless
+// the caller's principal has the domain.ScopePIIRead scope.
+func WithSensitiveFields(f domain.SensitiveFields) HandlerOption {
+	return func(o *handlerOptions) { o.sensitive = f }
+}
+
+// scopesHeader is the request header the server reads the caller's scopes
+// from.
+const scopesHeader = "X-Scopes"
+
+// varyByScope marks the response as depending on the caller's scopes when
+// any fields are sensitive, so shared caches neither store it nor serve it
+// to a caller with other scopes.
+func varyByScope(w http.ResponseWriter, f domain.SensitiveFields) {
+	if f.Empty() {
+		return
+	}
+	if !slices.Contains(w.Header().Values("Vary"), scopesHeader) {
+		w.Header().Add("Vary", scopesHeader)
+	}
+	w.Header().Set("Cache-Control", "private")
+}
+
+// maskFor returns the fields to mask in the response to r, none when the
+// caller may read personal data, and marks the response as varying by
+// scope.
+func maskFor(w http.ResponseWriter, r *http.Request, f domain.SensitiveFields) domain.SensitiveFields {
+	varyByScope(w, f)
+	if f.Empty() || domain.PrincipalFrom(r.Context()).HasScope(domain.ScopePIIRead) {
+		return domain.SensitiveFields{}
+	}
+	return f
+}
+
+// maskUsers returns users with the fields masked. Users are copied, not
+// modified, since results may be shared between requests.
+func maskUsers(f domain.SensitiveFields, users []*domain.User) []*domain.User {
+	if f.Empty() {
+		return users
+	}
+	result := make([]*domain.User, len(users))
+	for i, u := range users {
+		result[i] = f.Mask(u)
+	}
+	return result
+}
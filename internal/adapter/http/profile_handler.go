@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// ProfileHandler exposes a user's profile at /users/{id}/profile.
+type ProfileHandler struct {
+	service usecase.ProfileUsecase
+}
+
+func NewProfileHandler(service usecase.ProfileUsecase) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	profile, err := h.service.GetProfile(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, profile)
+}
+
+// PutProfile creates or replaces the profile; omitted fields are cleared.
+func (h *ProfileHandler) PutProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		Bio       string           `json:"bio"`
+		Phone     string           `json:"phone"`
+		Addresses []domain.Address `json:"addresses"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	profile, err := h.service.PutProfile(r.Context(), domain.Profile{
+		UserID:    id,
+		Bio:       req.Bio,
+		Phone:     req.Phone,
+		Addresses: req.Addresses,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, profile)
+}
+
+func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	if err := h.service.DeleteProfile(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func serveProfiles(h *ProfileHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/profile", h.GetProfile)
+	mux.HandleFunc("PUT /users/{id}/profile", h.PutProfile)
+	mux.HandleFunc("DELETE /users/{id}/profile", h.DeleteProfile)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestProfileHandler(t *testing.T) {
+	users := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if id != 1 {
+				return nil, domain.ErrUserNotFound
+			}
+			return &domain.User{ID: 1}, nil
+		},
+	}
+	h := NewProfileHandler(usecase.NewProfileService(memory.NewInMemoryProfileRepository(), users))
+
+	t.Run("Missing profile", func(t *testing.T) {
+		if rec := serveProfiles(h, "GET", "/users/1/profile", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Put then get", func(t *testing.T) {
+		body := `{"bio":"Hi","addresses":[{"line1":"1 Main St","city":"Springfield","country":"US"}]}`
+		if rec := serveProfiles(h, "PUT", "/users/1/profile", body); rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		rec := serveProfiles(h, "GET", "/users/1/profile", "")
+		var profile domain.Profile
+		_ = json.NewDecoder(rec.Body).Decode(&profile)
+		if profile.UserID != 1 || profile.Bio != "Hi" || len(profile.Addresses) != 1 {
+			t.Errorf("unexpected profile %+v", profile)
+		}
+	})
+
+	t.Run("Invalid profile", func(t *testing.T) {
+		if rec := serveProfiles(h, "PUT", "/users/1/profile", `{"phone":"call me"}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		if rec := serveProfiles(h, "PUT", "/users/2/profile", `{"bio":"Hi"}`); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if rec := serveProfiles(h, "DELETE", "/users/1/profile", ""); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		if rec := serveProfiles(h, "GET", "/users/1/profile", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 after delete, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"cleanarch/internal/usecase"
+)
+
+// RuleHandler exposes admin endpoints for managing validation rules.
+type RuleHandler struct {
+	rules *usecase.RuleSet
+}
+
+func NewRuleHandler(rules *usecase.RuleSet) *RuleHandler {
+	return &RuleHandler{rules: rules}
+}
+
+// ListRules handles GET /admin/rules.
+func (h *RuleHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, h.rules.List())
+}
+
+// PutRule handles PUT /admin/rules/{name}, creating or replacing the rule.
+func (h *RuleHandler) PutRule(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Expr    string `json:"expr"`
+		Message string `json:"message"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	rule := usecase.Rule{Name: r.PathValue("name"), Expr: req.Expr, Message: req.Message}
+	if err := h.rules.Put(rule); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidRule) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, r, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, r, http.StatusOK, rule)
+}
+
+// DeleteRule handles DELETE /admin/rules/{name}.
+func (h *RuleHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	if !h.rules.Remove(r.PathValue("name")) {
+		writeJSON(w, r, http.StatusNotFound, map[string]string{"error": "rule not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
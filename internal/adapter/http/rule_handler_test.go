@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/usecase"
+)
+
+func serveRules(h *RuleHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/rules", h.ListRules)
+	mux.HandleFunc("PUT /admin/rules/{name}", h.PutRule)
+	mux.HandleFunc("DELETE /admin/rules/{name}", h.DeleteRule)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestRuleHandler(t *testing.T) {
+	t.Run("Put, list and delete a rule", func(t *testing.T) {
+		h := NewRuleHandler(usecase.NewRuleSet())
+
+		rec := serveRules(h, "PUT", "/admin/rules/corp-only", `{"expr":"email endsWith \"@corp.com\""}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		rec = serveRules(h, "GET", "/admin/rules", "")
+		if !strings.Contains(rec.Body.String(), `"name":"corp-only"`) {
+			t.Errorf("expected rule in listing, got %s", rec.Body)
+		}
+		if rec := serveRules(h, "DELETE", "/admin/rules/corp-only", ""); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		if rec := serveRules(h, "DELETE", "/admin/rules/corp-only", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Invalid expression", func(t *testing.T) {
+		rec := serveRules(NewRuleHandler(usecase.NewRuleSet()), "PUT", "/admin/rules/bad", `{"expr":"id >"}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
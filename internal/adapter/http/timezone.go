@@ -0,0 +1,49 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	// Embedded so ?tz= works on hosts without a zoneinfo database.
+	_ "time/tzdata"
+
+	"cleanarch/internal/domain"
+)
+
+// requestZone returns the IANA time zone named by ?tz=, or nil when the
+// request does not ask for one. Timestamps are stored and served in UTC;
+// a zone only changes how a response renders them.
+func requestZone(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", domain.ErrInvalidInput, name)
+	}
+	return loc, nil
+}
+
+// inZone returns a copy of u with its timestamps in loc. A nil loc returns u
+// unchanged, so clients that don't ask keep canonical UTC.
+func inZone(u *domain.User, loc *time.Location) *domain.User {
+	if loc == nil {
+		return u
+	}
+	copy := *u
+	copy.CreatedAt = u.CreatedAt.In(loc)
+	copy.UpdatedAt = u.UpdatedAt.In(loc)
+	return &copy
+}
+
+func usersInZone(users []*domain.User, loc *time.Location) []*domain.User {
+	if loc == nil {
+		return users
+	}
+	result := make([]*domain.User, len(users))
+	for i, u := range users {
+		result[i] = inZone(u, loc)
+	}
+	return result
+}
@@ -1,27 +1,29 @@
 package http
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"cleanarch/internal/domain"
 	"cleanarch/internal/usecase"
 )
 
 // UserHandler exposes HTTP endpoints for user operations.
 type UserHandler struct {
-	service *usecase.UserService
+	service   usecase.UserUsecase
+	cursors   *Cursors
+	lists     *listCoalescer // nil disables coalescing
+	sensitive domain.SensitiveFields
 }
 
-func NewUserHandler(service *usecase.UserService) *UserHandler {
-	return &UserHandler{service: service}
-}
-
-func writeJSON(w http.ResponseWriter, status int, v any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(v)
+func NewUserHandler(service usecase.UserUsecase, opts ...HandlerOption) *UserHandler {
+	o := newHandlerOptions(opts)
+	return &UserHandler{service: service, cursors: o.cursors, lists: o.lists, sensitive: o.sensitive}
 }
 
 func parseID(r *http.Request) (int64, error) {
@@ -29,78 +31,504 @@ func parseID(r *http.Request) (int64, error) {
 	return strconv.ParseInt(idStr, 10, 64)
 }
 
+// etag formats the user's version as a strong entity tag.
+func etag(u *domain.User) string {
+	return `"` + strconv.FormatInt(u.Version, 10) + `"`
+}
+
+// ifMatch returns the version the If-Match header requires, or 0 when the
+// header is absent or "*". ok is false for a header no version can match,
+// such as a weak or malformed tag.
+func ifMatch(r *http.Request) (version int64, ok bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, true
+	}
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(v[1:len(v)-1], 10, 64)
+	return version, err == nil && version > 0
+}
+
+// writeUser responds with the user and its ETag, with timestamps in loc if
+// the request asked for a zone and sensitive fields masked unless the
+// caller may read them.
+func writeUser(w http.ResponseWriter, r *http.Request, status int, user *domain.User, loc *time.Location, sensitive domain.SensitiveFields) {
+	w.Header().Set("ETag", etag(user))
+	writeJSON(w, r, status, inZone(maskFor(w, r, sensitive).Mask(user), loc))
+}
+
+// parseFilter builds a domain.Filter from list query parameters, opening
+// the cursor with cursors.
+func parseFilter(r *http.Request, cursors *Cursors) (domain.Filter, error) {
+	q := r.URL.Query()
+	filter := domain.Filter{
+		UserFilter: domain.UserFilter{
+			NameContains: q.Get("name_contains"),
+			EmailEq:      q.Get("email"),
+			Tag:          domain.NormalizeTag(q.Get("tag")),
+		},
+		Status: domain.UserStatus(q.Get("status")),
+		Sort:   domain.ParseSortSpec(q.Get("sort")),
+	}
+	if v := q.Get("org"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("%w: org must be an organization ID", domain.ErrInvalidFilter)
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := cursors.Open(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	for _, bound := range []struct {
+		param string
+		dst   *time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	} {
+		if v := q.Get(bound.param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("%w: %s must be an RFC3339 timestamp", domain.ErrInvalidFilter, bound.param)
+			}
+			*bound.dst = t
+		}
+	}
+	page, err := parsePageRequest(q)
+	if err != nil {
+		return filter, err
+	}
+	filter.PageRequest = page
+	if v := q.Get("filter"); v != "" {
+		expr, err := domain.ParseExpr(v)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %w", domain.ErrInvalidFilter, err)
+		}
+		filter.Expr = expr
+	}
+	return filter, nil
+}
+
+// userRequest is the body of create and update requests. Role is optional.
+type userRequest struct {
+	Name  string      `json:"name" validate:"required"`
+	Email string      `json:"email" validate:"required"`
+	Role  domain.Role `json:"role"`
+}
+
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	var req userRequest
+	if !decodeRequest(w, r, &req) {
 		return
 	}
-	user, err := h.service.CreateUser(req.Name, req.Email)
+	user, err := h.service.CreateUser(r.Context(), req.Name, req.Email, req.Role)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, user)
+	writeUser(w, r, http.StatusCreated, user, loc, h.sensitive)
 }
 
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-	user, err := h.service.GetUser(id)
+	user, err := h.service.GetUser(r.Context(), id)
 	if err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		writeError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, user)
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
 }
 
+// GetUserByExternalID handles GET /users/by-external-id?provider=&subject=,
+// returning the user linked to that identity.
+func (h *UserHandler) GetUserByExternalID(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	q := r.URL.Query()
+	user, err := h.service.GetUserByExternalID(r.Context(), q.Get("provider"), q.Get("subject"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
+}
+
+// defaultSearchLimit is how many matches SearchUsers returns unless the
+// request sets a limit.
+const defaultSearchLimit = 20
+
+// searchResponse is the body of a name search. It has no total or cursor:
+// a search returns the first matches only, for type-ahead.
+type searchResponse struct {
+	Items []*domain.User `json:"items"`
+	Limit int            `json:"limit"`
+}
+
+// SearchUsers handles GET /users/search?q=&limit=, listing users whose name
+// starts with q, ignoring case, in name order.
+func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	q := r.URL.Query()
+	limit := defaultSearchLimit
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, fmt.Errorf("%w: limit must be an integer", domain.ErrInvalidFilter))
+			return
+		}
+	}
+	users, err := h.service.SearchUsers(r.Context(), q.Get("q"), limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resp := searchResponse{Items: usersInZone(maskUsers(maskFor(w, r, h.sensitive), users), loc), Limit: limit}
+	if resp.Items == nil {
+		resp.Items = []*domain.User{}
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// notModifiedSince reports whether the If-Modified-Since header covers lastModified.
+// HTTP dates have second precision, so lastModified is truncated before comparing.
+func notModifiedSince(r *http.Request, lastModified time.Time) bool {
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(t)
+}
+
+// ListUsers handles GET /users. Listings by organization are neither
+// conditional nor coalesced: the user collection's modification time, which
+// both rely on, doesn't change with memberships.
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.service.ListUsers()
+	filter, err := parseFilter(r, h.cursors)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	conditional := filter.OrgID == 0
+	var lastModified time.Time
+	if conditional {
+		// A 304 stands in for a masked page, so it varies by scope too.
+		varyByScope(w, h.sensitive)
+		lastModified, err = h.service.LastModified(r.Context())
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		if notModifiedSince(r, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	var page *domain.Page[domain.User]
+	if h.lists != nil && conditional {
+		page, err = h.lists.do(r.Context(), listKey(lastModified, r.URL.Query()), func(ctx context.Context) (*domain.Page[domain.User], error) {
+			return h.service.ListUsers(ctx, filter)
+		})
+	} else {
+		page, err = h.service.ListUsers(r.Context(), filter)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePage(w, r, page, filter.Limit, loc, h.cursors, h.sensitive)
+}
+
+// countResponse is the body of a user count.
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+// CountUsers handles GET /users/count, counting the users the list
+// parameters select without listing them. Paging parameters are accepted
+// and ignored. Like ListUsers it is conditional unless filtering by
+// organization.
+func (h *UserHandler) CountUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r, h.cursors)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if filter.OrgID == 0 {
+		lastModified, err := h.service.LastModified(r.Context())
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		if notModifiedSince(r, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	n, err := h.service.CountUsers(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, countResponse{Count: n})
+}
+
+// writePage writes a page of users, in the request's time zone and with
+// sensitive fields masked unless the caller may read them, as a
+// ListResponse.
+func writePage(w http.ResponseWriter, r *http.Request, page *domain.Page[domain.User], limit int, loc *time.Location, cursors *Cursors, sensitive domain.SensitiveFields) {
+	writeList(w, r, usersInZone(maskUsers(maskFor(w, r, sensitive), page.Items), loc), page.Total, page.NextCursor, limit, cursors)
+}
+
+// UserStats handles GET /users/stats?group_by=created|email_domain&bucket=day|week|month.
+func (h *UserHandler) UserStats(w http.ResponseWriter, r *http.Request) {
+	lastModified, err := h.service.LastModified(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	if notModifiedSince(r, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	q := r.URL.Query()
+	stats, err := h.service.UserStats(r.Context(), domain.StatsQuery{
+		GroupBy: domain.StatsGroup(q.Get("group_by")),
+		Bucket:  domain.TimeBucket(q.Get("bucket")),
+	})
 	if err != nil {
-		log.Printf("list users error: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		writeError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, users)
+	writeJSON(w, r, http.StatusOK, stats)
 }
 
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
 		return
 	}
-	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+	var req userRequest
+	if !decodeRequest(w, r, &req) {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	version, ok := ifMatch(r)
+	if !ok {
+		writeError(w, r, domain.ErrVersionConflict)
 		return
 	}
-	user, err := h.service.UpdateUser(id, req.Name, req.Email)
+	loc, err := requestZone(r)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		writeError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, user)
+	user, err := h.service.UpdateUser(r.Context(), id, req.Name, req.Email, req.Role, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
 }
 
+// DeleteUser handles DELETE /users/{id}. A user other records still refer
+// to is a 409 listing them, unless ?cascade=true removes them too.
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
 		return
 	}
-	if err := h.service.DeleteUser(id); err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+	version, ok := ifMatch(r)
+	if !ok {
+		writeError(w, r, domain.ErrVersionConflict)
+		return
+	}
+	cascade := false
+	if v := r.URL.Query().Get("cascade"); v != "" {
+		if cascade, err = strconv.ParseBool(v); err != nil {
+			writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "cascade must be true or false"})
+			return
+		}
+	}
+	err = h.service.DeleteUser(r.Context(), id, version, cascade)
+	var refErr *domain.ReferenceError
+	if errors.As(err, &refErr) {
+		writeJSON(w, r, http.StatusConflict, map[string]any{"error": err.Error(), "references": refErr.References})
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// SuspendUser handles POST /users/{id}/suspend.
+func (h *UserHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.SuspendUser)
+}
+
+// ActivateUser handles POST /users/{id}/activate.
+func (h *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.ActivateUser)
+}
+
+// AnonymizeUser handles POST /users/{id}/anonymize.
+func (h *UserHandler) AnonymizeUser(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.AnonymizeUser)
+}
+
+// SetUserTags handles PUT /users/{id}/tags with {"tags": [...]}, replacing
+// the user's tags. If-Match applies as for updates.
+func (h *UserHandler) SetUserTags(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	version, ok := ifMatch(r)
+	if !ok {
+		writeError(w, r, domain.ErrVersionConflict)
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := h.service.SetUserTags(r.Context(), id, req.Tags, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
+}
+
+// SetUserExternalIDs handles PUT /users/{id}/external-ids with
+// {"external_ids": {"provider": "subject", ...}}, replacing the user's
+// external IDs. If-Match applies as for updates.
+func (h *UserHandler) SetUserExternalIDs(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		ExternalIDs map[string]string `json:"external_ids"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	version, ok := ifMatch(r)
+	if !ok {
+		writeError(w, r, domain.ErrVersionConflict)
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := h.service.SetUserExternalIDs(r.Context(), id, req.ExternalIDs, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
+}
+
+// PatchUserMetadata handles PATCH /users/{id}/metadata with an RFC 7386
+// merge patch: keys set to null are removed, others are set or merged. A
+// null document clears the metadata. If-Match applies as for updates.
+func (h *UserHandler) PatchUserMetadata(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	var patch map[string]any
+	if !decodeRequest(w, r, &patch) {
+		return
+	}
+	version, ok := ifMatch(r)
+	if !ok {
+		writeError(w, r, domain.ErrVersionConflict)
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := h.service.PatchUserMetadata(r.Context(), id, patch, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
+}
+
+func (h *UserHandler) transition(w http.ResponseWriter, r *http.Request, apply func(context.Context, int64) (*domain.User, error)) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	user, err := apply(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeUser(w, r, http.StatusOK, user, loc, h.sensitive)
+}
@@ -0,0 +1,1127 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func newTestMux(h *UserHandler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users", h.CreateUser)
+	mux.HandleFunc("GET /users", h.ListUsers)
+	mux.HandleFunc("GET /users/stats", h.UserStats)
+	mux.HandleFunc("GET /users/count", h.CountUsers)
+	mux.HandleFunc("GET /users/{id}", h.GetUser)
+	mux.HandleFunc("PUT /users/{id}", h.UpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", h.DeleteUser)
+	mux.HandleFunc("POST /users/{id}/suspend", h.SuspendUser)
+	mux.HandleFunc("POST /users/{id}/activate", h.ActivateUser)
+	mux.HandleFunc("POST /users/{id}/anonymize", h.AnonymizeUser)
+	mux.HandleFunc("PUT /users/{id}/tags", h.SetUserTags)
+	mux.HandleFunc("PATCH /users/{id}/metadata", h.PatchUserMetadata)
+	mux.HandleFunc("GET /users/by-external-id", h.GetUserByExternalID)
+	mux.HandleFunc("GET /users/search", h.SearchUsers)
+	mux.HandleFunc("PUT /users/{id}/external-ids", h.SetUserExternalIDs)
+	return mux
+}
+
+func serve(h *UserHandler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	newTestMux(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUserHandler_CreateUser(t *testing.T) {
+	t.Run("Create user with valid data", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			CreateUserFunc: func(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+				return &domain.User{ID: 1, Name: name, Email: email}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users", `{"name":"John Doe","email":"john@example.com"}`, nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d", rec.Code)
+		}
+		var user domain.User
+		if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		if user.Name != "John Doe" {
+			t.Errorf("expected name 'John Doe', got %s", user.Name)
+		}
+	})
+
+	t.Run("Role is passed on", func(t *testing.T) {
+		var got domain.Role
+		svc := &mocks.UserUsecaseMock{
+			CreateUserFunc: func(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+				got = role
+				return &domain.User{ID: 1, Name: name, Email: email, Role: role}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users", `{"name":"John Doe","email":"john@example.com","role":"admin"}`, nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d", rec.Code)
+		}
+		if got != domain.RoleAdmin {
+			t.Errorf("expected role admin, got %q", got)
+		}
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "POST", "/users", `{`, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			CreateUserFunc: func(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+				return nil, domain.Invalid("email", "invalid format")
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users", `{"name":"John Doe","email":"john@"}`, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		var body struct {
+			Errors []domain.FieldError `json:"errors"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		if len(body.Errors) != 1 || body.Errors[0] != (domain.FieldError{Field: "email", Message: "invalid format"}) {
+			t.Errorf("expected an email field error, got %+v", body.Errors)
+		}
+	})
+
+	t.Run("Missing fields are reported per field", func(t *testing.T) {
+		rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "POST", "/users", `{"name":" "}`, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		var body struct {
+			Errors []domain.FieldError `json:"errors"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		want := []domain.FieldError{{Field: "name", Message: "is required"}, {Field: "email", Message: "is required"}}
+		if fmt.Sprint(body.Errors) != fmt.Sprint(want) {
+			t.Errorf("expected %v, got %v", want, body.Errors)
+		}
+	})
+
+	t.Run("Duplicate email", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			CreateUserFunc: func(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+				return nil, domain.ErrDuplicateEmail
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users", `{"name":"John Doe","email":"john@example.com"}`, nil)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Unexpected error", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			CreateUserFunc: func(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+				return nil, errors.New("disk full")
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users", `{"name":"John Doe","email":"john@example.com"}`, nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "disk full") {
+			t.Errorf("expected internal error detail to be hidden, got %s", rec.Body.String())
+		}
+	})
+}
+
+func TestUserHandler_GetUser(t *testing.T) {
+	t.Run("Get existing user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				return &domain.User{ID: id, Name: "John Doe"}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/7", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var user domain.User
+		_ = json.NewDecoder(rec.Body).Decode(&user)
+		if user.ID != 7 {
+			t.Errorf("expected ID 7, got %d", user.ID)
+		}
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "GET", "/users/abc", "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Non-existent user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				return nil, domain.ErrUserNotFound
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/999", "", nil)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	newService := func() *mocks.UserUsecaseMock {
+		return &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return lastModified, nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				return &domain.Page[domain.User]{Items: []*domain.User{{ID: 1}, {ID: 2}}}, nil
+			},
+		}
+	}
+
+	t.Run("List users sets Last-Modified", func(t *testing.T) {
+		rec := serve(NewUserHandler(newService()), "GET", "/users", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Last-Modified"); got != lastModified.Format(http.TimeFormat) {
+			t.Errorf("expected Last-Modified %s, got %s", lastModified.Format(http.TimeFormat), got)
+		}
+	})
+
+	t.Run("If-Modified-Since not modified", func(t *testing.T) {
+		header := http.Header{"If-Modified-Since": {lastModified.Format(http.TimeFormat)}}
+		rec := serve(NewUserHandler(newService()), "GET", "/users", "", header)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304, got %d", rec.Code)
+		}
+	})
+
+	t.Run("If-Modified-Since modified", func(t *testing.T) {
+		header := http.Header{"If-Modified-Since": {lastModified.Add(-time.Second).Format(http.TimeFormat)}}
+		rec := serve(NewUserHandler(newService()), "GET", "/users", "", header)
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Query parameters become a filter", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{
+				Items:      []*domain.User{{ID: 1}, {ID: 2}},
+				Total:      5,
+				NextCursor: domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{}),
+			}, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users?name_contains=jo&sort=name&limit=2", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got.NameContains != "jo" || got.Sort.Field != domain.SortByName || got.Limit != 2 {
+			t.Errorf("unexpected filter %+v", got)
+		}
+		if rec.Header().Get("X-Next-Cursor") == "" {
+			t.Error("expected X-Next-Cursor when more users remain")
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != "5" {
+			t.Errorf("expected X-Total-Count 5, got %q", got)
+		}
+		var list ListResponse[domain.User]
+		if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+			t.Fatalf("expected a list response, got %v", err)
+		}
+		if len(list.Items) != 2 || list.Total != 5 || list.Limit != 2 || list.NextCursor != rec.Header().Get("X-Next-Cursor") {
+			t.Errorf("unexpected list response %+v", list)
+		}
+	})
+
+	t.Run("Organization members are listed unconditionally", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{}, nil
+		}
+
+		header := http.Header{"If-Modified-Since": {lastModified.Format(http.TimeFormat)}}
+		rec := serve(NewUserHandler(svc), "GET", "/users?org=5", "", header)
+		if rec.Code != http.StatusOK || got.OrgID != 5 {
+			t.Fatalf("expected members of organization 5, got %d and %+v", rec.Code, got)
+		}
+		if rec.Header().Get("Last-Modified") != "" {
+			t.Error("expected no Last-Modified, since memberships don't change it")
+		}
+		for _, v := range []string{"x", "0", "-1"} {
+			if rec := serve(NewUserHandler(svc), "GET", "/users?org="+v, "", nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("org=%s: expected status 400, got %d", v, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Descending sort and creation range", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{}, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users?sort=-created_at&created_after=2024-01-01T00:00:00Z", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		want := domain.SortSpec{Field: domain.SortByCreatedAt, Descending: true}
+		if got.Sort != want || !got.CreatedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected filter %+v", got)
+		}
+
+		if rec := serve(NewUserHandler(svc), "GET", "/users?created_after=yesterday", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Limit and offset", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{}, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users", "", nil)
+		if got.Limit != domain.DefaultPageLimit || got.Offset != 0 {
+			t.Errorf("expected the default page, got %+v", got.PageRequest)
+		}
+		if rec.Header().Get("X-Next-Cursor") != "" {
+			t.Error("expected no X-Next-Cursor on the last page")
+		}
+
+		serve(NewUserHandler(svc), "GET", "/users?limit=10&offset=20", "", nil)
+		if got.Limit != 10 || got.Offset != 20 {
+			t.Errorf("expected limit 10 and offset 20, got %+v", got.PageRequest)
+		}
+
+		if rec := serve(NewUserHandler(svc), "GET", "/users?offset=x", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Next cursor resumes the listing", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{
+				Items:      []*domain.User{{ID: 1}, {ID: 2}},
+				Total:      5,
+				NextCursor: domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{}),
+			}, nil
+		}
+		h := NewUserHandler(svc)
+
+		next := serve(h, "GET", "/users?limit=2", "", nil).Header().Get("X-Next-Cursor")
+		if rec := serve(h, "GET", "/users?limit=2&cursor="+url.QueryEscape(next), "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		if want := domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{}); got.Cursor != want {
+			t.Errorf("expected cursor %s, got %s", want, got.Cursor)
+		}
+	})
+
+	t.Run("Link header points at the next page", func(t *testing.T) {
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			return &domain.Page[domain.User]{
+				Items:      []*domain.User{{ID: 1}, {ID: 2}},
+				Total:      5,
+				NextCursor: domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{}),
+			}, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users?status=active&limit=2&offset=4", "", nil)
+		next := rec.Header().Get("X-Next-Cursor")
+		want := "</users?" + url.Values{"cursor": {next}, "limit": {"2"}, "status": {"active"}}.Encode() + `>; rel="next"`
+		if got := rec.Header().Get("Link"); got != want {
+			t.Errorf("expected Link %s, got %s", want, got)
+		}
+
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			return &domain.Page[domain.User]{Items: []*domain.User{{ID: 1}}, Total: 1}, nil
+		}
+		if got := serve(NewUserHandler(svc), "GET", "/users", "", nil).Header().Get("Link"); got != "" {
+			t.Errorf("expected no Link on the last page, got %s", got)
+		}
+	})
+
+	t.Run("Unsigned cursor", func(t *testing.T) {
+		cursor := domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{})
+		if rec := serve(NewUserHandler(newService()), "GET", "/users?cursor="+cursor, "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Expired cursor", func(t *testing.T) {
+		cursors := NewCursors([]byte("secret"), time.Minute)
+		cursors.now = func() time.Time { return lastModified.Add(-time.Hour) }
+		cursor := cursors.Seal(domain.EncodeCursor(&domain.User{ID: 2}, domain.SortSpec{}))
+		cursors.now = time.Now
+
+		rec := serve(NewUserHandler(newService(), WithCursors(cursors)), "GET", "/users?cursor="+cursor, "", nil)
+		if rec.Code != http.StatusGone {
+			t.Errorf("expected status 410, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Filter expression", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{}, nil
+		}
+
+		q := url.Values{"filter": {`created_at > "2024-01-01" AND email endsWith "@corp.com"`}}
+		rec := serve(NewUserHandler(svc), "GET", "/users?"+q.Encode(), "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got.Expr == nil || got.Expr.String() != `(created_at > "2024-01-01" AND email endsWith "@corp.com")` {
+			t.Errorf("unexpected filter expression %v", got.Expr)
+		}
+
+		rec = serve(NewUserHandler(svc), "GET", "/users?"+url.Values{"filter": {`email ~ "x"`}}.Encode(), "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			return nil, domain.ErrInvalidFilter
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users?sort=password", "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		svc := newService()
+		svc.ListUsersFunc = func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			return nil, errors.New("repository error")
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users", "", nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_ListCoalescing(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Identical queries share a result", func(t *testing.T) {
+		var calls atomic.Int64
+		modified := lastModified
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return modified, nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				calls.Add(1)
+				return &domain.Page[domain.User]{Items: []*domain.User{{ID: 1, CreatedAt: lastModified}}}, nil
+			},
+		}
+		h := NewUserHandler(svc, WithListCoalescing(time.Minute))
+
+		serve(h, "GET", "/users?limit=50&sort=created_at", "", nil)
+		rec := serve(h, "GET", "/users?sort=created_at&limit=50&tz=Asia/Seoul", "", nil)
+		if n := calls.Load(); n != 1 {
+			t.Errorf("expected 1 service call, got %d", n)
+		}
+		if !strings.Contains(rec.Body.String(), "+09:00") {
+			t.Errorf("expected timestamps in the requested zone, got %s", rec.Body)
+		}
+
+		serve(h, "GET", "/users?limit=10", "", nil)
+		if n := calls.Load(); n != 2 {
+			t.Errorf("expected a different query to call the service, got %d calls", n)
+		}
+
+		modified = lastModified.Add(time.Second)
+		serve(h, "GET", "/users?limit=50&sort=created_at", "", nil)
+		if n := calls.Load(); n != 3 {
+			t.Errorf("expected a write to invalidate the result, got %d calls", n)
+		}
+	})
+
+	t.Run("Results expire after the TTL", func(t *testing.T) {
+		var calls atomic.Int64
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return lastModified, nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				calls.Add(1)
+				return &domain.Page[domain.User]{}, nil
+			},
+		}
+		h := NewUserHandler(svc, WithListCoalescing(time.Minute))
+		now := time.Now()
+		h.lists.now = func() time.Time { return now }
+
+		serve(h, "GET", "/users", "", nil)
+		now = now.Add(time.Minute)
+		serve(h, "GET", "/users", "", nil)
+		if n := calls.Load(); n != 2 {
+			t.Errorf("expected 2 service calls, got %d", n)
+		}
+	})
+
+	t.Run("In-flight queries coalesce", func(t *testing.T) {
+		var calls atomic.Int64
+		release := make(chan struct{})
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return lastModified, nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				calls.Add(1)
+				<-release
+				return &domain.Page[domain.User]{Items: []*domain.User{{ID: 1}}}, nil
+			},
+		}
+		h := NewUserHandler(svc, WithListCoalescing(time.Minute))
+
+		var wg sync.WaitGroup
+		codes := make([]int, 10)
+		for i := range codes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes[i] = serve(h, "GET", "/users?limit=50", "", nil).Code
+			}()
+		}
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if n := calls.Load(); n != 1 {
+			t.Errorf("expected 1 service call, got %d", n)
+		}
+		for i, code := range codes {
+			if code != http.StatusOK {
+				t.Errorf("request %d: expected status 200, got %d", i, code)
+			}
+		}
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		var calls atomic.Int64
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return lastModified, nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				calls.Add(1)
+				return nil, errors.New("repository error")
+			},
+		}
+		h := NewUserHandler(svc, WithListCoalescing(time.Minute))
+
+		serve(h, "GET", "/users", "", nil)
+		if rec := serve(h, "GET", "/users", "", nil); rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", rec.Code)
+		}
+		if n := calls.Load(); n != 2 {
+			t.Errorf("expected 2 service calls, got %d", n)
+		}
+	})
+}
+
+func TestUserHandler_UserStats(t *testing.T) {
+	newService := func() *mocks.UserUsecaseMock {
+		return &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil },
+		}
+	}
+
+	t.Run("Query parameters select the grouping", func(t *testing.T) {
+		var got domain.StatsQuery
+		svc := newService()
+		svc.UserStatsFunc = func(ctx context.Context, q domain.StatsQuery) (*domain.Stats, error) {
+			got = q
+			return &domain.Stats{Total: 2, GroupBy: q.GroupBy, Buckets: []domain.StatsBucket{{Key: "corp.com", Count: 2}}}, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/stats?group_by=email_domain", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got.GroupBy != domain.GroupByEmailDomain {
+			t.Errorf("unexpected query %+v", got)
+		}
+		if !strings.Contains(rec.Body.String(), `"total":2`) {
+			t.Errorf("expected total in body, got %s", rec.Body)
+		}
+	})
+
+	t.Run("Invalid grouping", func(t *testing.T) {
+		svc := newService()
+		svc.UserStatsFunc = func(ctx context.Context, q domain.StatsQuery) (*domain.Stats, error) {
+			return nil, domain.ErrInvalidFilter
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/stats?group_by=status", "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		header := http.Header{"If-Modified-Since": {"Mon, 01 Jan 2024 00:00:00 GMT"}}
+		rec := serve(NewUserHandler(newService()), "GET", "/users/stats", "", header)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_CountUsers(t *testing.T) {
+	newService := func() *mocks.UserUsecaseMock {
+		return &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil },
+		}
+	}
+
+	t.Run("Query parameters select the users", func(t *testing.T) {
+		var got domain.Filter
+		svc := newService()
+		svc.CountUsersFunc = func(ctx context.Context, filter domain.Filter) (int, error) {
+			got = filter
+			return 7, nil
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/count?name_contains=doe&status=active", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got.NameContains != "doe" || got.Status != domain.StatusActive {
+			t.Errorf("unexpected filter %+v", got)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"count":7}` {
+			t.Errorf("expected count in body, got %s", body)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		svc := newService()
+		svc.CountUsersFunc = func(ctx context.Context, filter domain.Filter) (int, error) {
+			return 0, domain.ErrInvalidFilter
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/count?status=bogus", "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		header := http.Header{"If-Modified-Since": {"Mon, 01 Jan 2024 00:00:00 GMT"}}
+		rec := serve(NewUserHandler(newService()), "GET", "/users/count", "", header)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	t.Run("Update existing user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			UpdateUserFunc: func(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error) {
+				return &domain.User{ID: id, Name: name, Email: email}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PUT", "/users/1", `{"name":"Jane Doe","email":"jane@example.com"}`, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "PUT", "/users/1", `{`, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("If-Match passes the version and the new ETag is returned", func(t *testing.T) {
+		var got int64
+		svc := &mocks.UserUsecaseMock{
+			UpdateUserFunc: func(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error) {
+				got = version
+				return &domain.User{ID: id, Name: name, Email: email, Version: version + 1}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PUT", "/users/1", `{"name":"Jane Doe","email":"jane@example.com"}`, http.Header{"If-Match": {`"3"`}})
+		if got != 3 {
+			t.Errorf("expected version 3, got %d", got)
+		}
+		if etag := rec.Header().Get("ETag"); etag != `"4"` {
+			t.Errorf("expected ETag \"4\", got %s", etag)
+		}
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			UpdateUserFunc: func(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error) {
+				return nil, domain.ErrVersionConflict
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PUT", "/users/1", `{"name":"Jane Doe","email":"jane@example.com"}`, http.Header{"If-Match": {`"3"`}})
+		if rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("expected status 412, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Unmatchable If-Match", func(t *testing.T) {
+		for _, v := range []string{`W/"3"`, `3`, `"x"`} {
+			rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "PUT", "/users/1", `{"name":"Jane Doe","email":"jane@example.com"}`, http.Header{"If-Match": {v}})
+			if rec.Code != http.StatusPreconditionFailed {
+				t.Errorf("If-Match %s: expected status 412, got %d", v, rec.Code)
+			}
+		}
+	})
+}
+
+func TestUserHandler_ETag(t *testing.T) {
+	svc := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{ID: id, Version: 7}, nil
+		},
+		DeleteUserFunc: func(ctx context.Context, id int64, version int64, cascade bool) error {
+			if version != 7 {
+				return domain.ErrVersionConflict
+			}
+			return nil
+		},
+	}
+	h := NewUserHandler(svc)
+
+	rec := serve(h, "GET", "/users/1", "", nil)
+	etag := rec.Header().Get("ETag")
+	if etag != `"7"` {
+		t.Fatalf("expected ETag \"7\", got %s", etag)
+	}
+	if rec := serve(h, "DELETE", "/users/1", "", http.Header{"If-Match": {`"6"`}}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status 412 for a stale ETag, got %d", rec.Code)
+	}
+	if rec := serve(h, "DELETE", "/users/1", "", http.Header{"If-Match": {etag}}); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204 for the current ETag, got %d", rec.Code)
+	}
+}
+
+func TestUserHandler_DeleteUser(t *testing.T) {
+	t.Run("Delete existing user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			DeleteUserFunc: func(ctx context.Context, id int64, version int64, cascade bool) error { return nil },
+		}
+
+		rec := serve(NewUserHandler(svc), "DELETE", "/users/1", "", nil)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Non-existent user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			DeleteUserFunc: func(ctx context.Context, id int64, version int64, cascade bool) error { return domain.ErrUserNotFound },
+		}
+
+		rec := serve(NewUserHandler(svc), "DELETE", "/users/999", "", nil)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Referenced user", func(t *testing.T) {
+		var cascaded bool
+		svc := &mocks.UserUsecaseMock{
+			DeleteUserFunc: func(ctx context.Context, id int64, version int64, cascade bool) error {
+				if cascaded = cascade; cascade {
+					return nil
+				}
+				return &domain.ReferenceError{References: []domain.Reference{{Kind: "organization", ID: 7}}}
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "DELETE", "/users/1", "", nil)
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("expected status 409, got %d", rec.Code)
+		}
+		var body struct {
+			References []domain.Reference `json:"references"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&body)
+		if len(body.References) != 1 || body.References[0] != (domain.Reference{Kind: "organization", ID: 7}) {
+			t.Errorf("expected the blocking reference, got %+v", body.References)
+		}
+
+		if rec := serve(NewUserHandler(svc), "DELETE", "/users/1?cascade=true", "", nil); rec.Code != http.StatusNoContent || !cascaded {
+			t.Errorf("expected a cascading delete with status 204, got %d", rec.Code)
+		}
+		if rec := serve(NewUserHandler(svc), "DELETE", "/users/1?cascade=maybe", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_Status(t *testing.T) {
+	t.Run("Suspend user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			SuspendUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				return &domain.User{ID: id, Status: domain.StatusSuspended}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users/3/suspend", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var user domain.User
+		_ = json.NewDecoder(rec.Body).Decode(&user)
+		if user.Status != domain.StatusSuspended {
+			t.Errorf("expected status suspended, got %q", user.Status)
+		}
+	})
+
+	t.Run("Anonymize user", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			AnonymizeUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				u := &domain.User{ID: id}
+				u.Anonymize()
+				return u, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users/3/anonymize", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var user domain.User
+		_ = json.NewDecoder(rec.Body).Decode(&user)
+		if user.ID != 3 || !user.Anonymized() {
+			t.Errorf("expected user 3 anonymized, got %+v", user)
+		}
+	})
+
+	t.Run("Disallowed transition", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			ActivateUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+				return nil, fmt.Errorf("%w: active to active", domain.ErrInvalidTransition)
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "POST", "/users/3/activate", "", nil)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rec.Code)
+		}
+	})
+
+	t.Run("List filters by status", func(t *testing.T) {
+		var got domain.Filter
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return time.Now(), nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				got = filter
+				return &domain.Page[domain.User]{}, nil
+			},
+		}
+
+		serve(NewUserHandler(svc), "GET", "/users?status=suspended", "", nil)
+		if got.Status != domain.StatusSuspended {
+			t.Errorf("expected status filter suspended, got %q", got.Status)
+		}
+	})
+}
+
+func TestUserHandler_Tags(t *testing.T) {
+	t.Run("Set tags", func(t *testing.T) {
+		var gotTags []string
+		var gotVersion int64
+		svc := &mocks.UserUsecaseMock{
+			SetUserTagsFunc: func(ctx context.Context, id int64, tags []string, version int64) (*domain.User, error) {
+				gotTags, gotVersion = tags, version
+				return &domain.User{ID: id, Tags: tags, Version: version + 1}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PUT", "/users/3/tags", `{"tags":["beta","vip"]}`, http.Header{"If-Match": {`"4"`}})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if strings.Join(gotTags, ",") != "beta,vip" || gotVersion != 4 {
+			t.Errorf("expected tags beta,vip at version 4, got %v at %d", gotTags, gotVersion)
+		}
+		if got := rec.Header().Get("ETag"); got != `"5"` {
+			t.Errorf("expected ETag \"5\", got %s", got)
+		}
+	})
+
+	t.Run("List filters by normalized tag", func(t *testing.T) {
+		var got domain.Filter
+		svc := &mocks.UserUsecaseMock{
+			LastModifiedFunc: func(ctx context.Context) (time.Time, error) { return time.Now(), nil },
+			ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+				got = filter
+				return &domain.Page[domain.User]{}, nil
+			},
+		}
+
+		serve(NewUserHandler(svc), "GET", "/users?tag=Beta", "", nil)
+		if got.Tag != "beta" {
+			t.Errorf("expected tag filter beta, got %q", got.Tag)
+		}
+	})
+}
+
+func TestUserHandler_PatchUserMetadata(t *testing.T) {
+	t.Run("Patch is passed on", func(t *testing.T) {
+		var got map[string]any
+		svc := &mocks.UserUsecaseMock{
+			PatchUserMetadataFunc: func(ctx context.Context, id int64, patch map[string]any, version int64) (*domain.User, error) {
+				got = patch
+				return &domain.User{ID: id, Metadata: map[string]any{"region": "eu"}}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PATCH", "/users/3/metadata", `{"plan":null,"region":"eu"}`, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if v, ok := got["plan"]; !ok || v != nil || got["region"] != "eu" {
+			t.Errorf("expected plan null and region eu, got %v", got)
+		}
+	})
+
+	t.Run("Non-object patch", func(t *testing.T) {
+		rec := serve(NewUserHandler(&mocks.UserUsecaseMock{}), "PATCH", "/users/3/metadata", `["plan"]`, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_ExternalIDs(t *testing.T) {
+	t.Run("Lookup by provider and subject", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			GetUserByExternalIDFunc: func(ctx context.Context, provider, subject string) (*domain.User, error) {
+				if provider != "google" || subject != "1082" {
+					return nil, domain.ErrUserNotFound
+				}
+				return &domain.User{ID: 3}, nil
+			},
+		}
+		h := NewUserHandler(svc)
+
+		if rec := serve(h, "GET", "/users/by-external-id?provider=google&subject=1082", "", nil); rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+		if rec := serve(h, "GET", "/users/by-external-id?provider=github&subject=1082", "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Linking an identity in use conflicts", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			SetUserExternalIDsFunc: func(ctx context.Context, id int64, ids map[string]string, version int64) (*domain.User, error) {
+				if ids["google"] != "1082" {
+					t.Errorf("expected google 1082, got %v", ids)
+				}
+				return nil, domain.ErrDuplicateExternalID
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "PUT", "/users/3/external-ids", `{"external_ids":{"google":"1082"}}`, nil)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_TimeZone(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			return &domain.User{ID: id, CreatedAt: created, UpdatedAt: created}, nil
+		},
+	}
+	h := NewUserHandler(svc)
+
+	t.Run("UTC by default", func(t *testing.T) {
+		rec := serve(h, "GET", "/users/1", "", nil)
+		if !strings.Contains(rec.Body.String(), `"created_at":"2024-03-01T12:00:00Z"`) {
+			t.Errorf("expected a UTC timestamp, got %s", rec.Body)
+		}
+	})
+
+	t.Run("Requested zone", func(t *testing.T) {
+		rec := serve(h, "GET", "/users/1?tz=Asia/Seoul", "", nil)
+		if !strings.Contains(rec.Body.String(), `"created_at":"2024-03-01T21:00:00+09:00"`) {
+			t.Errorf("expected a Seoul timestamp, got %s", rec.Body)
+		}
+	})
+
+	t.Run("Unknown zone", func(t *testing.T) {
+		if rec := serve(h, "GET", "/users/1?tz=Mars/Olympus", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
+func TestUserHandler_SearchUsers(t *testing.T) {
+	t.Run("Search with the default limit", func(t *testing.T) {
+		var gotPrefix string
+		var gotLimit int
+		svc := &mocks.UserUsecaseMock{
+			SearchUsersFunc: func(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+				gotPrefix, gotLimit = prefix, limit
+				return []*domain.User{{ID: 1, Name: "John Doe"}}, nil
+			},
+		}
+
+		rec := serve(NewUserHandler(svc), "GET", "/users/search?q=jo", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if gotPrefix != "jo" || gotLimit != defaultSearchLimit {
+			t.Errorf("expected jo with the default limit, got %q and %d", gotPrefix, gotLimit)
+		}
+		var resp searchResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Items) != 1 || resp.Limit != defaultSearchLimit {
+			t.Errorf("unexpected response %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("No matches is an empty list", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			SearchUsersFunc: func(ctx context.Context, prefix string, limit int) ([]*domain.User, error) { return nil, nil },
+		}
+		rec := serve(NewUserHandler(svc), "GET", "/users/search?q=zz&limit=5", "", nil)
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"items":[],"limit":5}` {
+			t.Errorf("expected an empty list, got %s", body)
+		}
+	})
+
+	t.Run("Invalid searches are rejected", func(t *testing.T) {
+		svc := &mocks.UserUsecaseMock{
+			SearchUsersFunc: func(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+				return nil, domain.ErrInvalidFilter
+			},
+		}
+		for _, target := range []string{"/users/search?q=jo&limit=x", "/users/search"} {
+			if rec := serve(NewUserHandler(svc), "GET", target, "", nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", target, rec.Code)
+			}
+		}
+	})
+}
+
+func TestUserHandler_SensitiveFields(t *testing.T) {
+	stored := &domain.User{ID: 7, Name: "Ada", Email: "ada@example.com", Metadata: map[string]any{"ssn": "078-05-1120", "plan": "pro"}}
+	svc := &mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			return stored, nil
+		},
+		LastModifiedFunc: func(ctx context.Context) (time.Time, error) {
+			return time.Time{}, nil
+		},
+		ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			return &domain.Page[domain.User]{Items: []*domain.User{stored}, Total: 1}, nil
+		},
+	}
+	fields, _ := domain.ParseSensitiveFields("email,metadata.ssn")
+	mux := newTestMux(NewUserHandler(svc, WithSensitiveFields(fields)))
+	get := func(target string, p domain.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(domain.WithPrincipal(req.Context(), p))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Masked without the pii:read scope", func(t *testing.T) {
+		var user domain.User
+		_ = json.NewDecoder(get("/users/7", domain.Principal{ActorID: 1, SubjectID: 1}).Body).Decode(&user)
+		if user.Email != "a***@example.com" || user.Metadata["ssn"] != domain.MaskedValue || user.Metadata["plan"] != "pro" {
+			t.Errorf("expected masked email and ssn, got %q and %v", user.Email, user.Metadata)
+		}
+		var list struct{ Items []domain.User }
+		_ = json.NewDecoder(get("/users", domain.Principal{ActorID: 1, SubjectID: 1}).Body).Decode(&list)
+		if len(list.Items) != 1 || list.Items[0].Email != "a***@example.com" {
+			t.Errorf("expected masked list item, got %+v", list.Items)
+		}
+		if stored.Email != "ada@example.com" || stored.Metadata["ssn"] != "078-05-1120" {
+			t.Error("expected the service's user to be left unchanged")
+		}
+	})
+
+	t.Run("Plain with the pii:read scope", func(t *testing.T) {
+		var user domain.User
+		_ = json.NewDecoder(get("/users/7", domain.Principal{ActorID: 1, SubjectID: 1, Scopes: domain.ScopePIIRead}).Body).Decode(&user)
+		if user.Email != "ada@example.com" || user.Metadata["ssn"] != "078-05-1120" {
+			t.Errorf("expected plain email and ssn, got %q and %v", user.Email, user.Metadata)
+		}
+	})
+	t.Run("Responses vary by scope", func(t *testing.T) {
+		for _, target := range []string{"/users/7", "/users"} {
+			rec := get(target, domain.Principal{ActorID: 1, SubjectID: 1})
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "X-Scopes" {
+				t.Errorf("expected Vary: X-Scopes for %s, got %v", target, got)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "private" {
+				t.Errorf("expected Cache-Control: private for %s, got %q", target, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"net/http"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// ViewHandler exposes HTTP endpoints for saved views.
+type ViewHandler struct {
+	service   usecase.ViewUsecase
+	cursors   *Cursors
+	sensitive domain.SensitiveFields
+}
+
+func NewViewHandler(service usecase.ViewUsecase, opts ...HandlerOption) *ViewHandler {
+	o := newHandlerOptions(opts)
+	return &ViewHandler{service: service, cursors: o.cursors, sensitive: o.sensitive}
+}
+
+func (h *ViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string           `json:"name"`
+		Filter string           `json:"filter"`
+		SortBy domain.SortField `json:"sort"`
+	}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	view, err := h.service.CreateView(r.Context(), req.Name, req.Filter, req.SortBy)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, view)
+}
+
+func (h *ViewHandler) ListViews(w http.ResponseWriter, r *http.Request) {
+	views, err := h.service.ListViews(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, views)
+}
+
+func (h *ViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	view, err := h.service.GetView(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, view)
+}
+
+func (h *ViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	if err := h.service.DeleteView(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Results executes the view. It accepts the list endpoint's limit, offset
+// and cursor parameters and responds in the same form.
+func (h *ViewHandler) Results(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	page, err := parseFilter(r, h.cursors)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	loc, err := requestZone(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	_, users, err := h.service.Results(r.Context(), id, page)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePage(w, r, users, page.Limit, loc, h.cursors, h.sensitive)
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/mocks"
+)
+
+// stubViewRepository implements domain.ViewRepository for handler tests.
+type stubViewRepository struct {
+	views []*domain.View
+}
+
+func (s *stubViewRepository) Create(ctx context.Context, v *domain.View) (*domain.View, error) {
+	v.ID = int64(len(s.views) + 1)
+	s.views = append(s.views, v)
+	return v, nil
+}
+
+func (s *stubViewRepository) GetByID(ctx context.Context, id int64) (*domain.View, error) {
+	for _, v := range s.views {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return nil, domain.ErrViewNotFound
+}
+
+func (s *stubViewRepository) List(ctx context.Context) ([]*domain.View, error) { return s.views, nil }
+func (s *stubViewRepository) Delete(ctx context.Context, id int64) error       { return nil }
+
+func serveViews(h *ViewHandler, method, target, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /views", h.CreateView)
+	mux.HandleFunc("GET /views", h.ListViews)
+	mux.HandleFunc("GET /views/{id}", h.GetView)
+	mux.HandleFunc("DELETE /views/{id}", h.DeleteView)
+	mux.HandleFunc("GET /views/{id}/results", h.Results)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestViewHandler(t *testing.T) {
+	var got domain.Filter
+	users := &mocks.UserUsecaseMock{
+		ListUsersFunc: func(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+			got = filter
+			return &domain.Page[domain.User]{
+				Items:      []*domain.User{{ID: 3, Name: "Ann"}},
+				Total:      2,
+				NextCursor: domain.EncodeCursor(&domain.User{ID: 3, Name: "Ann"}, domain.SortSpec{Field: domain.SortByName}),
+			}, nil
+		},
+	}
+	h := NewViewHandler(usecase.NewViewService(&stubViewRepository{}, users))
+
+	t.Run("Create view", func(t *testing.T) {
+		rec := serveViews(h, "POST", "/views", `{"name":"corp","filter":"email endsWith \"@corp.com\"","sort":"name"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body)
+		}
+		var view domain.View
+		_ = json.NewDecoder(rec.Body).Decode(&view)
+		if view.ID != 1 || view.SortBy != domain.SortByName {
+			t.Errorf("unexpected view %+v", view)
+		}
+	})
+
+	t.Run("Create view with invalid filter", func(t *testing.T) {
+		rec := serveViews(h, "POST", "/views", `{"name":"bad","filter":"id >"}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Results", func(t *testing.T) {
+		rec := serveViews(h, "GET", "/views/1/results?limit=1", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+		}
+		if got.Expr == nil || got.Sort.Field != domain.SortByName || got.Limit != 1 {
+			t.Errorf("unexpected filter %+v", got)
+		}
+		if rec.Header().Get("X-Next-Cursor") == "" {
+			t.Error("expected X-Next-Cursor when more users remain")
+		}
+	})
+
+	t.Run("Results for missing view", func(t *testing.T) {
+		if rec := serveViews(h, "GET", "/views/9/results", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+// authzDenied counts rejected requests at /debug/vars, keyed by
+// "unauthenticated" and "forbidden".
+var authzDenied = expvar.NewMap("authorization_denied")
+
+// Authorizer restricts admin-only requests to active admins and
+// impersonation to active admins and service accounts. It judges the
+// request's principal: admin-only requests by its subject, so an admin
+// impersonating a member has a member's rights. Other requests pass
+// through without a lookup.
+type Authorizer struct {
+	users usecase.UserUsecase
+}
+
+func NewAuthorizer(users usecase.UserUsecase) *Authorizer {
+	return &Authorizer{users: users}
+}
+
+// Middleware answers 400 for malformed principal headers, 401 when an
+// admin-only request has no known subject and 403 when the subject is not
+// an active admin or the actor may not impersonate. Changes to a user's
+// record are admin-only unless the subject is that user.
+func (a *Authorizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := requestPrincipal(r)
+		if err != nil {
+			denyRequest(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if p.Impersonating() && p.Service == "" {
+			if !a.admit(w, r, p.ActorID, "only admins may impersonate") {
+				return
+			}
+		}
+		switch id, ok := userWrite(r.Method, r.URL.Path); {
+		case adminOnly(r.Method, r.URL.Path):
+			if !a.admit(w, r, p.SubjectID, "admin role required") {
+				return
+			}
+		case ok && id != p.SubjectID:
+			if !a.admit(w, r, p.SubjectID, "admin role required to change another user") {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// admit reports whether the user is an active admin, responding with the
+// denial if not. An ID of zero is an unknown user.
+func (a *Authorizer) admit(w http.ResponseWriter, r *http.Request, id int64, forbidden string) bool {
+	user, err := a.user(r, id)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		authzDenied.Add("unauthenticated", 1)
+		denyRequest(w, http.StatusUnauthorized, "unknown caller")
+	case err != nil:
+		log.Printf("authorization error: %v", err)
+		denyRequest(w, http.StatusInternalServerError, "internal error")
+	case user.Role != domain.RoleAdmin || user.Status != domain.StatusActive:
+		authzDenied.Add("forbidden", 1)
+		denyRequest(w, http.StatusForbidden, forbidden)
+	default:
+		return true
+	}
+	return false
+}
+
+func (a *Authorizer) user(r *http.Request, id int64) (*domain.User, error) {
+	if id <= 0 {
+		return nil, domain.ErrUserNotFound
+	}
+	return a.users.GetUser(r.Context(), id)
+}
+
+// adminOnly reports whether a request goes to an /admin/ endpoint, or
+// deletes, anonymizes, suspends or activates through the API, reaches
+// across all users (listing,
+// counting, searching, aggregating, a saved view's results or a bulk
+// update), reads the audit log, sets a password or links external IDs.
+func adminOnly(method, path string) bool {
+	if strings.HasPrefix(path, "/admin/") {
+		return true
+	}
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	switch method {
+	case http.MethodDelete:
+		return true
+	case http.MethodPost:
+		for _, suffix := range []string{":bulkDelete", ":bulkUpdate", "/anonymize", "/suspend", "/activate"} {
+			if strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	case http.MethodPut:
+		return strings.HasSuffix(path, "/password") || strings.HasSuffix(path, "/external-ids")
+	case http.MethodGet:
+		path = strings.TrimSuffix(path, "/")
+		switch path {
+		case "/api/v1/users", "/api/v1/users/count", "/api/v1/users/search", "/api/v1/users/stats", "/api/v1/audit":
+			return true
+		}
+		return strings.HasPrefix(path, "/api/v1/views/") && strings.HasSuffix(path, "/results")
+	}
+	return false
+}
+
+// userWrite returns the user whose record a mutating request under
+// /api/v1/users/{id} changes.
+func userWrite(method, path string) (int64, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v1/users/")
+	if !ok || !mutating(method) {
+		return 0, false
+	}
+	seg, _, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(seg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+func denyRequest(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cleanarch/internal/config"
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase/mocks"
+)
+
+func TestAuthorizer(t *testing.T) {
+	users := map[int64]*domain.User{
+		1: {ID: 1, Role: domain.RoleAdmin, Status: domain.StatusActive},
+		2: {ID: 2, Role: domain.RoleMember, Status: domain.StatusActive},
+		3: {ID: 3, Role: domain.RoleAdmin, Status: domain.StatusSuspended},
+	}
+	a := NewAuthorizer(&mocks.UserUsecaseMock{
+		GetUserFunc: func(ctx context.Context, id int64) (*domain.User, error) {
+			if u, ok := users[id]; ok {
+				return u, nil
+			}
+			return nil, domain.ErrUserNotFound
+		},
+	})
+	serveAs := func(method, target string, header http.Header) int {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		a.Middleware(okHandler("ok")).ServeHTTP(rec, req)
+		return rec.Code
+	}
+	serve := func(method, target, caller string) int {
+		header := http.Header{}
+		if caller != "" {
+			header.Set(CallerHeader, caller)
+		}
+		return serveAs(method, target, header)
+	}
+
+	t.Run("Other requests need no caller", func(t *testing.T) {
+		for _, target := range []string{"/api/v1/users/1", "/api/v1/views/1", "/healthz", "/status"} {
+			if code := serve(http.MethodGet, target, ""); code != http.StatusOK {
+				t.Errorf("expected status 200 for %s, got %d", target, code)
+			}
+		}
+		if code := serve(http.MethodPost, "/api/v1/users", ""); code != http.StatusOK {
+			t.Errorf("expected status 200 for create, got %d", code)
+		}
+	})
+
+	t.Run("Admins may delete and list", func(t *testing.T) {
+		for _, req := range [][2]string{
+			{http.MethodDelete, "/api/v1/users/2"},
+			{http.MethodDelete, "/api/v1/views/1"},
+			{http.MethodPost, "/api/v1/users:bulkDelete"},
+			{http.MethodPost, "/api/v1/users:bulkUpdate"},
+			{http.MethodGet, "/api/v1/users"},
+			{http.MethodGet, "/api/v1/users/stats"},
+			{http.MethodGet, "/api/v1/views/1/results"},
+			{http.MethodPut, "/api/v1/users/2/password"},
+			{http.MethodPut, "/api/v1/users/2/external-ids"},
+			{http.MethodGet, "/api/v1/audit"},
+			{http.MethodPost, "/api/v1/users/2/anonymize"},
+			{http.MethodPut, "/admin/read-only"},
+			{http.MethodGet, "/admin/config"},
+		} {
+			if code := serve(req[0], req[1], "1"); code != http.StatusOK {
+				t.Errorf("expected status 200 for %s %s, got %d", req[0], req[1], code)
+			}
+		}
+	})
+
+	t.Run("Non-admins are forbidden", func(t *testing.T) {
+		if code := serve(http.MethodDelete, "/api/v1/users/1", "2"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a member, got %d", code)
+		}
+		if code := serve(http.MethodGet, "/api/v1/users", "3"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a suspended admin, got %d", code)
+		}
+		if code := serve(http.MethodPut, "/api/v1/users/1/password", "2"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a member setting a password, got %d", code)
+		}
+		if code := serve(http.MethodGet, "/api/v1/users/search", "2"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a member searching users, got %d", code)
+		}
+		if code := serve(http.MethodGet, "/api/v1/users/count", "2"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a member counting users, got %d", code)
+		}
+		for _, req := range [][2]string{
+			{http.MethodGet, "/api/v1/users/stats"},
+			{http.MethodGet, "/api/v1/views/1/results"},
+			{http.MethodPost, "/api/v1/users:bulkUpdate"},
+		} {
+			if code := serve(req[0], req[1], "2"); code != http.StatusForbidden {
+				t.Errorf("expected status 403 for a member at %s %s, got %d", req[0], req[1], code)
+			}
+		}
+	})
+
+	t.Run("Only admins suspend or activate", func(t *testing.T) {
+		for _, action := range []string{"suspend", "activate"} {
+			target := "/api/v1/users/1/" + action
+			if code := serve(http.MethodPost, target, ""); code != http.StatusUnauthorized {
+				t.Errorf("expected status 401 for an anonymous %s of the admin, got %d", action, code)
+			}
+			if code := serve(http.MethodPost, target, "2"); code != http.StatusForbidden {
+				t.Errorf("expected status 403 for a member's %s of the admin, got %d", action, code)
+			}
+			if code := serve(http.MethodPost, "/api/v1/users/2/"+action, "1"); code != http.StatusOK {
+				t.Errorf("expected status 200 for an admin's %s, got %d", action, code)
+			}
+		}
+	})
+
+	t.Run("Users change only their own record", func(t *testing.T) {
+		if code := serve(http.MethodPut, "/api/v1/users/2", "2"); code != http.StatusOK {
+			t.Errorf("expected status 200 for a member's own record, got %d", code)
+		}
+		if code := serve(http.MethodPatch, "/api/v1/users/2/metadata", "2"); code != http.StatusOK {
+			t.Errorf("expected status 200 for a member's own metadata, got %d", code)
+		}
+		if code := serve(http.MethodPut, "/api/v1/users/1", "2"); code != http.StatusForbidden {
+			t.Errorf("expected status 403 for a member changing the admin, got %d", code)
+		}
+		if code := serve(http.MethodPut, "/api/v1/users/1", ""); code != http.StatusUnauthorized {
+			t.Errorf("expected status 401 for an anonymous change, got %d", code)
+		}
+		if code := serve(http.MethodPut, "/api/v1/users/2", "1"); code != http.StatusOK {
+			t.Errorf("expected status 200 for an admin changing a member, got %d", code)
+		}
+	})
+
+	t.Run("Admin endpoints are admin-only", func(t *testing.T) {
+		for _, req := range [][2]string{
+			{http.MethodPut, "/admin/read-only"},
+			{http.MethodPut, "/admin/rules/corp-only"},
+			{http.MethodDelete, "/admin/rules/corp-only"},
+			{http.MethodPost, "/admin/consistency"},
+			{http.MethodGet, "/admin/config"},
+		} {
+			if code := serve(req[0], req[1], "2"); code != http.StatusForbidden {
+				t.Errorf("expected status 403 for a member at %s %s, got %d", req[0], req[1], code)
+			}
+			if code := serve(req[0], req[1], ""); code != http.StatusUnauthorized {
+				t.Errorf("expected status 401 for no caller at %s %s, got %d", req[0], req[1], code)
+			}
+		}
+	})
+
+	t.Run("Unknown callers are unauthenticated", func(t *testing.T) {
+		for _, caller := range []string{"", "abc", "99"} {
+			if code := serve(http.MethodDelete, "/api/v1/users/1", caller); code != http.StatusUnauthorized {
+				t.Errorf("expected status 401 for caller %q, got %d", caller, code)
+			}
+		}
+	})
+
+	t.Run("Impersonation", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			header http.Header
+			target string
+			want   int
+		}{
+			{"Admin as member", headers(CallerHeader, "1", ImpersonateHeader, "2"), "/api/v1/users/2", http.StatusOK},
+			{"Member may not impersonate", headers(CallerHeader, "2", ImpersonateHeader, "1"), "/api/v1/users/1", http.StatusForbidden},
+			{"Service account as member", headers(ServiceHeader, "billing", ImpersonateHeader, "2"), "/api/v1/users/2", http.StatusOK},
+			{"Subject's rights apply", headers(CallerHeader, "1", ImpersonateHeader, "2"), "/api/v1/users", http.StatusForbidden},
+			{"Service account alone is not an admin", headers(ServiceHeader, "billing"), "/api/v1/users", http.StatusUnauthorized},
+			{"Malformed subject", headers(CallerHeader, "1", ImpersonateHeader, "x"), "/api/v1/users/1", http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if code := serveAs(http.MethodGet, tt.target, tt.header); code != tt.want {
+					t.Errorf("expected status %d, got %d", tt.want, code)
+				}
+			})
+		}
+	})
+}
+
+func TestReplayIsAuthorized(t *testing.T) {
+	dir := t.TempDir()
+	serve := func(cfg config.Config, opts ServerOptions) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set(CallerHeader, "2")
+		rec := httptest.NewRecorder()
+		NewServer(cfg, opts).HTTP.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve(config.Default(), ServerOptions{RecordDir: dir}); code != http.StatusOK {
+		t.Fatalf("expected the recorded listing to succeed, got %d", code)
+	}
+
+	cfg := config.Default()
+	cfg.Authorization = true
+	if code := serve(cfg, ServerOptions{ReplayDir: dir}); code == http.StatusOK {
+		t.Error("expected the replayed listing to need an admin")
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"cleanarch/internal/usecase"
+)
+
+// consistencyMetrics is published at /debug/vars as "consistency": runs,
+// orphans found and repaired, and source errors, summed over all runs.
+var consistencyMetrics = expvar.NewMap("consistency")
+
+// ConsistencyJob runs a ConsistencyChecker on a schedule and on demand,
+// recording every run's outcome in consistencyMetrics.
+type ConsistencyJob struct {
+	*usecase.ConsistencyChecker
+	interval time.Duration
+
+	runMu  sync.Mutex // one run at a time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ usecase.ConsistencyUsecase = (*ConsistencyJob)(nil)
+
+func newConsistencyJob(checker *usecase.ConsistencyChecker, interval time.Duration) *ConsistencyJob {
+	return &ConsistencyJob{ConsistencyChecker: checker, interval: interval}
+}
+
+// Run checks once, waiting for a run already in progress to finish first.
+func (j *ConsistencyJob) Run(ctx context.Context) (*usecase.ConsistencyReport, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+	report, err := j.ConsistencyChecker.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	consistencyMetrics.Add("runs", 1)
+	consistencyMetrics.Add("orphans_found", int64(len(report.Orphans)))
+	consistencyMetrics.Add("orphans_repaired", int64(report.Repaired()))
+	consistencyMetrics.Add("errors", int64(len(report.Errors)))
+	if len(report.Orphans) > 0 || len(report.Errors) > 0 {
+		log.Printf("consistency check: %d orphans (%d repaired), %d errors", len(report.Orphans), report.Repaired(), len(report.Errors))
+	}
+	return report, nil
+}
+
+// Start runs a check every interval until Stop. The first check runs one
+// interval after start, so it doesn't compete with warm-up.
+func (j *ConsistencyJob) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := j.Run(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("consistency check: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cancels a running check and waits for the scheduler to exit.
+func (j *ConsistencyJob) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/fake"
+)
+
+func TestConsistencyJob(t *testing.T) {
+	t.Run("Runs on schedule until stopped", func(t *testing.T) {
+		job := newConsistencyJob(usecase.NewConsistencyChecker(fake.New(fake.Options{}), false), 10*time.Millisecond)
+		before := consistencyMetrics.Get("runs")
+
+		if err := job.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		deadline := time.Now().Add(time.Second)
+		for job.LastReport() == nil && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if err := job.Stop(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if job.LastReport() == nil {
+			t.Fatal("expected a scheduled run")
+		}
+		if after := consistencyMetrics.Get("runs"); after == nil || before != nil && after.String() == before.String() {
+			t.Errorf("expected the run counted, got %v", after)
+		}
+	})
+
+	t.Run("Stop before start", func(t *testing.T) {
+		job := newConsistencyJob(usecase.NewConsistencyChecker(fake.New(fake.Options{}), false), time.Hour)
+		if err := job.Stop(context.Background()); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"cleanarch/internal/deadline"
+)
+
+// RequestTimeoutHeader lets a client that gives up sooner than the server's
+// write timeout say so, e.g. "X-Request-Timeout: 2s"; work on its behalf
+// then stops in time.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// responseReserve is the share of a request's time held back for writing
+// the response; downstream calls split the rest.
+const responseReserve = 0.2
+
+// WithDeadline gives each request a deadline of timeout, or less when the
+// client asks with RequestTimeoutHeader, minus responseReserve. A zero
+// timeout sets none unless the client asks. Downstream
+// calls take their budget from it (see package deadline), so none of them
+// outlives the response.
+func WithDeadline(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := timeout
+		if v := r.Header.Get(RequestTimeoutHeader); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				denyRequest(w, http.StatusBadRequest, RequestTimeoutHeader+" must be a positive duration such as 2s")
+				return
+			}
+			if limit <= 0 || d < limit {
+				limit = d
+			}
+		}
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), limit)
+		defer cancel()
+		ctx, cancelShare := deadline.Share(ctx, 1-responseReserve)
+		defer cancelShare()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
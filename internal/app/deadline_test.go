@@ -0,0 +1,61 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	serve := func(timeout time.Duration, header string) (time.Duration, bool, int) {
+		var left time.Duration
+		var ok bool
+		h := WithDeadline(timeout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var d time.Time
+			if d, ok = r.Context().Deadline(); ok {
+				left = time.Until(d)
+			}
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		if header != "" {
+			req.Header.Set(RequestTimeoutHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return left, ok, rec.Code
+	}
+
+	t.Run("Time is held back for the response", func(t *testing.T) {
+		left, ok, _ := serve(time.Second, "")
+		if !ok || left > 800*time.Millisecond || left < 700*time.Millisecond {
+			t.Errorf("expected about 800ms, got %s", left)
+		}
+	})
+
+	t.Run("Clients can ask for less", func(t *testing.T) {
+		if left, _, _ := serve(time.Second, "100ms"); left > 80*time.Millisecond {
+			t.Errorf("expected at most 80ms, got %s", left)
+		}
+		if left, _, _ := serve(time.Second, "1h"); left > 800*time.Millisecond {
+			t.Errorf("expected the server timeout to cap the request, got %s", left)
+		}
+		if left, ok, _ := serve(0, "100ms"); !ok || left > 80*time.Millisecond {
+			t.Errorf("expected at most 80ms without a server timeout, got %s", left)
+		}
+	})
+
+	t.Run("No timeout sets no deadline", func(t *testing.T) {
+		if _, ok, _ := serve(0, ""); ok {
+			t.Error("expected no deadline")
+		}
+	})
+
+	t.Run("Malformed timeouts are rejected", func(t *testing.T) {
+		for _, v := range []string{"soon", "-1s", "0"} {
+			if _, _, code := serve(time.Second, v); code != http.StatusBadRequest {
+				t.Errorf("%q: expected status 400, got %d", v, code)
+			}
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"cleanarch/internal/config"
+)
+
+// Diagnostics describes how the server was assembled, for startup logs and
+// GET /admin/config.
+type Diagnostics struct {
+	Config     map[string]string `json:"config"`
+	Repository string            `json:"repository"`
+	Middleware []string          `json:"middleware"`
+	Routes     []string          `json:"routes"`
+}
+
+// NewDiagnostics collects the diagnostics for cfg and the routes registered on r.
+func NewDiagnostics(cfg config.Config, r Router, middleware ...string) Diagnostics {
+	return Diagnostics{
+		Config:     cfg.Dump(),
+		Repository: cfg.RepositoryBackend,
+		Middleware: middleware,
+		Routes:     Routes(r),
+	}
+}
+
+// Log emits the diagnostics as structured startup events.
+func (d Diagnostics) Log(logger *slog.Logger) {
+	attrs := make([]any, 0, len(d.Config))
+	for _, k := range config.Keys(d.Config) {
+		attrs = append(attrs, slog.String(k, d.Config[k]))
+	}
+	logger.Info("startup config", attrs...)
+	logger.Info("startup repository", "backend", d.Repository)
+	logger.Info("startup middleware", "middleware", d.Middleware)
+	for _, route := range d.Routes {
+		logger.Info("startup route", "route", route)
+	}
+}
+
+// DiagnosticsHandler serves d as JSON.
+func DiagnosticsHandler(d *Diagnostics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d)
+	})
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/config"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Run("Collects routes and config", func(t *testing.T) {
+		r := NewServeMuxRouter()
+		r.Group("/api", func(g Router) {
+			g.Handle(http.MethodGet, "/ping", okHandler("pong"))
+		})
+
+		d := NewDiagnostics(config.Default(), r, "logging")
+		if len(d.Routes) != 1 || d.Routes[0] != "GET /api/ping" {
+			t.Errorf("expected route 'GET /api/ping', got %v", d.Routes)
+		}
+		if d.Repository != "memory" {
+			t.Errorf("expected repository 'memory', got %s", d.Repository)
+		}
+		if d.Config["HTTP_ADDR"] != ":8080" {
+			t.Errorf("expected HTTP_ADDR ':8080', got %s", d.Config["HTTP_ADDR"])
+		}
+	})
+
+	t.Run("Handler serves JSON", func(t *testing.T) {
+		d := NewDiagnostics(config.Default(), NewServeMuxRouter(), "logging")
+
+		rec := httptest.NewRecorder()
+		DiagnosticsHandler(&d).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+		var got Diagnostics
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		if len(got.Middleware) != 1 || got.Middleware[0] != "logging" {
+			t.Errorf("expected middleware [logging], got %v", got.Middleware)
+		}
+	})
+
+	t.Run("Middleware follows the handler chain", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Authorization = true
+		s := NewServer(cfg, ServerOptions{RecordDir: t.TempDir()})
+		want := "logging priority deadline authorization record principal dry_run read_only slo body_limit"
+		if got := strings.Join(s.Diagnostics.Middleware, " "); got != want {
+			t.Errorf("expected middleware %s, got %s", want, got)
+		}
+
+		s = NewServer(cfg, ServerOptions{ReplayDir: t.TempDir()})
+		if got := strings.Join(s.Diagnostics.Middleware, " "); got != "logging priority deadline authorization replay" {
+			t.Errorf("expected replay behind authorization, got %s", got)
+		}
+	})
+}
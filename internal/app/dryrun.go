@@ -0,0 +1,46 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cleanarch/internal/domain"
+)
+
+// DryRunHeader asks for a dry run, as does the dry_run query parameter.
+// Dry-run responses carry it too.
+const DryRunHeader = "X-Dry-Run"
+
+// WithDryRun makes API writes that ask for it dry runs (see
+// domain.WithDryRun): they are authorized and validated as usual and
+// answered with what they would return, but nothing is stored. Writes
+// outside the API don't honor dry runs, so asking for one is rejected
+// rather than carried out. Reads ignore the flag.
+func WithDryRun(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get("dry_run")
+		if v == "" {
+			v = r.Header.Get(DryRunHeader)
+		}
+		if v == "" || !mutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		dry, err := strconv.ParseBool(v)
+		if err != nil {
+			denyRequest(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+		if !dry {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			denyRequest(w, http.StatusBadRequest, "dry runs are only supported for API writes")
+			return
+		}
+		w.Header().Set(DryRunHeader, "true")
+		next.ServeHTTP(w, r.WithContext(domain.WithDryRun(r.Context())))
+	})
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/config"
+	"cleanarch/internal/domain"
+)
+
+func TestWithDryRun(t *testing.T) {
+	newServer := func() (*Server, func(method, target, body string, header http.Header) *httptest.ResponseRecorder) {
+		s := NewServer(config.Default(), ServerOptions{})
+		return s, func(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, target, strings.NewReader(body))
+			for k, v := range header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			s.HTTP.Handler.ServeHTTP(rec, req)
+			return rec
+		}
+	}
+	dryRun := http.Header{DryRunHeader: {"true"}}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) domain.User {
+		var u domain.User
+		if err := json.NewDecoder(rec.Body).Decode(&u); err != nil {
+			t.Fatalf("expected a user, got %v", err)
+		}
+		return u
+	}
+
+	t.Run("Create returns the user without storing it", func(t *testing.T) {
+		_, do := newServer()
+		rec := do("POST", "/api/v1/users?dry_run=true", `{"name":"Ada","email":"ada@example.com"}`, nil)
+		if rec.Code != http.StatusCreated || rec.Header().Get(DryRunHeader) != "true" {
+			t.Fatalf("expected a dry-run 201, got %d %v", rec.Code, rec.Header())
+		}
+		if u := decode(t, rec); u.ID != 1 || u.Name != "Ada" {
+			t.Errorf("expected the would-be user 1, got %+v", u)
+		}
+		if rec := do("GET", "/api/v1/users/1", "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("expected no stored user, got %d", rec.Code)
+		}
+		rec = do("POST", "/api/v1/users", `{"name":"Ada","email":"ada@example.com"}`, nil)
+		if u := decode(t, rec); u.ID != 1 {
+			t.Errorf("expected the dry run not to use up ID 1, got %d", u.ID)
+		}
+	})
+
+	t.Run("Update and delete leave the user unchanged", func(t *testing.T) {
+		_, do := newServer()
+		do("POST", "/api/v1/users", `{"name":"Ada","email":"ada@example.com"}`, nil)
+
+		rec := do("PUT", "/api/v1/users/1", `{"name":"Ada King","email":"ada@example.com"}`, dryRun)
+		if u := decode(t, rec); rec.Code != http.StatusOK || u.Name != "Ada King" || u.Version != 2 {
+			t.Errorf("expected the would-be update, got %d %+v", rec.Code, u)
+		}
+		if rec := do("DELETE", "/api/v1/users/1", "", dryRun); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		rec = do("GET", "/api/v1/users/1", "", nil)
+		if u := decode(t, rec); u.Name != "Ada" || u.Version != 1 {
+			t.Errorf("expected the stored user unchanged, got %+v", u)
+		}
+		if rec := do("GET", "/api/v1/audit", "", nil); strings.Count(rec.Body.String(), `"action"`) != 1 {
+			t.Errorf("expected only the real create to be audited, got %s", rec.Body)
+		}
+	})
+
+	t.Run("Conflicts are reported", func(t *testing.T) {
+		_, do := newServer()
+		do("POST", "/api/v1/users", `{"name":"Ada","email":"ada@example.com"}`, nil)
+		rec := do("POST", "/api/v1/users", `{"name":"Ada","email":"ada@example.com"}`, dryRun)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rec.Code)
+		}
+		rec = do("PUT", "/api/v1/users/1", `{"name":"Ada","email":"ada@example.com"}`, http.Header{DryRunHeader: {"true"}, "If-Match": {`"5"`}})
+		if rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("expected status 412, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Dry runs pass read-only mode", func(t *testing.T) {
+		s, do := newServer()
+		s.ReadOnly.SetEnabled(true)
+		if rec := do("POST", "/api/v1/users", `{"name":"Ada","email":"ada@example.com"}`, dryRun); rec.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Unsupported requests are rejected", func(t *testing.T) {
+		_, do := newServer()
+		if rec := do("POST", "/api/v1/users?dry_run=maybe", `{}`, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for a malformed flag, got %d", rec.Code)
+		}
+		if rec := do("PUT", "/admin/read-only", `{"enabled":true}`, dryRun); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an admin write, got %d", rec.Code)
+		}
+		if rec := do("GET", "/api/v1/users/1", "", dryRun); rec.Code != http.StatusNotFound || rec.Header().Get(DryRunHeader) != "" {
+			t.Errorf("expected reads to ignore the flag, got %d %v", rec.Code, rec.Header())
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// eventMetrics counts published events by type, and handler panics, at /debug/vars.
+var eventMetrics = expvar.NewMap("events")
+
+var _ domain.EventBus = (*EventBus)(nil)
+
+// EventBus is the in-process domain.EventBus. Handlers run synchronously on
+// the publishing goroutine in subscription order, so slow subscribers (e.g.
+// webhook delivery) should hand work off rather than block the request. A
+// panicking handler is logged and does not stop the others.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []subscription
+}
+
+type subscription struct {
+	handler domain.EventHandler
+	types   []domain.EventType
+}
+
+func (s subscription) wants(t domain.EventType) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, want := range s.types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+func (b *EventBus) Subscribe(h domain.EventHandler, types ...domain.EventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, subscription{handler: h, types: types})
+}
+
+func (b *EventBus) Publish(ctx context.Context, e domain.Event) {
+	eventMetrics.Add(string(e.Type), 1)
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, s := range handlers {
+		if s.wants(e.Type) {
+			deliver(ctx, s.handler, e)
+		}
+	}
+}
+
+func deliver(ctx context.Context, h domain.EventHandler, e domain.Event) {
+	defer func() {
+		if p := recover(); p != nil {
+			eventMetrics.Add("handler_panics", 1)
+			log.Printf("event handler panic on %s: %v", e.Type, p)
+		}
+	}()
+	h(ctx, e)
+}
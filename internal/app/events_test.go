@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestEventBus(t *testing.T) {
+	t.Run("Delivers only subscribed types", func(t *testing.T) {
+		bus := NewEventBus()
+		var all, deletes []domain.EventType
+		bus.Subscribe(func(ctx context.Context, e domain.Event) { all = append(all, e.Type) })
+		bus.Subscribe(func(ctx context.Context, e domain.Event) { deletes = append(deletes, e.Type) }, domain.UserDeleted)
+
+		bus.Publish(context.Background(), domain.Event{Type: domain.UserCreated})
+		bus.Publish(context.Background(), domain.Event{Type: domain.UserDeleted})
+		if len(all) != 2 {
+			t.Errorf("expected 2 events, got %v", all)
+		}
+		if len(deletes) != 1 || deletes[0] != domain.UserDeleted {
+			t.Errorf("expected only the delete, got %v", deletes)
+		}
+	})
+
+	t.Run("A panicking handler does not stop the others", func(t *testing.T) {
+		bus := NewEventBus()
+		delivered := false
+		bus.Subscribe(func(ctx context.Context, e domain.Event) { panic("boom") })
+		bus.Subscribe(func(ctx context.Context, e domain.Event) { delivered = true })
+
+		bus.Publish(context.Background(), domain.Event{Type: domain.UserUpdated})
+		if !delivered {
+			t.Error("expected the second handler to run")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"cleanarch/internal/config"
+)
+
+// RouteExample is a runnable request against one API route, in the body of
+// GET /docs/examples.
+type RouteExample struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// Snippets holds the request as "curl", "httpie" and "go" (net/http)
+	// source, addressed to the host that served the examples.
+	Snippets map[string]string `json:"snippets"`
+}
+
+// exampleBodies are valid request bodies for the routes that take one,
+// keyed like Routes. TestExampleBodies checks every such route has one.
+var exampleBodies = map[string]string{
+	"POST /api/v1/users":                  `{"name":"Ada Lovelace","email":"ada.lovelace@example.com","role":"member"}`,
+	"POST /api/v1/users:bulkUpdate":       `{"filter":"email endsWith \"@example.com\"","patch":{"status":"suspended"},"dry_run":true}`,
+	"POST /api/v1/users:bulkDelete":       `{"filter":"status == \"suspended\"","dry_run":true}`,
+	"PUT /api/v1/users/{id}":              `{"name":"Ada King","email":"ada.king@example.com","role":"admin"}`,
+	"PUT /api/v1/users/{id}/tags":         `{"tags":["beta","team:analytics"]}`,
+	"PATCH /api/v1/users/{id}/metadata":   `{"plan":"enterprise","seats":25,"trial":null}`,
+	"PUT /api/v1/users/{id}/external-ids": `{"external_ids":{"google":"108273645519283746501","github":"583231"}}`,
+	"PUT /api/v1/users/{id}/profile":      `{"bio":"Mathematician and writer.","phone":"+44 20 7946 0958","addresses":[{"label":"home","line1":"12 St James's Square","city":"London","postal_code":"SW1Y 4JH","country":"GB"}]}`,
+	"PUT /api/v1/users/{id}/password":     `{"password":"correct-horse-battery-staple"}`,
+	"POST /api/v1/views":                  `{"name":"Suspended members","filter":"status == \"suspended\"","sort":"name"}`,
+	"POST /api/v1/orgs":                   `{"name":"Analytical Engines Ltd"}`,
+	"POST /api/v1/orgs/{id}/members":      `{"user_id":7}`,
+}
+
+// examplePathValues fill the wildcards of example paths.
+var examplePathValues = map[string]string{
+	"{id}":      "42",
+	"{user_id}": "7",
+}
+
+// registerExamples serves GET /docs/examples: a RouteExample for every API
+// route registered on r. Like OPTIONS, the list comes from the route table.
+// When authorization is on, snippets send CallerHeader as user 1.
+func registerExamples(r Router, cfg config.Config) {
+	var routes []RouteExample
+	for _, route := range Routes(r) {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		ex := RouteExample{Method: method, Path: path}
+		if body, ok := exampleBodies[route]; ok {
+			ex.Body = json.RawMessage(body)
+		}
+		routes = append(routes, ex)
+	}
+	var headers []string
+	if cfg.Authorization {
+		headers = append(headers, CallerHeader+": 1")
+	}
+	r.Handle(http.MethodGet, "/docs/examples", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		base := "http://" + req.Host
+		if req.TLS != nil {
+			base = "https://" + req.Host
+		}
+		out := make([]RouteExample, len(routes))
+		for i, ex := range routes {
+			url := base + examplePath(ex.Path)
+			ex.Snippets = map[string]string{
+				"curl":   curlSnippet(ex.Method, url, headers, ex.Body),
+				"httpie": httpieSnippet(ex.Method, url, headers, ex.Body),
+				"go":     goSnippet(ex.Method, url, headers, ex.Body),
+			}
+			out[i] = ex
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+}
+
+func examplePath(path string) string {
+	for wildcard, v := range examplePathValues {
+		path = strings.ReplaceAll(path, wildcard, v)
+	}
+	return path
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func curlSnippet(method, url string, headers []string, body []byte) string {
+	parts := []string{"curl", "-X", method, shellQuote(url)}
+	for _, h := range headers {
+		parts = append(parts, "-H", shellQuote(h))
+	}
+	if body != nil {
+		parts = append(parts, "-H", shellQuote("Content-Type: application/json"), "-d", shellQuote(string(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func httpieSnippet(method, url string, headers []string, body []byte) string {
+	parts := []string{"http", method, shellQuote(url)}
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ": ")
+		parts = append(parts, shellQuote(name+":"+value))
+	}
+	cmd := strings.Join(parts, " ")
+	if body != nil {
+		cmd = "echo " + shellQuote(string(body)) + " | " + cmd
+	}
+	return cmd
+}
+
+func goSnippet(method, url string, headers []string, body []byte) string {
+	var b strings.Builder
+	reader := "nil"
+	if body != nil {
+		reader = "strings.NewReader(`" + string(body) + "`)"
+	}
+	b.WriteString("req, err := http.NewRequestWithContext(ctx, \"" + method + "\", \"" + url + "\", " + reader + ")\n")
+	b.WriteString("if err != nil {\n\treturn err\n}\n")
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ": ")
+		b.WriteString("req.Header.Set(\"" + name + "\", \"" + value + "\")\n")
+	}
+	if body != nil {
+		b.WriteString("req.Header.Set(\"Content-Type\", \"application/json\")\n")
+	}
+	b.WriteString("resp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("if err != nil {\n\treturn err\n}\n")
+	b.WriteString("defer resp.Body.Close()\n")
+	return b.String()
+}
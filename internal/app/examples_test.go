@@ -0,0 +1,90 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/config"
+)
+
+func TestExampleBodies(t *testing.T) {
+	s := NewServer(config.Default(), ServerOptions{})
+	routes := make(map[string]bool)
+	for _, route := range Routes(s.Router) {
+		routes[route] = true
+		method, path, _ := strings.Cut(route, " ")
+		if !strings.HasPrefix(path, "/api/") || (method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch) {
+			continue
+		}
+		body, ok := exampleBodies[route]
+		if !ok {
+			if !strings.HasSuffix(path, "/suspend") && !strings.HasSuffix(path, "/activate") && !strings.HasSuffix(path, "/anonymize") {
+				t.Errorf("expected an example body for %s", route)
+			}
+			continue
+		}
+		rec := httptest.NewRecorder()
+		s.Router.ServeHTTP(rec, httptest.NewRequest(method, examplePath(path), strings.NewReader(body)))
+		if rec.Code == http.StatusBadRequest || rec.Code == http.StatusUnprocessableEntity {
+			t.Errorf("expected the example for %s to be accepted, got %d: %s", route, rec.Code, rec.Body)
+		}
+	}
+	for route := range exampleBodies {
+		if !routes[route] {
+			t.Errorf("expected %s to be a registered route", route)
+		}
+	}
+}
+
+func TestRegisterExamples(t *testing.T) {
+	examples := func(cfg config.Config) []RouteExample {
+		r := NewServeMuxRouter()
+		r.Handle(http.MethodPost, "/api/v1/users", okHandler("create"))
+		r.Handle(http.MethodDelete, "/api/v1/users/{id}", okHandler("delete"))
+		r.Handle(http.MethodGet, "/healthz", okHandler("ok"))
+		registerExamples(r, cfg)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://api.example.com/docs/examples", nil))
+		var out []RouteExample
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		return out
+	}
+
+	t.Run("Snippets address the serving host", func(t *testing.T) {
+		out := examples(config.Default())
+		if len(out) != 2 || out[0].Path != "/api/v1/users" || out[1].Path != "/api/v1/users/{id}" {
+			t.Fatalf("expected the two API routes, got %+v", out)
+		}
+		want := `curl -X POST 'http://api.example.com/api/v1/users' -H 'Content-Type: application/json' -d '` + exampleBodies["POST /api/v1/users"] + `'`
+		if got := out[0].Snippets["curl"]; got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+		if got := out[1].Snippets["httpie"]; got != "http DELETE 'http://api.example.com/api/v1/users/42'" {
+			t.Errorf("expected an HTTPie DELETE of user 42, got %s", got)
+		}
+		if got := out[1].Snippets["go"]; !strings.Contains(got, `"DELETE", "http://api.example.com/api/v1/users/42", nil)`) {
+			t.Errorf("expected a Go request without a body, got %s", got)
+		}
+	})
+
+	t.Run("Authorization adds the caller header", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Authorization = true
+		out := examples(cfg)
+		if got := out[1].Snippets["curl"]; !strings.Contains(got, `-H 'X-User-ID: 1'`) {
+			t.Errorf("expected the caller header, got %s", got)
+		}
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote(`{"bio":"it's"}`); got != `'{"bio":"it'\''s"}'` {
+		t.Errorf("expected an escaped single quote, got %s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Hook is a component's start/stop pair. Either function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	// Timeout bounds each of OnStart and OnStop when set. A function still
+	// running at the deadline fails with context.DeadlineExceeded and is
+	// abandoned, so it should watch ctx.
+	Timeout time.Duration
+}
+
+// Lifecycle starts hooks in registration order and stops the started ones
+// in reverse order, so components stop before the things they depend on.
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// Append registers h to run after the hooks already registered.
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// InsertBefore registers h to start just before the hook called name, and
+// so to stop just after it, or appends h if there is no such hook. Hooks
+// must be registered before Start.
+func (l *Lifecycle) InsertBefore(name string, h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, existing := range l.hooks {
+		if existing.Name == name {
+			l.hooks = append(l.hooks[:i], append([]Hook{h}, l.hooks[i:]...)...)
+			return
+		}
+	}
+	l.hooks = append(l.hooks, h)
+}
+
+// Start runs every OnStart in order. If one fails, the hooks already started
+// are stopped again and the start error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.started < len(l.hooks) {
+		h := l.hooks[l.started]
+		if h.OnStart != nil {
+			if err := h.run(ctx, h.OnStart); err != nil {
+				startErr := fmt.Errorf("start %s: %w", h.Name, err)
+				return errors.Join(startErr, l.stopLocked(ctx))
+			}
+		}
+		l.started++
+	}
+	return nil
+}
+
+// Stop runs OnStop for every started hook in reverse order and returns all
+// stop errors joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stopLocked(ctx)
+}
+
+func (l *Lifecycle) stopLocked(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		if err := h.run(ctx, h.OnStop); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", h.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run calls fn, bounded by the hook's timeout if it has one.
+func (h Hook) run(ctx context.Context, fn func(context.Context) error) error {
+	if h.Timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cleanarch/internal/config"
+)
+
+func recordingHook(name string, events *[]string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Run("Starts in order and stops in reverse", func(t *testing.T) {
+		var events []string
+		var l Lifecycle
+		l.Append(recordingHook("repo", &events, nil))
+		l.Append(recordingHook("http", &events, nil))
+
+		if err := l.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := l.Stop(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := "start repo,start http,stop http,stop repo"
+		if got := strings.Join(events, ","); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Failed start rolls back started hooks", func(t *testing.T) {
+		var events []string
+		var l Lifecycle
+		l.Append(recordingHook("repo", &events, nil))
+		l.Append(recordingHook("http", &events, errors.New("address in use")))
+		l.Append(recordingHook("never", &events, nil))
+
+		err := l.Start(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "start http: address in use") {
+			t.Fatalf("expected start error for http, got %v", err)
+		}
+		want := "start repo,start http,stop repo"
+		if got := strings.Join(events, ","); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Stop errors are joined", func(t *testing.T) {
+		var l Lifecycle
+		for _, name := range []string{"a", "b"} {
+			l.Append(Hook{Name: name, OnStop: func(context.Context) error { return errors.New("boom") }})
+		}
+		_ = l.Start(context.Background())
+
+		err := l.Stop(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "stop a") || !strings.Contains(err.Error(), "stop b") {
+			t.Errorf("expected both stop errors, got %v", err)
+		}
+	})
+
+	t.Run("Inserted hooks run ahead of the named hook", func(t *testing.T) {
+		var events []string
+		var l Lifecycle
+		l.Append(recordingHook("repo", &events, nil))
+		l.Append(recordingHook("http", &events, nil))
+		l.InsertBefore("http", recordingHook("cache", &events, nil))
+		l.InsertBefore("missing", recordingHook("last", &events, nil))
+
+		_ = l.Start(context.Background())
+		_ = l.Stop(context.Background())
+		want := "start repo,start cache,start http,start last,stop last,stop http,stop cache,stop repo"
+		if got := strings.Join(events, ","); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Timeout abandons a hung hook", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		var l Lifecycle
+		l.Append(Hook{
+			Name: "stuck",
+			OnStart: func(context.Context) error {
+				<-release
+				return nil
+			},
+			Timeout: 10 * time.Millisecond,
+		})
+		err := l.Start(context.Background())
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "start stuck") {
+			t.Errorf("expected a deadline error for stuck, got %v", err)
+		}
+	})
+}
+
+func TestServer_EmbedderHooks(t *testing.T) {
+	cfg := config.Default()
+	cfg.Addr = "127.0.0.1:0"
+	s := NewServer(cfg, ServerOptions{})
+
+	var events []string
+	s.OnStart("queue", func(context.Context) error {
+		events = append(events, "start queue")
+		return nil
+	})
+	s.OnStop("queue", func(context.Context) error {
+		events = append(events, "stop queue")
+		return nil
+	})
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "start queue,stop queue"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	names := make([]string, len(s.Lifecycle.hooks))
+	for i, h := range s.Lifecycle.hooks {
+		names[i] = h.Name
+	}
+	if got := strings.Join(names[len(names)-3:], ","); got != "queue,queue,http" {
+		t.Errorf("expected embedder hooks just before http, got %s", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"cleanarch/internal/domain"
 )
 
 type statusRecorder struct {
@@ -16,13 +18,27 @@ func (r *statusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
-// WithLogging wraps an http.Handler to log requests and response status/duration.
+// WithLogging wraps an http.Handler to log requests and response
+// status/duration, and who made them when the request names a principal.
 func WithLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		start := time.Now()
 		next.ServeHTTP(recorder, r)
 		dur := time.Since(start)
+		if p, err := requestPrincipal(r); err == nil && p != (domain.Principal{}) {
+			log.Printf("%s %s -> %d (%s) by %s", r.Method, r.URL.Path, recorder.status, dur, p)
+			return
+		}
 		log.Printf("%s %s -> %d (%s)", r.Method, r.URL.Path, recorder.status, dur)
 	})
 }
+
+// WithBodyLimit caps request bodies at limit bytes. Reading past the limit
+// fails with an *http.MaxBytesError, which handlers answer with 413.
+func WithBodyLimit(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
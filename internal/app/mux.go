@@ -0,0 +1,88 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Middleware wraps an http.Handler with additional behavior.
+type Middleware func(http.Handler) http.Handler
+
+// Router is the routing surface routes are registered on. The stdlib
+// ServeMux backs the default implementation; embedders can supply their own
+// (e.g. an adapter around chi or gorilla/mux) and pass it to RegisterRoutes.
+type Router interface {
+	http.Handler
+	// Handle registers h for method and pattern. An empty method matches any method.
+	Handle(method, pattern string, h http.Handler)
+	// Group registers the routes added by fn under prefix. Middleware added
+	// inside the group does not leak to the parent.
+	Group(prefix string, fn func(r Router))
+	// Use appends middleware applied to routes registered after the call.
+	Use(mw ...Middleware)
+}
+
+// NewServeMuxRouter returns a Router backed by http.ServeMux.
+func NewServeMuxRouter() Router {
+	return &muxRouter{mux: http.NewServeMux(), table: &routeTable{}}
+}
+
+// Routes returns the patterns registered on r, if the router keeps track of them.
+func Routes(r Router) []string {
+	if l, ok := r.(interface{ Routes() []string }); ok {
+		return l.Routes()
+	}
+	return nil
+}
+
+type muxRouter struct {
+	mux        *http.ServeMux
+	table      *routeTable
+	prefix     string
+	middleware []Middleware
+}
+
+// routeTable records registered patterns; groups share their parent's table.
+type routeTable struct {
+	mu     sync.Mutex
+	routes []string
+}
+
+func (r *muxRouter) Routes() []string {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+	return append([]string(nil), r.table.routes...)
+}
+
+func (r *muxRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+func (r *muxRouter) Handle(method, pattern string, h http.Handler) {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	path := r.prefix + pattern
+	if method != "" {
+		path = method + " " + path
+	}
+	r.mux.Handle(path, h)
+
+	r.table.mu.Lock()
+	r.table.routes = append(r.table.routes, path)
+	r.table.mu.Unlock()
+}
+
+func (r *muxRouter) Group(prefix string, fn func(r Router)) {
+	fn(&muxRouter{
+		mux:        r.mux,
+		table:      r.table,
+		prefix:     r.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: append([]Middleware(nil), r.middleware...),
+	})
+}
+
+func (r *muxRouter) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func okHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func tag(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Middleware", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestServeMuxRouter(t *testing.T) {
+	t.Run("Handle matches method and pattern", func(t *testing.T) {
+		r := NewServeMuxRouter()
+		r.Handle(http.MethodGet, "/items/{id}", okHandler("item"))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+		if rec.Body.String() != "item" {
+			t.Errorf("expected body 'item', got %q", rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/1", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Group prefixes patterns and scopes middleware", func(t *testing.T) {
+		r := NewServeMuxRouter()
+		r.Use(tag("outer"))
+		r.Group("/api/", func(g Router) {
+			g.Use(tag("inner"))
+			g.Handle(http.MethodGet, "/ping", okHandler("pong"))
+		})
+		r.Handle(http.MethodGet, "/root", okHandler("root"))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+		if got := rec.Header().Values("X-Middleware"); len(got) != 2 || got[0] != "outer" || got[1] != "inner" {
+			t.Errorf("expected outer then inner middleware, got %v", got)
+		}
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/root", nil))
+		if got := rec.Header().Values("X-Middleware"); len(got) != 1 || got[0] != "outer" {
+			t.Errorf("expected only outer middleware, got %v", got)
+		}
+	})
+}
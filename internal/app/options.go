@@ -0,0 +1,99 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanarch/internal/config"
+)
+
+// EndpointPolicy is the body of an OPTIONS response: what a client may do
+// with an API path and the limits it will meet.
+type EndpointPolicy struct {
+	Path    string         `json:"path"`
+	Methods []MethodPolicy `json:"methods"`
+	// Limits is the load shedding policy, when enabled. There is no
+	// per-client rate limit.
+	Limits *LimitPolicy `json:"limits,omitempty"`
+	// ReadOnly is set while mutating methods are rejected.
+	ReadOnly bool `json:"read_only"`
+}
+
+// MethodPolicy describes one method on a path.
+type MethodPolicy struct {
+	Method string `json:"method"`
+	// MaxBodyBytes is the largest accepted body, for methods that take one.
+	MaxBodyBytes int64 `json:"max_body_bytes,omitempty"`
+	// DryRun is set for writes that accept dry_run; see WithDryRun.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Scopes are the roles the request's subject needs; empty means any caller.
+	Scopes []string `json:"scopes"`
+}
+
+// LimitPolicy reports the server-wide load shedding thresholds.
+type LimitPolicy struct {
+	MaxInFlight   int64  `json:"max_in_flight,omitempty"`
+	TargetLatency string `json:"target_latency,omitempty"`
+}
+
+// registerOptions answers OPTIONS for every API path registered on r with
+// its EndpointPolicy. Policies are assembled from the route table and cfg, so
+// they can't drift from what the middleware enforces.
+func registerOptions(r Router, cfg config.Config, readOnly *ReadOnly) {
+	methods := make(map[string][]string)
+	var paths []string
+	for _, route := range Routes(r) {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		if _, seen := methods[path]; !seen {
+			paths = append(paths, path)
+		}
+		methods[path] = append(methods[path], method)
+	}
+	for _, path := range paths {
+		policy := endpointPolicy(cfg, path, methods[path])
+		r.Handle(http.MethodOptions, path, optionsHandler(policy, readOnly))
+	}
+}
+
+func endpointPolicy(cfg config.Config, path string, methods []string) EndpointPolicy {
+	sort.Strings(methods)
+	p := EndpointPolicy{Path: path, Methods: make([]MethodPolicy, len(methods))}
+	for i, method := range methods {
+		m := MethodPolicy{Method: method, Scopes: []string{}}
+		if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+			m.MaxBodyBytes = cfg.MaxBodyBytes
+		}
+		m.DryRun = mutating(method)
+		if cfg.Authorization && adminOnly(method, path) {
+			m.Scopes = []string{"admin"}
+		}
+		p.Methods[i] = m
+	}
+	if cfg.ShedTargetLatency > 0 || cfg.ShedMaxInFlight > 0 {
+		p.Limits = &LimitPolicy{MaxInFlight: cfg.ShedMaxInFlight}
+		if cfg.ShedTargetLatency > 0 {
+			p.Limits.TargetLatency = cfg.ShedTargetLatency.Round(time.Millisecond).String()
+		}
+	}
+	return p
+}
+
+func optionsHandler(policy EndpointPolicy, readOnly *ReadOnly) http.Handler {
+	allow := []string{http.MethodOptions}
+	for _, m := range policy.Methods {
+		allow = append(allow, m.Method)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := policy
+		p.ReadOnly = readOnly.Enabled()
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p)
+	})
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/config"
+)
+
+func TestRegisterOptions(t *testing.T) {
+	options := func(cfg config.Config, readOnly bool, target string) (*httptest.ResponseRecorder, EndpointPolicy) {
+		r := NewServeMuxRouter()
+		r.Handle(http.MethodGet, "/api/v1/users", okHandler("list"))
+		r.Handle(http.MethodPost, "/api/v1/users", okHandler("create"))
+		r.Handle(http.MethodDelete, "/api/v1/users/{id}", okHandler("delete"))
+		r.Handle(http.MethodGet, "/healthz", okHandler("ok"))
+		registerOptions(r, cfg, NewReadOnly(readOnly))
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, target, nil))
+		var p EndpointPolicy
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatalf("expected JSON body, got %v", err)
+			}
+		}
+		return rec, p
+	}
+
+	t.Run("Lists methods with body limits", func(t *testing.T) {
+		rec, p := options(config.Default(), false, "/api/v1/users")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != "OPTIONS, GET, POST" {
+			t.Errorf("expected Allow 'OPTIONS, GET, POST', got %q", got)
+		}
+		if len(p.Methods) != 2 || p.Methods[0].Method != "GET" || p.Methods[1].Method != "POST" {
+			t.Fatalf("expected GET and POST, got %+v", p.Methods)
+		}
+		if p.Methods[0].MaxBodyBytes != 0 || p.Methods[1].MaxBodyBytes != config.Default().MaxBodyBytes {
+			t.Errorf("expected a body limit on POST only, got %+v", p.Methods)
+		}
+		if p.Methods[0].DryRun || !p.Methods[1].DryRun {
+			t.Errorf("expected dry runs on POST only, got %+v", p.Methods)
+		}
+		if len(p.Methods[0].Scopes) != 0 || p.Limits != nil || p.ReadOnly {
+			t.Errorf("expected no scopes, limits or read-only mode, got %+v", p)
+		}
+	})
+
+	t.Run("Reflects authorization, shedding and read-only mode", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Authorization = true
+		cfg.ShedMaxInFlight = 100
+		_, p := options(cfg, true, "/api/v1/users")
+		if strings.Join(p.Methods[0].Scopes, ",") != "admin" || len(p.Methods[1].Scopes) != 0 {
+			t.Errorf("expected admin scope on GET only, got %+v", p.Methods)
+		}
+		if p.Limits == nil || p.Limits.MaxInFlight != 100 {
+			t.Errorf("expected max in flight 100, got %+v", p.Limits)
+		}
+		if !p.ReadOnly {
+			t.Error("expected read-only mode")
+		}
+
+		_, p = options(cfg, false, "/api/v1/users/7")
+		if p.Path != "/api/v1/users/{id}" || len(p.Methods) != 1 || strings.Join(p.Methods[0].Scopes, ",") != "admin" {
+			t.Errorf("expected admin-only DELETE on /api/v1/users/{id}, got %+v", p)
+		}
+	})
+
+	t.Run("Only API paths answer", func(t *testing.T) {
+		if rec, _ := options(config.Default(), false, "/healthz"); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
+
+func TestWithBodyLimit(t *testing.T) {
+	t.Run("Oversized bodies fail to read", func(t *testing.T) {
+		var readErr error
+		h := WithBodyLimit(4, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := make([]byte, 16)
+			for readErr == nil {
+				_, readErr = r.Body.Read(buf)
+			}
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789")))
+		var tooLarge *http.MaxBytesError
+		if !errors.As(readErr, &tooLarge) {
+			t.Errorf("expected a max bytes error, got %v", readErr)
+		}
+	})
+}
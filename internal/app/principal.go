@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cleanarch/internal/domain"
+)
+
+// Principal headers. The service does no authentication itself: the proxy
+// in front of it must authenticate the caller, set these headers and strip
+// them from client requests.
+const (
+	// CallerHeader carries the ID of the authenticated user making the
+	// request.
+	CallerHeader = "X-User-ID"
+	// ServiceHeader names the service account making the request, in place
+	// of CallerHeader.
+	ServiceHeader = "X-Service-Account"
+	// ImpersonateHeader carries the ID of the user the caller acts as.
+	ImpersonateHeader = "X-Impersonate-User"
+	// ScopesHeader lists extra permissions granted to the caller,
+	// space-separated, such as domain.ScopePIIRead.
+	ScopesHeader = "X-Scopes"
+)
+
+// requestPrincipal reads the principal from r's headers. A malformed
+// CallerHeader is treated as absent, so the request is anonymous; naming
+// two actors, or impersonating or claiming scopes without an actor, is an
+// error.
+func requestPrincipal(r *http.Request) (domain.Principal, error) {
+	var p domain.Principal
+	if id, err := strconv.ParseInt(r.Header.Get(CallerHeader), 10, 64); err == nil && id > 0 {
+		p.ActorID, p.SubjectID = id, id
+	}
+	if service := r.Header.Get(ServiceHeader); service != "" {
+		if p.ActorID != 0 {
+			return domain.Principal{}, errors.New("send either " + CallerHeader + " or " + ServiceHeader)
+		}
+		p.Service = service
+	}
+	if v := r.Header.Get(ImpersonateHeader); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return domain.Principal{}, errors.New("invalid " + ImpersonateHeader)
+		}
+		if p.ActorID == 0 && p.Service == "" {
+			return domain.Principal{}, errors.New(ImpersonateHeader + " requires " + CallerHeader + " or " + ServiceHeader)
+		}
+		p.SubjectID = id
+	}
+	if scopes := strings.Fields(r.Header.Get(ScopesHeader)); len(scopes) > 0 {
+		if p.ActorID == 0 && p.Service == "" {
+			return domain.Principal{}, errors.New(ScopesHeader + " requires " + CallerHeader + " or " + ServiceHeader)
+		}
+		p.Scopes = strings.Join(scopes, " ")
+	}
+	return p, nil
+}
+
+// WithPrincipal records the request's principal in its context, where the
+// audit log and events pick it up. It trusts the headers as the Authorizer
+// does and looks nobody up; without the Authorizer, any caller may
+// impersonate.
+func WithPrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := requestPrincipal(r)
+		if err != nil {
+			denyRequest(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(domain.WithPrincipal(r.Context(), p)))
+	})
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestWithPrincipal(t *testing.T) {
+	var got domain.Principal
+	h := WithPrincipal(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = domain.PrincipalFrom(r.Context())
+	}))
+	serve := func(header http.Header) int {
+		got = domain.Principal{}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("Principals from headers", func(t *testing.T) {
+		tests := []struct {
+			header http.Header
+			want   domain.Principal
+		}{
+			{headers(), domain.Principal{}},
+			{headers(CallerHeader, "abc"), domain.Principal{}},
+			{headers(CallerHeader, "5"), domain.Principal{ActorID: 5, SubjectID: 5}},
+			{headers(CallerHeader, "5", ImpersonateHeader, "9"), domain.Principal{ActorID: 5, SubjectID: 9}},
+			{headers(ServiceHeader, "billing"), domain.Principal{Service: "billing"}},
+			{headers(ServiceHeader, "billing", ImpersonateHeader, "9"), domain.Principal{Service: "billing", SubjectID: 9}},
+			{headers(CallerHeader, "5", ScopesHeader, " pii:read  audit:read "), domain.Principal{ActorID: 5, SubjectID: 5, Scopes: "pii:read audit:read"}},
+		}
+		for _, tt := range tests {
+			if code := serve(tt.header); code != http.StatusOK || got != tt.want {
+				t.Errorf("expected %+v for %v, got %d and %+v", tt.want, tt.header, code, got)
+			}
+		}
+	})
+
+	t.Run("Malformed headers", func(t *testing.T) {
+		for _, header := range []http.Header{
+			headers(CallerHeader, "5", ServiceHeader, "billing"),
+			headers(ImpersonateHeader, "9"),
+			headers(CallerHeader, "5", ImpersonateHeader, "-1"),
+			headers(ScopesHeader, "pii:read"),
+		} {
+			if code := serve(header); code != http.StatusBadRequest {
+				t.Errorf("expected status 400 for %v, got %d", header, code)
+			}
+		}
+	})
+}
+
+// headers builds a header from key, value pairs.
+func headers(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return h
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync/atomic"
+
+	"cleanarch/internal/domain"
+)
+
+// readOnlyGauge is published at /debug/vars: 1 while read-only mode is on.
+var readOnlyGauge = expvar.NewInt("read_only")
+
+// ReadOnly rejects mutating requests with 503 while enabled; reads keep
+// working. Only PUT /admin/read-only is exempt, so the switch can be turned
+// off.
+type ReadOnly struct {
+	enabled atomic.Bool
+}
+
+func NewReadOnly(enabled bool) *ReadOnly {
+	m := &ReadOnly{}
+	m.SetEnabled(enabled)
+	return m
+}
+
+func (m *ReadOnly) Enabled() bool {
+	return m.enabled.Load()
+}
+
+func (m *ReadOnly) SetEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+	if enabled {
+		readOnlyGauge.Set(1)
+	} else {
+		readOnlyGauge.Set(0)
+	}
+}
+
+// Middleware blocks POST, PUT, PATCH and DELETE while read-only mode is on.
+// Dry runs pass, since they store nothing.
+func (m *ReadOnly) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.Enabled() && mutating(r.Method) && !togglesReadOnly(r) && !domain.IsDryRun(r.Context()) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "service is in read-only mode"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Handler reports the switch on GET and sets it on PUT with {"enabled": bool}.
+func (m *ReadOnly) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			var req struct {
+				Enabled *bool `json:"enabled"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "expected {\"enabled\": true|false}"})
+				return
+			}
+			m.SetEnabled(*req.Enabled)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"enabled": m.Enabled()})
+	})
+}
+
+// togglesReadOnly reports whether r sets the read-only switch.
+func togglesReadOnly(r *http.Request) bool {
+	return r.Method == http.MethodPut && r.URL.Path == "/admin/read-only"
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
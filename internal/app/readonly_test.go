@@ -0,0 +1,73 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	serve := func(m *ReadOnly, method, target string) int {
+		rec := httptest.NewRecorder()
+		m.Middleware(okHandler("ok")).ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec.Code
+	}
+
+	t.Run("Disabled lets writes through", func(t *testing.T) {
+		if code := serve(NewReadOnly(false), http.MethodPost, "/api/v1/users"); code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", code)
+		}
+	})
+
+	t.Run("Enabled rejects writes but not reads", func(t *testing.T) {
+		m := NewReadOnly(true)
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			if code := serve(m, method, "/api/v1/users/1"); code != http.StatusServiceUnavailable {
+				t.Errorf("expected status 503 for %s, got %d", method, code)
+			}
+		}
+		if code := serve(m, http.MethodGet, "/api/v1/users"); code != http.StatusOK {
+			t.Errorf("expected status 200 for GET, got %d", code)
+		}
+	})
+
+	t.Run("Only the switch itself is exempt", func(t *testing.T) {
+		m := NewReadOnly(true)
+		if code := serve(m, http.MethodPut, "/admin/read-only"); code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", code)
+		}
+		for _, req := range [][2]string{
+			{http.MethodPost, "/admin/consistency"},
+			{http.MethodPut, "/admin/rules/corp-only"},
+			{http.MethodDelete, "/admin/rules/corp-only"},
+		} {
+			if code := serve(m, req[0], req[1]); code != http.StatusServiceUnavailable {
+				t.Errorf("expected status 503 for %s %s, got %d", req[0], req[1], code)
+			}
+		}
+	})
+
+	t.Run("Handler toggles the switch", func(t *testing.T) {
+		m := NewReadOnly(false)
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/read-only", strings.NewReader(`{"enabled":true}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if !m.Enabled() {
+			t.Error("expected read-only to be enabled")
+		}
+		if readOnlyGauge.Value() != 1 {
+			t.Errorf("expected gauge 1, got %d", readOnlyGauge.Value())
+		}
+	})
+
+	t.Run("Handler rejects a missing flag", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewReadOnly(false).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/read-only", strings.NewReader(`{}`)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
@@ -2,23 +2,83 @@ package app
 
 import (
 	httpadapter "cleanarch/internal/adapter/http"
+	"cleanarch/internal/health"
+	"expvar"
 	"net/http"
 )
 
-func NewRouter(userHandler *httpadapter.UserHandler) *http.ServeMux {
-	mux := http.NewServeMux()
+// Handlers are the delivery handlers RegisterRoutes mounts.
+type Handlers struct {
+	Users       *httpadapter.UserHandler
+	Views       *httpadapter.ViewHandler
+	Orgs        *httpadapter.OrganizationHandler
+	Profiles    *httpadapter.ProfileHandler
+	Credentials *httpadapter.CredentialHandler
+	Bulk        *httpadapter.BulkHandler
+	Audit       *httpadapter.AuditHandler
+	Readiness   *health.Registry
+}
 
-	mux.HandleFunc("POST /api/v1/users", userHandler.CreateUser)
-	mux.HandleFunc("GET /api/v1/users", userHandler.ListUsers)
-	mux.HandleFunc("GET /api/v1/users/{id}", userHandler.GetUser)
-	mux.HandleFunc("PUT /api/v1/users/{id}", userHandler.UpdateUser)
-	mux.HandleFunc("DELETE /api/v1/users/{id}", userHandler.DeleteUser)
+// NewRouter returns the default ServeMux-backed router with all routes registered.
+func NewRouter(h Handlers) Router {
+	r := NewServeMuxRouter()
+	RegisterRoutes(r, h)
+	return r
+}
+
+// RegisterRoutes registers the service's routes on r.
+func RegisterRoutes(r Router, h Handlers) {
+	r.Group("/api/v1/users", func(r Router) {
+		r.Handle(http.MethodPost, "", http.HandlerFunc(h.Users.CreateUser))
+		r.Handle(http.MethodPost, ":bulkUpdate", http.HandlerFunc(h.Bulk.BulkUpdate))
+		r.Handle(http.MethodPost, ":bulkDelete", http.HandlerFunc(h.Bulk.BulkDelete))
+		r.Handle(http.MethodGet, "", http.HandlerFunc(h.Users.ListUsers))
+		r.Handle(http.MethodGet, "/stats", http.HandlerFunc(h.Users.UserStats))
+		r.Handle(http.MethodGet, "/count", http.HandlerFunc(h.Users.CountUsers))
+		r.Handle(http.MethodGet, "/by-external-id", http.HandlerFunc(h.Users.GetUserByExternalID))
+		r.Handle(http.MethodGet, "/search", http.HandlerFunc(h.Users.SearchUsers))
+		r.Handle(http.MethodGet, "/{id}", http.HandlerFunc(h.Users.GetUser))
+		r.Handle(http.MethodPut, "/{id}", http.HandlerFunc(h.Users.UpdateUser))
+		r.Handle(http.MethodDelete, "/{id}", http.HandlerFunc(h.Users.DeleteUser))
+		r.Handle(http.MethodPost, "/{id}/suspend", http.HandlerFunc(h.Users.SuspendUser))
+		r.Handle(http.MethodPost, "/{id}/activate", http.HandlerFunc(h.Users.ActivateUser))
+		r.Handle(http.MethodPost, "/{id}/anonymize", http.HandlerFunc(h.Users.AnonymizeUser))
+		r.Handle(http.MethodPut, "/{id}/tags", http.HandlerFunc(h.Users.SetUserTags))
+		r.Handle(http.MethodPatch, "/{id}/metadata", http.HandlerFunc(h.Users.PatchUserMetadata))
+		r.Handle(http.MethodPut, "/{id}/external-ids", http.HandlerFunc(h.Users.SetUserExternalIDs))
+		r.Handle(http.MethodGet, "/{id}/profile", http.HandlerFunc(h.Profiles.GetProfile))
+		r.Handle(http.MethodPut, "/{id}/profile", http.HandlerFunc(h.Profiles.PutProfile))
+		r.Handle(http.MethodDelete, "/{id}/profile", http.HandlerFunc(h.Profiles.DeleteProfile))
+		r.Handle(http.MethodPut, "/{id}/password", http.HandlerFunc(h.Credentials.SetPassword))
+		r.Handle(http.MethodDelete, "/{id}/password", http.HandlerFunc(h.Credentials.DeletePassword))
+	})
+	r.Group("/api/v1/views", func(r Router) {
+		r.Handle(http.MethodPost, "", http.HandlerFunc(h.Views.CreateView))
+		r.Handle(http.MethodGet, "", http.HandlerFunc(h.Views.ListViews))
+		r.Handle(http.MethodGet, "/{id}", http.HandlerFunc(h.Views.GetView))
+		r.Handle(http.MethodDelete, "/{id}", http.HandlerFunc(h.Views.DeleteView))
+		r.Handle(http.MethodGet, "/{id}/results", http.HandlerFunc(h.Views.Results))
+	})
+	r.Group("/api/v1/orgs", func(r Router) {
+		r.Handle(http.MethodPost, "", http.HandlerFunc(h.Orgs.CreateOrganization))
+		r.Handle(http.MethodGet, "", http.HandlerFunc(h.Orgs.ListOrganizations))
+		r.Handle(http.MethodGet, "/{id}", http.HandlerFunc(h.Orgs.GetOrganization))
+		r.Handle(http.MethodDelete, "/{id}", http.HandlerFunc(h.Orgs.DeleteOrganization))
+		r.Handle(http.MethodGet, "/{id}/members", http.HandlerFunc(h.Orgs.ListMembers))
+		r.Handle(http.MethodPost, "/{id}/members", http.HandlerFunc(h.Orgs.AddMember))
+		r.Handle(http.MethodDelete, "/{id}/members/{user_id}", http.HandlerFunc(h.Orgs.RemoveMember))
+	})
+	r.Handle(http.MethodGet, "/api/v1/operations/{id}", http.HandlerFunc(h.Bulk.GetOperation))
+	r.Handle(http.MethodGet, "/api/v1/audit", http.HandlerFunc(h.Audit.ListAudit))
 
 	// Healthcheck
-	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+	r.Handle(http.MethodGet, "/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
-	})
+	}))
+	r.Handle(http.MethodGet, "/readyz", h.Readiness.Handler())
+	r.Handle(http.MethodGet, "/status", h.Readiness.StatusHandler())
 
-	return mux
+	// Runtime and repository metrics
+	r.Handle(http.MethodGet, "/debug/vars", expvar.Handler())
 }
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"cleanarch/internal/health"
+	"cleanarch/internal/slo"
+	"cleanarch/internal/usecase"
+)
+
+// Server is the fully assembled service.
+type Server struct {
+	HTTP      *http.Server
+	Router    Router
+	Lifecycle *Lifecycle
+	Readiness *health.Registry
+	ReadOnly  *ReadOnly
+	Rules     *usecase.RuleSet
+	SLO       *slo.Tracker
+	// Events carries user mutations; subscribe to react to them.
+	Events *EventBus
+	// References are checked before a user is deleted; register sources
+	// for aggregates that refer to users.
+	References *usecase.References
+	// Consistency checks for records left referring to deleted users;
+	// register sources for aggregates that hold such records.
+	Consistency *ConsistencyJob
+	Diagnostics Diagnostics
+}
+
+// Start starts every component; the HTTP listener is bound before Start returns.
+func (s *Server) Start(ctx context.Context) error {
+	return s.Lifecycle.Start(ctx)
+}
+
+// Stop gracefully stops every started component in reverse start order.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.Lifecycle.Stop(ctx)
+}
+
+// EmbedderHookTimeout bounds each function registered with OnStart or OnStop.
+const EmbedderHookTimeout = 30 * time.Second
+
+// OnStart registers fn to run during Start, after the server's components
+// and before the HTTP listener opens, so requests can rely on it. Functions
+// run in registration order; one that fails or exceeds EmbedderHookTimeout
+// fails Start. Register before calling Start.
+func (s *Server) OnStart(name string, fn func(ctx context.Context) error) {
+	s.Lifecycle.InsertBefore(httpHookName, Hook{Name: name, OnStart: fn, Timeout: EmbedderHookTimeout})
+}
+
+// OnStop registers fn to run during Stop, after the HTTP server has drained
+// and before the server's components stop. Functions run in reverse
+// registration order; errors, including exceeding EmbedderHookTimeout, are
+// returned from Stop after every hook has run. Register before calling Start.
+func (s *Server) OnStop(name string, fn func(ctx context.Context) error) {
+	s.Lifecycle.InsertBefore(httpHookName, Hook{Name: name, OnStop: fn, Timeout: EmbedderHookTimeout})
+}
+
+// httpHookName names the HTTP listener's hook, the last one registered by
+// NewServer.
+const httpHookName = "http"
+
+// httpServerHook binds srv's address on start, so errors like "address in
+// use" fail Start, and serves in the background until shut down on stop.
+func httpServerHook(srv *http.Server) Hook {
+	return Hook{
+		Name: httpHookName,
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			log.Printf("HTTP server listening on %s", ln.Addr())
+			go func() {
+				if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+					log.Printf("server error: %v", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"encoding/json"
+	"expvar"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cleanarch/internal/priority"
+)
+
+// shedMetrics is published at /debug/vars as "load_shedding": "shed" counts
+// rejected requests ("shed.<class>" per priority class), "drop_rate" is the current fraction of low-priority
+// requests rejected and "in_flight" the requests being served.
+var shedMetrics = expvar.NewMap("load_shedding")
+
+// ShedOptions configures a Shedder.
+type ShedOptions struct {
+	// TargetLatency is the latency every request in an interval may exceed
+	// before the shedder considers the service overloaded. Zero disables
+	// latency-based shedding.
+	TargetLatency time.Duration
+	// Interval over which the minimum latency is measured; defaults to 100ms.
+	Interval time.Duration
+	// MaxInFlight sheds low-priority requests while at least this many
+	// requests are being served. Zero means no limit.
+	MaxInFlight int64
+	// Step is how much the drop rate changes per interval; defaults to 0.1.
+	Step float64
+}
+
+// Shedder rejects requests with 503 under overload, batch traffic first.
+// Like CoDel, it treats an interval whose fastest request still exceeded the
+// target as a standing queue, raising the drop rate each such interval and
+// lowering it once latency recovers. Batch requests are dropped at the drop
+// rate; interactive ones only once it passes one half, and admin requests
+// are never shed.
+type Shedder struct {
+	opts     ShedOptions
+	inFlight atomic.Int64
+
+	mu            sync.Mutex
+	intervalStart time.Time
+	minLatency    time.Duration
+	dropRate      float64
+}
+
+func NewShedder(opts ShedOptions) *Shedder {
+	if opts.Interval <= 0 {
+		opts.Interval = 100 * time.Millisecond
+	}
+	if opts.Step <= 0 {
+		opts.Step = 0.1
+	}
+	return &Shedder{opts: opts}
+}
+
+// DropRate returns the current fraction of low-priority requests rejected.
+func (s *Shedder) DropRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropRate
+}
+
+// observe feeds a finished request's latency into the control loop.
+func (s *Shedder) observe(latency time.Duration, now time.Time) {
+	if s.opts.TargetLatency <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.intervalStart.IsZero() {
+		s.intervalStart, s.minLatency = now, latency
+		return
+	}
+	if latency < s.minLatency {
+		s.minLatency = latency
+	}
+	if now.Sub(s.intervalStart) < s.opts.Interval {
+		return
+	}
+	if s.minLatency > s.opts.TargetLatency {
+		s.dropRate = min(1, s.dropRate+s.opts.Step)
+	} else {
+		s.dropRate = max(0, s.dropRate-s.opts.Step)
+	}
+	s.intervalStart, s.minLatency = now, latency
+	shedMetrics.Set("drop_rate", floatVar(s.dropRate))
+}
+
+func (s *Shedder) shouldShed(class priority.Class, inFlight int64) bool {
+	rate := s.DropRate()
+	limit := s.opts.MaxInFlight
+	switch class {
+	case priority.Admin:
+		return false
+	case priority.Interactive:
+		// Interactive traffic gets the second half of the control range
+		// and twice the in-flight allowance.
+		rate = 2*rate - 1
+		limit *= 2
+	}
+	if limit > 0 && inFlight > limit {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// Middleware sheds requests and measures the latency of the ones it admits.
+func (s *Shedder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		shedMetrics.Set("in_flight", intVar(inFlight))
+
+		if class := priority.Of(r); s.shouldShed(class, inFlight) {
+			shedMetrics.Add("shed", 1)
+			shedMetrics.Add("shed."+class.String(), 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "server overloaded, retry later"})
+			return
+		}
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		s.observe(time.Since(start), time.Now())
+	})
+}
+
+func floatVar(v float64) *expvar.Float {
+	f := new(expvar.Float)
+	f.Set(v)
+	return f
+}
+
+func intVar(v int64) *expvar.Int {
+	i := new(expvar.Int)
+	i.Set(v)
+	return i
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cleanarch/internal/priority"
+)
+
+func TestShedder(t *testing.T) {
+	t.Run("Drop rate follows the minimum latency per interval", func(t *testing.T) {
+		s := NewShedder(ShedOptions{TargetLatency: 50 * time.Millisecond, Interval: time.Second, Step: 0.5})
+		now := time.Unix(0, 0)
+
+		s.observe(80*time.Millisecond, now)
+		s.observe(90*time.Millisecond, now.Add(time.Second))
+		if got := s.DropRate(); got != 0.5 {
+			t.Fatalf("expected drop rate 0.5 after a slow interval, got %v", got)
+		}
+		s.observe(90*time.Millisecond, now.Add(2*time.Second))
+		if got := s.DropRate(); got != 1 {
+			t.Fatalf("expected drop rate capped at 1, got %v", got)
+		}
+
+		s.observe(time.Millisecond, now.Add(2500*time.Millisecond))
+		s.observe(90*time.Millisecond, now.Add(3*time.Second))
+		if got := s.DropRate(); got != 0.5 {
+			t.Errorf("expected one fast request to lower the drop rate, got %v", got)
+		}
+	})
+
+	t.Run("Priority classes are shed in order", func(t *testing.T) {
+		s := NewShedder(ShedOptions{TargetLatency: time.Millisecond})
+		s.dropRate = 0.5
+		if s.shouldShed(priority.Interactive, 1) {
+			t.Error("expected interactive traffic to be kept at drop rate 0.5")
+		}
+		s.dropRate = 1
+		if !s.shouldShed(priority.Interactive, 1) {
+			t.Error("expected interactive traffic to be shed at drop rate 1")
+		}
+		if s.shouldShed(priority.Admin, 1) {
+			t.Error("expected admin traffic never to be shed")
+		}
+	})
+
+	t.Run("Batch requests are shed first", func(t *testing.T) {
+		s := NewShedder(ShedOptions{TargetLatency: time.Millisecond})
+		s.dropRate = 1
+
+		serve := func(method, target string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			s.Middleware(okHandler("ok")).ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+			return rec
+		}
+		rec := serve(http.MethodGet, "/api/v1/users")
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+			t.Errorf("expected 503 with Retry-After for a listing, got %d", rec.Code)
+		}
+		if rec := serve(http.MethodPut, "/admin/read-only"); rec.Code != http.StatusOK {
+			t.Errorf("expected admin requests to be served, got %d", rec.Code)
+		}
+	})
+
+	t.Run("In-flight limit", func(t *testing.T) {
+		s := NewShedder(ShedOptions{MaxInFlight: 1})
+		if s.shouldShed(priority.Batch, 1) {
+			t.Error("expected batch request within the limit to be admitted")
+		}
+		if !s.shouldShed(priority.Batch, 2) {
+			t.Error("expected batch request over the limit to be shed")
+		}
+		if s.shouldShed(priority.Interactive, 2) || !s.shouldShed(priority.Interactive, 3) {
+			t.Error("expected interactive requests to get twice the limit")
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cleanarch/internal/health"
+)
+
+// ProcessStatus is what the status socket reports.
+type ProcessStatus struct {
+	Ready             bool          `json:"ready"`
+	Readiness         health.Report `json:"readiness"`
+	Version           string        `json:"version"`
+	ActiveConnections int64         `json:"active_connections"`
+	UptimeSeconds     int64         `json:"uptime_seconds"`
+}
+
+// connCounter counts open HTTP connections. Install track as the server's
+// ConnState hook.
+type connCounter struct {
+	open atomic.Int64
+}
+
+func (c *connCounter) track(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		c.open.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		c.open.Add(-1)
+	}
+}
+
+// buildVersion reports the main module version and VCS revision the binary
+// was built from, or "devel" when they aren't recorded.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	version := info.Main.Version
+	if version == "" || version == "(devel)" {
+		version = "devel"
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			return version + " (" + s.Value + ")"
+		}
+	}
+	return version
+}
+
+// StatusSocket serves ProcessStatus on a Unix socket for local supervisors
+// that can't or shouldn't speak HTTP. The protocol is one JSON object per
+// connection: connect, read until EOF. For example:
+//
+//	socat - UNIX-CONNECT:/run/cleanarch.sock
+type StatusSocket struct {
+	path      string
+	readiness *health.Registry
+	conns     *connCounter
+	started   time.Time
+
+	ln net.Listener
+	wg sync.WaitGroup
+}
+
+func newStatusSocket(path string, readiness *health.Registry, conns *connCounter) *StatusSocket {
+	return &StatusSocket{path: path, readiness: readiness, conns: conns}
+}
+
+// Status runs the readiness checks and assembles a report.
+func (s *StatusSocket) Status(ctx context.Context) ProcessStatus {
+	report := s.readiness.Run(ctx)
+	return ProcessStatus{
+		Ready:             report.Status == health.StatusUp,
+		Readiness:         report,
+		Version:           buildVersion(),
+		ActiveConnections: s.conns.open.Load(),
+		UptimeSeconds:     int64(time.Since(s.started).Seconds()),
+	}
+}
+
+// Start listens on the socket path, replacing a stale socket left by a
+// previous process. Anything else at the path, including a socket another
+// process still serves, is left alone and fails the start.
+func (s *StatusSocket) Start(ctx context.Context) error {
+	if err := removeStaleSocket(s.path); err != nil {
+		return err
+	}
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	s.started = time.Now()
+	log.Printf("status socket listening on %s", s.path)
+	s.wg.Add(1)
+	go s.serve()
+	return nil
+}
+
+// removeStaleSocket removes the socket at path if nothing accepts
+// connections on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+func (s *StatusSocket) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), health.DefaultTimeout+time.Second)
+			defer cancel()
+			_ = conn.SetWriteDeadline(time.Now().Add(health.DefaultTimeout + 2*time.Second))
+			_ = json.NewEncoder(conn).Encode(s.Status(ctx))
+		}()
+	}
+}
+
+// Stop closes the listener, which also removes the socket file, and waits
+// for in-flight reports.
+func (s *StatusSocket) Stop(ctx context.Context) error {
+	if s.ln == nil {
+		return nil
+	}
+	err := s.ln.Close()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cleanarch/internal/health"
+)
+
+func TestStatusSocket(t *testing.T) {
+	readStatus := func(t *testing.T, path string) ProcessStatus {
+		t.Helper()
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+		var status ProcessStatus
+		if err := json.NewDecoder(conn).Decode(&status); err != nil {
+			t.Fatalf("expected a JSON status, got %v", err)
+		}
+		return status
+	}
+
+	t.Run("Reports readiness, version and connections", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.sock")
+		readiness := health.NewRegistry()
+		readiness.Register("warmup", func(ctx context.Context) error { return errors.New("warming up") })
+		conns := &connCounter{}
+		conns.track(nil, http.StateNew)
+		conns.track(nil, http.StateNew)
+		conns.track(nil, http.StateClosed)
+
+		s := newStatusSocket(path, readiness, conns)
+		if err := s.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer s.Stop(context.Background())
+
+		status := readStatus(t, path)
+		if status.Ready || status.Readiness.Checks["warmup"].Error != "warming up" {
+			t.Errorf("expected not ready while warming up, got %+v", status)
+		}
+		if status.Version == "" || status.ActiveConnections != 1 {
+			t.Errorf("expected a version and 1 connection, got %+v", status)
+		}
+
+		readiness.Register("warmup", func(ctx context.Context) error { return nil })
+		if status := readStatus(t, path); !status.Ready {
+			t.Errorf("expected ready, got %+v", status)
+		}
+	})
+
+	t.Run("Replaces a stale socket and removes it on stop", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.sock")
+		stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stale.SetUnlinkOnClose(false)
+		stale.Close()
+		s := newStatusSocket(path, health.NewRegistry(), &connCounter{})
+		if err := s.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := s.Stop(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected the socket file to be removed, got %v", err)
+		}
+	})
+
+	t.Run("Leaves other files and live sockets alone", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "status.sock")
+		if err := os.WriteFile(file, []byte("keep"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		live := filepath.Join(t.TempDir(), "status.sock")
+		ln, err := net.Listen("unix", live)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+
+		for _, path := range []string{file, live} {
+			if err := newStatusSocket(path, health.NewRegistry(), &connCounter{}).Start(context.Background()); err == nil {
+				t.Errorf("expected an error for %s", path)
+			}
+			if _, err := os.Lstat(path); err != nil {
+				t.Errorf("expected %s to be kept, got %v", path, err)
+			}
+		}
+	})
+}
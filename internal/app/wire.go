@@ -0,0 +1,381 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	httpadapter "cleanarch/internal/adapter/http"
+	"cleanarch/internal/config"
+	"cleanarch/internal/domain"
+	"cleanarch/internal/fieldcrypt"
+	"cleanarch/internal/fixture"
+	"cleanarch/internal/health"
+	"cleanarch/internal/metrics"
+	"cleanarch/internal/password"
+	"cleanarch/internal/priority"
+	"cleanarch/internal/repository"
+	"cleanarch/internal/repository/memory"
+	"cleanarch/internal/slo"
+	"cleanarch/internal/usecase"
+	"cleanarch/internal/usecase/fake"
+)
+
+// This file is the composition root: each provider builds one component
+// from config and its dependencies, and NewServer calls them in dependency
+// order. Components with resources register lifecycle hooks.
+
+// ServerOptions selects optional server modes.
+type ServerOptions struct {
+	// Mock serves deterministic fake data instead of the repository when set.
+	Mock *fake.Options
+	// RecordDir records request/response fixtures into the directory when set.
+	RecordDir string
+	// ReplayDir serves recorded fixtures instead of the API when set.
+	ReplayDir string
+	// Hooks are custom business rules run by the user use case.
+	Hooks *usecase.Hooks
+}
+
+// NewServer wires repositories, use cases, handlers and middleware from cfg.
+// It is shared by cmd/server and by harnesses that boot the full stack.
+func NewServer(cfg config.Config, opts ServerOptions) *Server {
+	s := &Server{
+		Lifecycle:  &Lifecycle{},
+		Readiness:  health.NewRegistry(),
+		ReadOnly:   NewReadOnly(cfg.ReadOnly),
+		SLO:        provideSLOTracker(cfg),
+		Rules:      usecase.NewRuleSet(),
+		Events:     NewEventBus(),
+		References: usecase.NewReferences(),
+	}
+	if opts.Mock != nil {
+		cfg.RepositoryBackend = "mock"
+	}
+
+	if opts.Hooks == nil {
+		opts.Hooks = usecase.NewHooks()
+	}
+	s.Rules.Attach(opts.Hooks)
+	s.Readiness.OnTransition(logTransition)
+	configureHistograms(cfg)
+
+	var audit domain.AuditRepository = memory.NewInMemoryAuditRepository()
+	if sensitive := provideSensitiveFields(cfg); !sensitive.Empty() {
+		audit = repository.WithAuditEncryption(provideFieldCipher(cfg), sensitive)(audit)
+	}
+	orgRepo := memory.NewInMemoryOrganizationRepository()
+	users := provideUserService(cfg, opts, s, audit, orgRepo)
+	views := usecase.NewViewService(memory.NewInMemoryViewRepository(), users)
+	orgs := usecase.NewOrganizationService(orgRepo, users)
+	s.References.Register(orgs)
+	profiles := usecase.NewProfileService(memory.NewInMemoryProfileRepository(), users)
+	credentials := usecase.NewCredentialService(memory.NewInMemoryCredentialRepository(), users, password.PBKDF2{})
+	checker := usecase.NewConsistencyChecker(users, cfg.ConsistencyRepair)
+	checker.PauseRepairWhile(s.ReadOnly.Enabled)
+	s.Consistency = newConsistencyJob(checker, cfg.ConsistencyInterval)
+	s.Consistency.Register(orgs)
+	s.Consistency.Register(profiles)
+	s.Consistency.Register(credentials)
+	if cfg.ConsistencyInterval > 0 {
+		s.Lifecycle.Append(Hook{Name: "consistency", OnStart: s.Consistency.Start, OnStop: s.Consistency.Stop})
+	}
+	s.Events.Subscribe(func(ctx context.Context, e domain.Event) {
+		if err := orgs.RemoveUser(ctx, e.User.ID); err != nil {
+			log.Printf("removing deleted user %d from organizations: %v", e.User.ID, err)
+		}
+	}, domain.UserDeleted)
+	s.Events.Subscribe(func(ctx context.Context, e domain.Event) {
+		if err := profiles.DeleteProfile(ctx, e.User.ID); err != nil && !errors.Is(err, domain.ErrProfileNotFound) {
+			log.Printf("deleting profile of user %d after %s: %v", e.User.ID, e.Type, err)
+		}
+		if err := credentials.DeletePassword(ctx, e.User.ID); err != nil && !errors.Is(err, domain.ErrCredentialNotFound) {
+			log.Printf("deleting password of user %d after %s: %v", e.User.ID, e.Type, err)
+		}
+	}, domain.UserDeleted, domain.UserAnonymized)
+	bulk := usecase.NewBulkService(users,
+		usecase.WithDeletePause(cfg.BulkDeletePause),
+		usecase.WithAudit(logAudit),
+		usecase.WithPauseWhile(s.ReadOnly.Enabled),
+	)
+	s.Lifecycle.Append(Hook{Name: "bulk_operations", OnStop: bulk.Stop})
+	cursors := httpadapter.WithCursors(httpadapter.NewCursors([]byte(cfg.CursorSecret), cfg.CursorTTL))
+	masking := httpadapter.WithSensitiveFields(provideSensitiveFields(cfg))
+	s.Router = provideRouter(cfg, Handlers{
+		Users:       httpadapter.NewUserHandler(users, cursors, masking, httpadapter.WithListCoalescing(cfg.ListCacheTTL)),
+		Views:       httpadapter.NewViewHandler(views, cursors, masking),
+		Orgs:        httpadapter.NewOrganizationHandler(orgs, masking),
+		Profiles:    httpadapter.NewProfileHandler(profiles),
+		Credentials: httpadapter.NewCredentialHandler(credentials),
+		Bulk:        httpadapter.NewBulkHandler(bulk),
+		Audit:       httpadapter.NewAuditHandler(usecase.NewAuditService(audit), cursors, masking),
+		Readiness:   s.Readiness,
+	}, s)
+	root, middleware := provideRootHandler(cfg, opts, s, users)
+	s.Diagnostics = NewDiagnostics(cfg, s.Router, middleware...)
+	s.HTTP = provideHTTPServer(cfg, root)
+	conns := &connCounter{}
+	s.HTTP.ConnState = conns.track
+	if cfg.StatusSocket != "" {
+		status := newStatusSocket(cfg.StatusSocket, s.Readiness, conns)
+		s.Lifecycle.Append(Hook{Name: "status_socket", OnStart: status.Start, OnStop: status.Stop})
+	}
+	if cfg.StatsDAddr != "" {
+		s.Lifecycle.Append(metricsPushHook(cfg))
+	}
+	s.Lifecycle.Append(httpServerHook(s.HTTP))
+	return s
+}
+
+// logTransition writes a line when a readiness check changes status, so
+// log-based alerting sees dependencies going down and coming back.
+func logTransition(t health.Transition) {
+	msg := fmt.Sprintf("readiness: %s went %s -> %s", t.Component, t.From, t.To)
+	if t.Error != "" {
+		msg += ": " + t.Error
+	}
+	if t.Flapping {
+		msg += " (flapping)"
+	}
+	log.Print(msg)
+}
+
+// logAudit writes an audit line for each user removed by a bulk operation,
+// naming the principal that started it. Users appear by ID only.
+func logAudit(ctx context.Context, e usecase.AuditEntry) {
+	log.Printf("audit: operation %d deleted user %d at %s by %s", e.Operation, e.UserID, e.At.Format(time.RFC3339), domain.PrincipalFrom(ctx))
+}
+
+// metricsPushHook pushes the expvar metric set to StatsD while the server runs.
+func metricsPushHook(cfg config.Config) Hook {
+	p := metrics.NewPusher(metrics.PushOptions{
+		Addr:     cfg.StatsDAddr,
+		Interval: cfg.MetricsPushInterval,
+		Prefix:   cfg.MetricsPrefix,
+	})
+	return Hook{Name: "metrics_push", OnStart: p.Start, OnStop: p.Stop}
+}
+
+// repositoryRetry retries reads that fail transiently. Each attempt gets
+// its own repositoryShare of the time left.
+var repositoryRetry = repository.RetryPolicy{Attempts: 3, Backoff: 20 * time.Millisecond}
+
+// repositoryShare is the fraction of a request's remaining time one
+// repository call may use; a use case making two calls in turn still has
+// a quarter left after both.
+const repositoryShare = 0.5
+
+func provideUserService(cfg config.Config, opts ServerOptions, s *Server, audit domain.AuditRepository, orgs domain.OrganizationRepository) usecase.UserUsecase {
+	if opts.Mock != nil {
+		return fake.New(*opts.Mock)
+	}
+	decorators := []repository.Decorator{repository.WithMetrics()}
+	if cfg.CacheTTL > 0 {
+		decorators = append(decorators, repository.WithCache(repository.NewMemoryCache(cfg.CacheTTL)))
+	}
+	decorators = append(decorators, repository.WithRetry(repositoryRetry), repository.WithDeadline(repositoryShare))
+	if sensitive := provideSensitiveFields(cfg); !sensitive.Empty() {
+		decorators = append(decorators, repository.WithFieldEncryption(provideFieldCipher(cfg), sensitive))
+	}
+	repo := repository.Wrap(memory.NewInMemoryUserRepository(), decorators...)
+	s.Readiness.Register("user_repository", func(ctx context.Context) error {
+		_, err := repo.LastModified(ctx)
+		return err
+	})
+	if cfg.WarmupUsers > 0 {
+		s.Lifecycle.Append(warmupHook(repo, cfg.WarmupUsers, s.Readiness))
+	}
+	options := []usecase.Option{
+		usecase.WithHooks(opts.Hooks),
+		usecase.WithEvents(s.Events),
+		usecase.WithReferences(s.References),
+		usecase.WithAuditLog(audit),
+		usecase.WithOrganizations(orgs),
+	}
+	if cfg.Authorization {
+		options = append(options, usecase.WithRoleAuthorization())
+	}
+	return usecase.NewUserService(repo, options...)
+}
+
+// warmupHook loads recently updated users into the cache in the background
+// on start; readiness reports "warmup" down until it has finished. Stopping
+// the server cancels an unfinished warm-up.
+func warmupHook(repo domain.UserRepository, n int, readiness *health.Registry) Hook {
+	var done atomic.Bool
+	cancel := func() {}
+	readiness.Register("warmup", func(ctx context.Context) error {
+		if !done.Load() {
+			return errors.New("warming up")
+		}
+		return nil
+	})
+	return Hook{
+		Name: "warmup",
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			go func() {
+				defer done.Store(true)
+				start := time.Now()
+				loaded, err := repository.Warm(ctx, repo, n)
+				if err != nil {
+					log.Printf("warm-up stopped after %d users: %v", loaded, err)
+					return
+				}
+				log.Printf("warm-up loaded %d users in %s", loaded, time.Since(start))
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	}
+}
+
+func provideRouter(cfg config.Config, handlers Handlers, s *Server) Router {
+	s.Readiness.RegisterDetail("read_only", func() any { return s.ReadOnly.Enabled() })
+
+	mux := NewRouter(handlers)
+	mux.Handle(http.MethodGet, "/admin/config", DiagnosticsHandler(&s.Diagnostics))
+	mux.Handle(http.MethodGet, "/admin/read-only", s.ReadOnly.Handler())
+	mux.Handle(http.MethodPut, "/admin/read-only", s.ReadOnly.Handler())
+	mux.Handle(http.MethodGet, "/admin/slo", s.SLO.Handler())
+	mux.Handle(http.MethodGet, "/admin/health", s.Readiness.DetailHandler())
+
+	rules := httpadapter.NewRuleHandler(s.Rules)
+	mux.Handle(http.MethodGet, "/admin/rules", http.HandlerFunc(rules.ListRules))
+	mux.Handle(http.MethodPut, "/admin/rules/{name}", http.HandlerFunc(rules.PutRule))
+	mux.Handle(http.MethodDelete, "/admin/rules/{name}", http.HandlerFunc(rules.DeleteRule))
+
+	consistency := httpadapter.NewConsistencyHandler(s.Consistency)
+	mux.Handle(http.MethodGet, "/admin/consistency", http.HandlerFunc(consistency.Report))
+	mux.Handle(http.MethodPost, "/admin/consistency", http.HandlerFunc(consistency.Run))
+
+	registerOptions(mux, cfg, s.ReadOnly)
+	registerExamples(mux, cfg)
+	return mux
+}
+
+// layer is one named middleware of the root handler.
+type layer struct {
+	name string
+	wrap func(http.Handler) http.Handler
+}
+
+// provideRootHandler wraps the router in the middleware chain and returns
+// the chain's names, outermost first, for the diagnostics. Replaying
+// fixtures replaces the router and the middleware that only matters to
+// live requests.
+func provideRootHandler(cfg config.Config, opts ServerOptions, s *Server, users usecase.UserUsecase) (http.Handler, []string) {
+	// The deadline covers time spent queued by the shedder and authorizing.
+	layers := []layer{
+		{"logging", WithLogging},
+		{"priority", priority.Middleware},
+		{"deadline", func(h http.Handler) http.Handler { return WithDeadline(cfg.WriteTimeout, h) }},
+	}
+	if cfg.ShedTargetLatency > 0 || cfg.ShedMaxInFlight > 0 {
+		layers = append(layers, layer{"load_shedding", NewShedder(ShedOptions{
+			TargetLatency: cfg.ShedTargetLatency,
+			MaxInFlight:   cfg.ShedMaxInFlight,
+		}).Middleware})
+	}
+	// Replayed responses are authorized like live ones.
+	if cfg.Authorization {
+		layers = append(layers, layer{"authorization", NewAuthorizer(users).Middleware})
+	}
+
+	var root http.Handler = s.Router
+	switch {
+	case opts.ReplayDir != "":
+		log.Printf("replaying fixtures from %s", opts.ReplayDir)
+		root = fixture.Replay(opts.ReplayDir, provideFixtureOptions(cfg))
+		layers = append(layers, layer{name: "replay"})
+	case opts.RecordDir != "":
+		log.Printf("recording fixtures into %s", opts.RecordDir)
+		layers = append(layers, layer{"record", func(h http.Handler) http.Handler {
+			return fixture.Record(opts.RecordDir, provideFixtureOptions(cfg), h)
+		}})
+	}
+	if opts.ReplayDir == "" {
+		// The SLO tracker wraps the router directly to see the matched pattern.
+		layers = append(layers,
+			layer{"principal", WithPrincipal},
+			layer{"dry_run", WithDryRun},
+			layer{"read_only", s.ReadOnly.Middleware},
+			layer{"slo", s.SLO.Middleware},
+			layer{"body_limit", func(h http.Handler) http.Handler { return WithBodyLimit(cfg.MaxBodyBytes, h) }},
+		)
+	}
+
+	names := make([]string, len(layers))
+	for i := len(layers) - 1; i >= 0; i-- {
+		names[i] = layers[i].name
+		if layers[i].wrap != nil {
+			root = layers[i].wrap(root)
+		}
+	}
+	return root, names
+}
+
+// configureHistograms applies the configured bucket bounds, falling back to
+// the defaults for those not set.
+func configureHistograms(cfg config.Config) {
+	// config.Load has already validated the bounds.
+	latency, size := metrics.LatencyBuckets, metrics.SizeBuckets
+	if cfg.LatencyBuckets != "" {
+		latency, _ = metrics.ParseBuckets(cfg.LatencyBuckets)
+	}
+	if cfg.SizeBuckets != "" {
+		size, _ = metrics.ParseBuckets(cfg.SizeBuckets)
+	}
+	httpadapter.SetHistogramBuckets(latency, size)
+}
+
+func provideSLOTracker(cfg config.Config) *slo.Tracker {
+	// config.Load has already validated the spec.
+	objectives, _ := slo.Parse(cfg.SLOs)
+	return slo.NewTracker(objectives, cfg.SLOWindow)
+}
+
+// provideFixtureOptions keys fixtures by the caller's identity, so one
+// caller's recorded response isn't replayed to another, and keeps emails
+// and sensitive metadata out of them.
+func provideFixtureOptions(cfg config.Config) fixture.Options {
+	return fixture.Options{
+		Headers: []string{CallerHeader, ServiceHeader, ImpersonateHeader, ScopesHeader},
+		Redact:  append([]string{"email"}, provideSensitiveFields(cfg).Metadata...),
+	}
+}
+
+func provideSensitiveFields(cfg config.Config) domain.SensitiveFields {
+	// config.Load has already validated the list.
+	fields, _ := domain.ParseSensitiveFields(cfg.SensitiveFields)
+	return fields
+}
+
+// provideFieldCipher builds the cipher for sensitive fields. config.Load
+// requires a valid key whenever fields are marked, so a failure here is a
+// wiring bug rather than bad input.
+func provideFieldCipher(cfg config.Config) *fieldcrypt.AESGCM {
+	key, _ := fieldcrypt.ParseKey(cfg.FieldEncryptionKey)
+	c, err := fieldcrypt.New(key)
+	if err != nil {
+		panic("FIELD_ENCRYPTION_KEY: " + err.Error())
+	}
+	return c
+}
+
+func provideHTTPServer(cfg config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
@@ -0,0 +1,111 @@
+// Package archtest enforces clean-architecture import boundaries. It parses
+// the import blocks of every non-test Go file under a module root and reports
+// imports that a layer rule forbids, in go vet's file:line format.
+package archtest
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule forbids packages under Layer (an import path prefix) from importing
+// any path under the Forbid prefixes.
+type Rule struct {
+	Layer  string
+	Forbid []string
+	Reason string
+}
+
+// Violation is one forbidden import.
+type Violation struct {
+	Pos     token.Position
+	Package string
+	Import  string
+	Reason  string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s imports %s: %s", v.Pos, v.Package, v.Import, v.Reason)
+}
+
+// Check walks the module rooted at root and returns every violation of rules.
+func Check(root string, rules []Rule) ([]Violation, error) {
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var violations []Violation
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); p != root && (strings.HasPrefix(name, ".") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		pkg := path.Join(module, filepath.ToSlash(rel))
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, imp := range f.Imports {
+			target, _ := strconv.Unquote(imp.Path.Value)
+			for _, rule := range rules {
+				if !under(pkg, rule.Layer) {
+					continue
+				}
+				for _, forbidden := range rule.Forbid {
+					if under(target, forbidden) && !under(target, rule.Layer) {
+						violations = append(violations, Violation{
+							Pos:     fset.Position(imp.Pos()),
+							Package: pkg,
+							Import:  target,
+							Reason:  rule.Reason,
+						})
+					}
+				}
+			}
+		}
+		return nil
+	})
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].String() < violations[j].String()
+	})
+	return violations, err
+}
+
+// under reports whether importPath is prefix or a package nested below it.
+func under(importPath, prefix string) bool {
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
+
+func modulePath(root string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("%s: no module directive", filepath.Join(root, "go.mod"))
+}
@@ -0,0 +1,69 @@
+package archtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// layerRules are the boundaries of this service. Inner layers never depend
+// on outer ones: domain <- usecase <- adapter/repository <- app/cmd.
+var layerRules = []Rule{
+	{
+		Layer:  "cleanarch/internal/domain",
+		Forbid: []string{"cleanarch", "net/http", "database/sql"},
+		Reason: "domain must not depend on other layers or delivery/persistence packages",
+	},
+	{
+		Layer:  "cleanarch/internal/usecase",
+		Forbid: []string{"cleanarch/internal/adapter", "cleanarch/internal/app", "cleanarch/internal/repository", "net/http"},
+		Reason: "usecase must depend only on domain ports, not on delivery or persistence",
+	},
+	{
+		Layer:  "cleanarch/internal/repository",
+		Forbid: []string{"cleanarch/internal/adapter", "cleanarch/internal/app", "cleanarch/internal/usecase", "net/http"},
+		Reason: "repositories implement domain ports and must not know about use cases or HTTP",
+	},
+	{
+		Layer:  "cleanarch/internal/adapter",
+		Forbid: []string{"cleanarch/internal/repository", "cleanarch/internal/app"},
+		Reason: "adapters talk to use cases, never to repositories or the composition root",
+	},
+}
+
+func TestLayerBoundaries(t *testing.T) {
+	violations, err := Check(filepath.Join("..", ".."), layerRules)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	for _, v := range violations {
+		t.Error(v)
+	}
+}
+
+func TestCheck_ReportsViolations(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, src string) {
+		p := filepath.Join(root, name)
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example\n")
+	write("domain/user.go", "package domain\n\nimport _ \"example/adapter\"\n")
+	write("domain/user_test.go", "package domain\n\nimport _ \"example/adapter\"\n")
+	write("domain/sub/ok.go", "package sub\n\nimport _ \"example/domain\"\n")
+
+	violations, err := Check(root, []Rule{{Layer: "example/domain", Forbid: []string{"example"}, Reason: "no"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", violations)
+	}
+	v := violations[0]
+	if v.Package != "example/domain" || v.Import != "example/adapter" || v.Pos.Line != 3 {
+		t.Errorf("unexpected violation %s", v)
+	}
+}
@@ -0,0 +1,312 @@
+// Package config loads the server configuration from environment variables.
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/fieldcrypt"
+	"cleanarch/internal/metrics"
+	"cleanarch/internal/slo"
+)
+
+// Config is the effective server configuration.
+type Config struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	RepositoryBackend string
+	ReadOnly          bool
+	// Authorization restricts deletes, reads and writes across all users,
+	// changes to another user's record, assigning roles and the /admin/
+	// endpoints to admins, identified by the X-User-ID header an
+	// authenticating proxy sets.
+	Authorization bool
+
+	// MaxBodyBytes caps request bodies; larger ones are rejected with 413.
+	MaxBodyBytes int64
+
+	// StatusSocket serves process status on a Unix socket at this path when set.
+	StatusSocket string
+
+	// StatsDAddr enables pushing metrics to StatsD when set.
+	StatsDAddr          string
+	MetricsPushInterval time.Duration
+	MetricsPrefix       string
+	// LatencyBuckets and SizeBuckets override the histogram bucket bounds
+	// in the format read by metrics.ParseBuckets; empty keeps the defaults.
+	LatencyBuckets string
+	SizeBuckets    string
+
+	// SLOs declares service level objectives in the format read by slo.Parse.
+	SLOs      string
+	SLOWindow time.Duration
+
+	// ShedTargetLatency and ShedMaxInFlight enable load shedding when set.
+	ShedTargetLatency time.Duration
+	ShedMaxInFlight   int64
+
+	// CacheTTL enables the user cache when set; WarmupUsers pre-loads that
+	// many recently updated users into it before the service reports ready.
+	CacheTTL    time.Duration
+	WarmupUsers int
+
+	// BulkDeletePause is the pause between bulk delete batches.
+	BulkDeletePause time.Duration
+
+	// CursorSecret signs list cursors; instances behind one load balancer
+	// need the same secret. When empty each process uses a random key.
+	CursorSecret string
+	CursorTTL    time.Duration
+
+	// ListCacheTTL is how long identical user list queries share a result.
+	ListCacheTTL time.Duration
+
+	// ConsistencyInterval schedules the orphaned record check when set;
+	// ConsistencyRepair makes it remove the orphans it finds.
+	ConsistencyInterval time.Duration
+	ConsistencyRepair   bool
+
+	// SensitiveFields marks user fields as personal data in the format read
+	// by domain.ParseSensitiveFields: they are stored encrypted with
+	// FieldEncryptionKey, a base64 key of fieldcrypt.KeySize bytes, and
+	// masked in responses for callers without the pii:read scope.
+	SensitiveFields    string
+	FieldEncryptionKey string
+}
+
+// Default returns the configuration used when no variables are set.
+func Default() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownTimeout:   10 * time.Second,
+		RepositoryBackend: "memory",
+		MaxBodyBytes:      1 << 20,
+
+		MetricsPushInterval: 10 * time.Second,
+		MetricsPrefix:       "cleanarch",
+
+		SLOWindow: 24 * time.Hour,
+
+		BulkDeletePause: 100 * time.Millisecond,
+
+		CursorTTL: time.Hour,
+
+		ListCacheTTL: 250 * time.Millisecond,
+	}
+}
+
+// Load reads the configuration from the environment, falling back to Default.
+func Load() (Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (Config, error) {
+	c := Default()
+	if v, ok := lookup("HTTP_ADDR"); ok {
+		c.Addr = v
+	}
+	durations := []struct {
+		key string
+		dst *time.Duration
+	}{
+		{"HTTP_READ_TIMEOUT", &c.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", &c.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", &c.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", &c.ShutdownTimeout},
+		{"METRICS_PUSH_INTERVAL", &c.MetricsPushInterval},
+		{"SLO_WINDOW", &c.SLOWindow},
+		{"SHED_TARGET_LATENCY", &c.ShedTargetLatency},
+		{"CACHE_TTL", &c.CacheTTL},
+		{"BULK_DELETE_PAUSE", &c.BulkDeletePause},
+		{"CURSOR_TTL", &c.CursorTTL},
+		{"LIST_CACHE_TTL", &c.ListCacheTTL},
+		{"CONSISTENCY_INTERVAL", &c.ConsistencyInterval},
+	}
+	for _, d := range durations {
+		v, ok := lookup(d.key)
+		if !ok {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			return c, fmt.Errorf("%s: invalid duration %q", d.key, v)
+		}
+		*d.dst = parsed
+	}
+	if v, ok := lookup("REPOSITORY_BACKEND"); ok {
+		c.RepositoryBackend = v
+	}
+	if v, ok := lookup("CURSOR_SECRET"); ok {
+		c.CursorSecret = v
+	}
+	if v, ok := lookup("STATUS_SOCKET"); ok {
+		c.StatusSocket = v
+	}
+	if v, ok := lookup("STATSD_ADDR"); ok {
+		c.StatsDAddr = v
+	}
+	if v, ok := lookup("METRICS_PREFIX"); ok {
+		if err := metrics.ValidatePrefix(v); err != nil {
+			return c, fmt.Errorf("METRICS_PREFIX: %w", err)
+		}
+		c.MetricsPrefix = v
+	}
+	if v, ok := lookup("METRICS_LATENCY_BUCKETS"); ok {
+		if _, err := metrics.ParseBuckets(v); err != nil {
+			return c, fmt.Errorf("METRICS_LATENCY_BUCKETS: %w", err)
+		}
+		c.LatencyBuckets = v
+	}
+	if v, ok := lookup("METRICS_SIZE_BUCKETS"); ok {
+		if _, err := metrics.ParseBuckets(v); err != nil {
+			return c, fmt.Errorf("METRICS_SIZE_BUCKETS: %w", err)
+		}
+		c.SizeBuckets = v
+	}
+	if v, ok := lookup("SLOS"); ok {
+		if _, err := slo.Parse(v); err != nil {
+			return c, fmt.Errorf("SLOS: %w", err)
+		}
+		c.SLOs = v
+	}
+	if v, ok := lookup("SHED_MAX_IN_FLIGHT"); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("SHED_MAX_IN_FLIGHT: invalid count %q", v)
+		}
+		c.ShedMaxInFlight = n
+	}
+	if v, ok := lookup("MAX_BODY_BYTES"); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return c, fmt.Errorf("MAX_BODY_BYTES: invalid size %q", v)
+		}
+		c.MaxBodyBytes = n
+	}
+	if v, ok := lookup("WARMUP_USERS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("WARMUP_USERS: invalid count %q", v)
+		}
+		c.WarmupUsers = n
+	}
+	if c.WarmupUsers > 0 && c.CacheTTL == 0 {
+		return c, fmt.Errorf("WARMUP_USERS: requires CACHE_TTL to be set")
+	}
+	if v, ok := lookup("READ_ONLY"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("READ_ONLY: invalid boolean %q", v)
+		}
+		c.ReadOnly = b
+	}
+	if v, ok := lookup("AUTHORIZATION"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("AUTHORIZATION: invalid boolean %q", v)
+		}
+		c.Authorization = b
+	}
+	if v, ok := lookup("CONSISTENCY_REPAIR"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("CONSISTENCY_REPAIR: invalid boolean %q", v)
+		}
+		c.ConsistencyRepair = b
+	}
+	if v, ok := lookup("SENSITIVE_FIELDS"); ok {
+		if _, err := domain.ParseSensitiveFields(v); err != nil {
+			return c, fmt.Errorf("SENSITIVE_FIELDS: %w", err)
+		}
+		c.SensitiveFields = v
+	}
+	if v, ok := lookup("FIELD_ENCRYPTION_KEY"); ok {
+		if _, err := fieldcrypt.ParseKey(v); err != nil {
+			return c, fmt.Errorf("FIELD_ENCRYPTION_KEY: %w", err)
+		}
+		c.FieldEncryptionKey = v
+	}
+	if strings.TrimSpace(c.SensitiveFields) != "" && c.FieldEncryptionKey == "" {
+		return c, fmt.Errorf("SENSITIVE_FIELDS: requires FIELD_ENCRYPTION_KEY to be set")
+	}
+	if c.RepositoryBackend != "memory" {
+		return c, fmt.Errorf("REPOSITORY_BACKEND: unsupported backend %q", c.RepositoryBackend)
+	}
+	return c, nil
+}
+
+// Dump returns the effective configuration keyed by environment variable,
+// with secret values redacted, for logs and the admin config endpoint.
+func (c Config) Dump() map[string]string {
+	return redact(map[string]string{
+		"HTTP_ADDR":          c.Addr,
+		"HTTP_READ_TIMEOUT":  c.ReadTimeout.String(),
+		"HTTP_WRITE_TIMEOUT": c.WriteTimeout.String(),
+		"HTTP_IDLE_TIMEOUT":  c.IdleTimeout.String(),
+		"SHUTDOWN_TIMEOUT":   c.ShutdownTimeout.String(),
+		"REPOSITORY_BACKEND": c.RepositoryBackend,
+		"READ_ONLY":          strconv.FormatBool(c.ReadOnly),
+		"AUTHORIZATION":      strconv.FormatBool(c.Authorization),
+		"MAX_BODY_BYTES":     strconv.FormatInt(c.MaxBodyBytes, 10),
+
+		"STATUS_SOCKET":           c.StatusSocket,
+		"STATSD_ADDR":             c.StatsDAddr,
+		"METRICS_PUSH_INTERVAL":   c.MetricsPushInterval.String(),
+		"METRICS_PREFIX":          c.MetricsPrefix,
+		"METRICS_LATENCY_BUCKETS": c.LatencyBuckets,
+		"METRICS_SIZE_BUCKETS":    c.SizeBuckets,
+		"SLOS":                    c.SLOs,
+		"SLO_WINDOW":              c.SLOWindow.String(),
+		"SHED_TARGET_LATENCY":     c.ShedTargetLatency.String(),
+		"SHED_MAX_IN_FLIGHT":      strconv.FormatInt(c.ShedMaxInFlight, 10),
+		"CACHE_TTL":               c.CacheTTL.String(),
+		"WARMUP_USERS":            strconv.Itoa(c.WarmupUsers),
+		"BULK_DELETE_PAUSE":       c.BulkDeletePause.String(),
+		"CURSOR_SECRET":           c.CursorSecret,
+		"CURSOR_TTL":              c.CursorTTL.String(),
+		"LIST_CACHE_TTL":          c.ListCacheTTL.String(),
+		"CONSISTENCY_INTERVAL":    c.ConsistencyInterval.String(),
+		"CONSISTENCY_REPAIR":      strconv.FormatBool(c.ConsistencyRepair),
+		"SENSITIVE_FIELDS":        c.SensitiveFields,
+		"FIELD_ENCRYPTION_KEY":    c.FieldEncryptionKey,
+	})
+}
+
+// Keys returns the dump's keys in sorted order.
+func Keys(dump map[string]string) []string {
+	keys := make([]string, 0, len(dump))
+	for k := range dump {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const redacted = "[REDACTED]"
+
+var secretSuffixes = []string{"_SECRET", "_PASSWORD", "_TOKEN", "_KEY", "_DSN"}
+
+func redact(dump map[string]string) map[string]string {
+	for k, v := range dump {
+		if v == "" {
+			continue
+		}
+		for _, suffix := range secretSuffixes {
+			if strings.HasSuffix(k, suffix) {
+				dump[k] = redacted
+			}
+		}
+	}
+	return dump
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults when unset", func(t *testing.T) {
+		c, err := load(env(nil))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c != Default() {
+			t.Errorf("expected defaults, got %+v", c)
+		}
+	})
+
+	t.Run("Overrides from environment", func(t *testing.T) {
+		c, err := load(env(map[string]string{
+			"HTTP_ADDR":         ":9090",
+			"HTTP_READ_TIMEOUT": "3s",
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.Addr != ":9090" {
+			t.Errorf("expected addr ':9090', got %s", c.Addr)
+		}
+		if c.ReadTimeout != 3*time.Second {
+			t.Errorf("expected read timeout 3s, got %s", c.ReadTimeout)
+		}
+	})
+
+	t.Run("Metrics push settings", func(t *testing.T) {
+		c, err := load(env(map[string]string{
+			"STATSD_ADDR":           "127.0.0.1:8125",
+			"METRICS_PUSH_INTERVAL": "30s",
+			"METRICS_PREFIX":        "users",
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.StatsDAddr != "127.0.0.1:8125" || c.MetricsPushInterval != 30*time.Second || c.MetricsPrefix != "users" {
+			t.Errorf("unexpected metrics push settings %+v", c)
+		}
+	})
+
+	t.Run("Histogram buckets", func(t *testing.T) {
+		c, err := load(env(map[string]string{
+			"METRICS_LATENCY_BUCKETS": "0.01,0.1,1",
+			"METRICS_SIZE_BUCKETS":    "1024,65536",
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.LatencyBuckets != "0.01,0.1,1" || c.SizeBuckets != "1024,65536" {
+			t.Errorf("unexpected bucket settings %+v", c)
+		}
+		if _, err := load(env(map[string]string{"METRICS_LATENCY_BUCKETS": "1,0.1"})); err == nil {
+			t.Error("expected error for decreasing buckets")
+		}
+		if _, err := load(env(map[string]string{"METRICS_PREFIX": "users-api"})); err == nil {
+			t.Error("expected error for invalid prefix")
+		}
+	})
+
+	t.Run("Service level objectives", func(t *testing.T) {
+		c, err := load(env(map[string]string{"SLOS": "reads=GET:200ms:99.9", "SLO_WINDOW": "1h"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.SLOs != "reads=GET:200ms:99.9" || c.SLOWindow != time.Hour {
+			t.Errorf("unexpected SLO settings %+v", c)
+		}
+		if _, err := load(env(map[string]string{"SLOS": "reads=GET"})); err == nil {
+			t.Error("expected error for malformed SLOS")
+		}
+	})
+
+	t.Run("Body size limit", func(t *testing.T) {
+		c, err := load(env(map[string]string{"MAX_BODY_BYTES": "4096"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.MaxBodyBytes != 4096 {
+			t.Errorf("expected max body bytes 4096, got %d", c.MaxBodyBytes)
+		}
+		if _, err := load(env(map[string]string{"MAX_BODY_BYTES": "0"})); err == nil {
+			t.Error("expected error for zero body size")
+		}
+	})
+
+	t.Run("Load shedding", func(t *testing.T) {
+		c, err := load(env(map[string]string{"SHED_TARGET_LATENCY": "50ms", "SHED_MAX_IN_FLIGHT": "200"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.ShedTargetLatency != 50*time.Millisecond || c.ShedMaxInFlight != 200 {
+			t.Errorf("unexpected shedding settings %+v", c)
+		}
+		if _, err := load(env(map[string]string{"SHED_MAX_IN_FLIGHT": "-1"})); err == nil {
+			t.Error("expected error for negative in-flight limit")
+		}
+	})
+
+	t.Run("Cache warm-up", func(t *testing.T) {
+		c, err := load(env(map[string]string{"CACHE_TTL": "5m", "WARMUP_USERS": "100"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.CacheTTL != 5*time.Minute || c.WarmupUsers != 100 {
+			t.Errorf("unexpected cache settings %+v", c)
+		}
+		if _, err := load(env(map[string]string{"WARMUP_USERS": "100"})); err == nil {
+			t.Error("expected error for warm-up without a cache")
+		}
+	})
+
+	t.Run("Bulk delete pause", func(t *testing.T) {
+		c, err := load(env(map[string]string{"BULK_DELETE_PAUSE": "2s"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.BulkDeletePause != 2*time.Second {
+			t.Errorf("expected pause 2s, got %s", c.BulkDeletePause)
+		}
+	})
+
+	t.Run("Invalid duration", func(t *testing.T) {
+		if _, err := load(env(map[string]string{"HTTP_IDLE_TIMEOUT": "soon"})); err == nil {
+			t.Error("expected error for invalid duration")
+		}
+	})
+
+	t.Run("Read-only flag", func(t *testing.T) {
+		c, err := load(env(map[string]string{"READ_ONLY": "true"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !c.ReadOnly {
+			t.Error("expected read-only to be enabled")
+		}
+		if _, err := load(env(map[string]string{"READ_ONLY": "maybe"})); err == nil {
+			t.Error("expected error for invalid boolean")
+		}
+	})
+
+	t.Run("Authorization flag", func(t *testing.T) {
+		c, err := load(env(map[string]string{"AUTHORIZATION": "true"}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !c.Authorization {
+			t.Error("expected authorization to be enabled")
+		}
+		if _, err := load(env(map[string]string{"AUTHORIZATION": "maybe"})); err == nil {
+			t.Error("expected error for invalid boolean")
+		}
+	})
+
+	t.Run("Sensitive fields", func(t *testing.T) {
+		key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+		c, err := load(env(map[string]string{"SENSITIVE_FIELDS": "email,metadata.ssn", "FIELD_ENCRYPTION_KEY": key}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.SensitiveFields != "email,metadata.ssn" || c.Dump()["FIELD_ENCRYPTION_KEY"] != redacted {
+			t.Errorf("unexpected sensitive field settings %+v", c)
+		}
+		for _, vars := range []map[string]string{
+			{"SENSITIVE_FIELDS": "email"},
+			{"SENSITIVE_FIELDS": "phone", "FIELD_ENCRYPTION_KEY": key},
+			{"SENSITIVE_FIELDS": "email", "FIELD_ENCRYPTION_KEY": "short"},
+		} {
+			if _, err := load(env(vars)); err == nil {
+				t.Errorf("expected error for %v", vars)
+			}
+		}
+	})
+
+	t.Run("Unsupported backend", func(t *testing.T) {
+		if _, err := load(env(map[string]string{"REPOSITORY_BACKEND": "oracle"})); err == nil {
+			t.Error("expected error for unsupported backend")
+		}
+	})
+}
+
+func TestDump(t *testing.T) {
+	t.Run("Dump lists effective values", func(t *testing.T) {
+		dump := Default().Dump()
+		if dump["HTTP_ADDR"] != ":8080" {
+			t.Errorf("expected HTTP_ADDR ':8080', got %s", dump["HTTP_ADDR"])
+		}
+		if dump["HTTP_WRITE_TIMEOUT"] != "10s" {
+			t.Errorf("expected HTTP_WRITE_TIMEOUT '10s', got %s", dump["HTTP_WRITE_TIMEOUT"])
+		}
+	})
+
+	t.Run("Secrets are redacted", func(t *testing.T) {
+		dump := redact(map[string]string{"DATABASE_DSN": "postgres://u:p@db", "EMPTY_TOKEN": "", "HTTP_ADDR": ":80"})
+		if dump["DATABASE_DSN"] != redacted {
+			t.Errorf("expected DATABASE_DSN redacted, got %s", dump["DATABASE_DSN"])
+		}
+		if dump["EMPTY_TOKEN"] != "" {
+			t.Errorf("expected empty secret to stay empty, got %s", dump["EMPTY_TOKEN"])
+		}
+		if dump["HTTP_ADDR"] != ":80" {
+			t.Errorf("expected HTTP_ADDR unchanged, got %s", dump["HTTP_ADDR"])
+		}
+	})
+}
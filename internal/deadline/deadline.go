@@ -0,0 +1,39 @@
+// Package deadline splits the time left on a request among the calls made
+// on its behalf, so downstream work never outlives the client waiting for
+// it.
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+// Remaining returns the time left before ctx's deadline, and false if ctx
+// has none.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
+
+// Share returns a context for one downstream call that may use fraction
+// (between 0 and 1) of the time left before ctx's deadline. Calls made in
+// turn each leave time for the ones after them and for the caller. Without
+// a deadline, ctx is returned as is.
+func Share(ctx context.Context, fraction float64) (context.Context, context.CancelFunc) {
+	left, ok := Remaining(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(float64(left)*fraction))
+}
+
+// Covers reports whether ctx has at least d left before its deadline. A
+// context without a deadline covers any d. Callers check it before waiting,
+// such as before a retry backoff, rather than start work that can't finish.
+func Covers(ctx context.Context, d time.Duration) bool {
+	left, ok := Remaining(ctx)
+	return !ok || left >= d
+}
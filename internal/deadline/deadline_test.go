@@ -0,0 +1,44 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShare(t *testing.T) {
+	t.Run("Takes a fraction of the time left", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ctx, cancel := Share(parent, 0.5)
+		defer cancel()
+
+		left, ok := Remaining(ctx)
+		if !ok || left > 500*time.Millisecond || left < 400*time.Millisecond {
+			t.Errorf("expected about 500ms left, got %s", left)
+		}
+	})
+
+	t.Run("Without a deadline the context is unchanged", func(t *testing.T) {
+		parent := context.Background()
+		ctx, cancel := Share(parent, 0.5)
+		defer cancel()
+		if ctx != parent {
+			t.Error("expected the parent context")
+		}
+	})
+}
+
+func TestCovers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !Covers(ctx, 100*time.Millisecond) {
+		t.Error("expected 1s to cover 100ms")
+	}
+	if Covers(ctx, 2*time.Second) {
+		t.Error("expected 1s not to cover 2s")
+	}
+	if !Covers(context.Background(), time.Hour) {
+		t.Error("expected a context without a deadline to cover any duration")
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditAction names what an audited change did.
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+	// AuditAnonymize entries have no Before, and anonymizing redacts the
+	// user's earlier entries, so the log doesn't keep a copy of the data
+	// that was removed.
+	AuditAnonymize AuditAction = "anonymize"
+)
+
+// AuditEntry records one stored change to a user and who made it. Before
+// is nil for a create and After for a delete.
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	Principal
+	Action   AuditAction `json:"action"`
+	EntityID int64       `json:"entity_id"`
+	Before   *User       `json:"before,omitempty"`
+	After    *User       `json:"after,omitempty"`
+	At       time.Time   `json:"at"`
+}
+
+// AuditQuery selects audit entries, newest first. A zero EntityID matches
+// every entry; Cursor resumes a listing after the entry it was built from.
+type AuditQuery struct {
+	EntityID int64
+	Cursor   string
+	PageRequest
+}
+
+// Validate checks the page request and cursor.
+func (q AuditQuery) Validate() error {
+	if err := q.PageRequest.Validate(); err != nil {
+		return err
+	}
+	if q.Cursor != "" {
+		if _, err := q.DecodeCursor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AuditCursor builds the cursor resuming a listing after e.
+func AuditCursor(e *AuditEntry) string {
+	return strconv.FormatInt(e.ID, 10)
+}
+
+// DecodeCursor returns the ID of the entry the listing resumes after.
+func (q AuditQuery) DecodeCursor() (int64, error) {
+	id, err := strconv.ParseInt(q.Cursor, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	return id, nil
+}
+
+// AuditRepository is an append-only store of audit entries. Entries are
+// never removed, but Redact may rewrite the snapshots they hold.
+type AuditRepository interface {
+	// Append stores e, assigning its ID.
+	Append(ctx context.Context, e *AuditEntry) (*AuditEntry, error)
+	List(ctx context.Context, q AuditQuery) (*Page[AuditEntry], error)
+	// Redact applies fn to the Before and After snapshots of every entry
+	// about entityID, so erased data doesn't survive in the log.
+	Redact(ctx context.Context, entityID int64, fn func(*User)) error
+}
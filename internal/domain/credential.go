@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Password length limits, in characters.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+// Credential is a user's stored password. Each user has at most one, keyed
+// by UserID. Hash is the encoded password hash and is never serialized.
+type Credential struct {
+	UserID    int64     `json:"user_id"`
+	Hash      string    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ValidatePassword checks a new password's length, returning a
+// *ValidationError for the "password" field.
+func ValidatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < MinPasswordLength || n > MaxPasswordLength {
+		return Invalid("password", fmt.Sprintf("must be %d to %d characters", MinPasswordLength, MaxPasswordLength))
+	}
+	return nil
+}
+
+// CredentialRepository persists credentials by user ID.
+type CredentialRepository interface {
+	Repository[Credential, int64]
+	// Put creates or replaces the user's credential.
+	Put(ctx context.Context, credential *Credential) (*Credential, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCredential(t *testing.T) {
+	t.Run("Hash is never serialized", func(t *testing.T) {
+		b, err := json.Marshal(Credential{UserID: 1, Hash: "$pbkdf2-sha256$i=1$c2FsdA$a2V5"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if strings.Contains(string(b), "pbkdf2") || strings.Contains(string(b), "hash") {
+			t.Errorf("expected no hash in %s", b)
+		}
+	})
+
+	t.Run("Password length", func(t *testing.T) {
+		for _, p := range []string{"", "1234567", strings.Repeat("x", MaxPasswordLength+1)} {
+			if err := ValidatePassword(p); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput for a %d character password, got %v", len(p), err)
+			}
+		}
+		if err := ValidatePassword("correct horse"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which writes are checked but not
+// kept. Repositories honor it where they would store a change: they make
+// every check the write makes, such as uniqueness and versions, and return
+// the result it would have without storing it. Use cases skip the side
+// effects of a stored change, such as events and post-write hooks.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx is a dry run.
+func IsDryRun(ctx context.Context) bool {
+	dry, _ := ctx.Value(dryRunKey{}).(bool)
+	return dry
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "errors"
+
+// Sentinel errors returned (possibly wrapped) by repositories and use cases.
+// Callers should test for them with errors.Is.
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrViewNotFound    = errors.New("view not found")
+	ErrOrgNotFound     = errors.New("organization not found")
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrMemberNotFound  = errors.New("user is not a member")
+	ErrAlreadyMember   = errors.New("user is already a member")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrDuplicateEmail  = errors.New("email already in use")
+	// ErrDuplicateExternalID is returned when an external ID is already
+	// linked to another user.
+	ErrDuplicateExternalID = errors.New("external ID already linked")
+	// ErrInvalidTransition is returned (wrapped) for a disallowed status change.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrVersionConflict is returned when a write names a version that is no
+	// longer current.
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrCursorExpired is returned for a pagination cursor that was valid
+	// but is too old to resume from; the client should restart the listing.
+	ErrCursorExpired = errors.New("cursor expired")
+	// ErrCredentialNotFound is returned for a user without a password.
+	ErrCredentialNotFound = errors.New("credential not found")
+	// ErrInvalidCredentials is returned when a password doesn't match or
+	// the user has none.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrForbidden is returned when the request's principal may not make
+	// the change.
+	ErrForbidden = errors.New("forbidden")
+	// ErrUnavailable is wrapped by repositories around failures that may
+	// go away on retry, such as a dropped connection to the backend.
+	ErrUnavailable = errors.New("temporarily unavailable")
+)
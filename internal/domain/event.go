@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EventType names a change to a user.
+type EventType string
+
+const (
+	UserCreated EventType = "user.created"
+	UserUpdated EventType = "user.updated"
+	// UserDeleted events carry only the deleted user's ID and version.
+	UserDeleted EventType = "user.deleted"
+	// UserAnonymized events carry the user with its personal data replaced.
+	UserAnonymized EventType = "user.anonymized"
+)
+
+// Event records a mutation after it was stored, and who made it.
+type Event struct {
+	Type EventType `json:"type"`
+	User User      `json:"user"`
+	At   time.Time `json:"at"`
+	By   Principal `json:"by"`
+}
+
+// EventHandler reacts to a published event.
+type EventHandler func(ctx context.Context, e Event)
+
+// EventBus delivers events to subscribers.
+type EventBus interface {
+	Publish(ctx context.Context, e Event)
+	// Subscribe registers h for the given types, or for every type if none
+	// are given.
+	Subscribe(h EventHandler, types ...EventType)
+}
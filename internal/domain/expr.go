@@ -0,0 +1,400 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Limits bounding the size of expressions accepted by ParseExpr. Evaluation
+// visits every node at most once, so these also bound its cost.
+const (
+	MaxExprLength      = 1024
+	MaxExprDepth       = 16
+	MaxExprComparisons = 32
+)
+
+// ErrInvalidExpression is returned (wrapped) when an expression fails to parse.
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// Expr is a boolean expression over user attributes such as
+//
+//	created_at > "2024-01-01" AND (email endsWith "@corp.com" OR NOT name = "root")
+//
+// Backends that can't call Match walk the exported node types (And, Or, Not,
+// Comparison) to translate the expression into their own query language.
+type Expr interface {
+	Match(u *User) bool
+	String() string
+}
+
+// Operators accepted in comparisons.
+const (
+	OpEq         = "="
+	OpNe         = "!="
+	OpGt         = ">"
+	OpGe         = ">="
+	OpLt         = "<"
+	OpLe         = "<="
+	OpContains   = "contains"
+	OpStartsWith = "startsWith"
+	OpEndsWith   = "endsWith"
+)
+
+// And matches when both sides match.
+type And struct{ Left, Right Expr }
+
+// Or matches when either side matches.
+type Or struct{ Left, Right Expr }
+
+// Not matches when X does not.
+type Not struct{ X Expr }
+
+// Comparison compares a user field with a literal. Value holds the literal
+// as written; Num and Time hold its parsed form for id and time fields.
+type Comparison struct {
+	Field string
+	Op    string
+	Value string
+	Num   int64
+	Time  time.Time
+}
+
+func (e And) Match(u *User) bool { return e.Left.Match(u) && e.Right.Match(u) }
+func (e Or) Match(u *User) bool  { return e.Left.Match(u) || e.Right.Match(u) }
+func (e Not) Match(u *User) bool { return !e.X.Match(u) }
+
+func (e And) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e Or) String() string  { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e Not) String() string { return "NOT " + e.X.String() }
+
+func (c Comparison) String() string {
+	if c.Field == "id" {
+		return c.Field + " " + c.Op + " " + c.Value
+	}
+	return c.Field + " " + c.Op + " " + quoteLiteral(c.Value)
+}
+
+// quoteLiteral quotes s using only the escapes scanString understands.
+func quoteLiteral(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (c Comparison) Match(u *User) bool {
+	switch c.Field {
+	case "id":
+		return compareOrdered(c.Op, u.ID, c.Num)
+	case "created_at":
+		return compareTime(c.Op, u.CreatedAt, c.Time)
+	case "updated_at":
+		return compareTime(c.Op, u.UpdatedAt, c.Time)
+	case "name":
+		return compareString(c.Op, u.Name, c.Value)
+	case "email":
+		return compareString(c.Op, u.Email, c.Value)
+	case "status":
+		return compareString(c.Op, string(u.Status), c.Value)
+	}
+	return false
+}
+
+func compareOrdered[T int64 | string](op string, a, b T) bool {
+	switch op {
+	case OpEq:
+		return a == b
+	case OpNe:
+		return a != b
+	case OpGt:
+		return a > b
+	case OpGe:
+		return a >= b
+	case OpLt:
+		return a < b
+	case OpLe:
+		return a <= b
+	}
+	return false
+}
+
+func compareTime(op string, a, b time.Time) bool {
+	return compareOrdered(op, int64(a.Compare(b)), 0)
+}
+
+func compareString(op, a, b string) bool {
+	switch op {
+	case OpContains:
+		return strings.Contains(a, b)
+	case OpStartsWith:
+		return strings.HasPrefix(a, b)
+	case OpEndsWith:
+		return strings.HasSuffix(a, b)
+	}
+	return compareOrdered(op, a, b)
+}
+
+// fieldKinds lists the fields expressions may reference and their kind.
+var fieldKinds = map[string]string{
+	"id":         "number",
+	"name":       "string",
+	"email":      "string",
+	"status":     "string",
+	"created_at": "time",
+	"updated_at": "time",
+}
+
+// ParseExpr parses src using the grammar
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = field op literal
+//
+// Keywords are case-insensitive, string literals are double-quoted, and time
+// fields accept RFC 3339 timestamps or dates such as "2024-01-01" (UTC).
+func ParseExpr(src string) (Expr, error) {
+	if len(src) > MaxExprLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidExpression, MaxExprLength)
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	e, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '"':
+			s, n, err := scanString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v at offset %d", ErrInvalidExpression, err, i)
+			}
+			tokens = append(tokens, token{tokString, s, i})
+			i += n
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			n := 1
+			if i+1 < len(src) && src[i+1] == '=' {
+				n = 2
+			}
+			op := src[i : i+n]
+			if op == "!" {
+				return nil, fmt.Errorf("%w: unexpected \"!\" at offset %d", ErrInvalidExpression, i)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += n
+		case c >= '0' && c <= '9' || c == '-':
+			j := i + 1
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, src[i:j], i})
+			i = j
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidExpression, c, i)
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(src)}), nil
+}
+
+// scanString reads a double-quoted literal supporting \" and \\ escapes and
+// returns its value and the number of bytes consumed.
+func scanString(src string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(src) || (src[i+1] != '"' && src[i+1] != '\\') {
+				return "", 0, errors.New("invalid escape")
+			}
+			i++
+		}
+		b.WriteByte(src[i])
+	}
+	return "", 0, errors.New("unterminated string")
+}
+
+type exprParser struct {
+	tokens      []token
+	pos         int
+	comparisons int
+}
+
+func (p *exprParser) peek() token { return p.tokens[p.pos] }
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidExpression, fmt.Sprintf(format, args...), t.pos)
+}
+
+func (p *exprParser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary(depth int) (Expr, error) {
+	if depth >= MaxExprDepth {
+		return nil, p.errorf(p.peek(), "nested deeper than %d", MaxExprDepth)
+	}
+	if p.keyword("NOT") {
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{x}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, p.errorf(t, "expected \")\"")
+		}
+		return e, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (Expr, error) {
+	field := p.next()
+	kind, ok := fieldKinds[field.text]
+	if field.kind != tokIdent || !ok {
+		return nil, p.errorf(field, "expected field name, got %q", field.text)
+	}
+	p.comparisons++
+	if p.comparisons > MaxExprComparisons {
+		return nil, p.errorf(field, "more than %d comparisons", MaxExprComparisons)
+	}
+
+	opTok := p.next()
+	op := opTok.text
+	switch {
+	case opTok.kind == tokOp:
+	case opTok.kind == tokIdent && (op == OpContains || op == OpStartsWith || op == OpEndsWith):
+		if kind != "string" {
+			return nil, p.errorf(opTok, "%s does not apply to %s", op, field.text)
+		}
+	default:
+		return nil, p.errorf(opTok, "expected operator, got %q", op)
+	}
+
+	lit := p.next()
+	c := Comparison{Field: field.text, Op: op, Value: lit.text}
+	switch kind {
+	case "number":
+		n, err := strconv.ParseInt(lit.text, 10, 64)
+		if lit.kind != tokNumber || err != nil {
+			return nil, p.errorf(lit, "%s expects a number", field.text)
+		}
+		c.Num = n
+	case "time":
+		t, err := parseExprTime(lit.text)
+		if lit.kind != tokString || err != nil {
+			return nil, p.errorf(lit, "%s expects a quoted date or RFC 3339 time", field.text)
+		}
+		c.Time = t
+	default:
+		if lit.kind != tokString {
+			return nil, p.errorf(lit, "%s expects a quoted string", field.text)
+		}
+	}
+	return c, nil
+}
+
+func parseExprTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExpr(t *testing.T) {
+	alice := &User{ID: 1, Name: "Alice", Email: "alice@corp.com", CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	bob := &User{ID: 2, Name: "Bob", Email: "bob@example.com", CreatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	cases := []struct {
+		src        string
+		alice, bob bool
+	}{
+		{`created_at > "2024-01-01" AND email endsWith "@corp.com"`, true, false},
+		{`name = "Bob" or id = 1`, true, true},
+		{`NOT (name startsWith "A")`, false, true},
+		{`id >= 2`, false, true},
+		{`email contains "example" OR created_at <= "2023-06-01T00:00:00Z"`, false, true},
+		{`name != "Alice" AND NOT id < 0`, false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			e, err := ParseExpr(tc.src)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := e.Match(alice); got != tc.alice {
+				t.Errorf("expected alice match %v, got %v", tc.alice, got)
+			}
+			if got := e.Match(bob); got != tc.bob {
+				t.Errorf("expected bob match %v, got %v", tc.bob, got)
+			}
+		})
+	}
+}
+
+func TestParseExpr_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":         `password = "x"`,
+		"missing operator":      `name "x"`,
+		"string op on number":   `id contains 1`,
+		"number for string":     `name = 1`,
+		"bad time":              `created_at > "yesterday"`,
+		"unterminated string":   `name = "x`,
+		"unbalanced parens":     `(name = "x"`,
+		"trailing tokens":       `name = "x" name = "y"`,
+		"dangling keyword":      `name = "x" AND`,
+		"too deep":              strings.Repeat("NOT ", MaxExprDepth+1) + `id = 1`,
+		"too many comparisons":  strings.Repeat(`id = 1 OR `, MaxExprComparisons) + `id = 1`,
+		"too long":              `name = "` + strings.Repeat("x", MaxExprLength) + `"`,
+		"unsupported character": `name = "x" & id = 1`,
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseExpr(src)
+			if !errors.Is(err, ErrInvalidExpression) {
+				t.Errorf("expected ErrInvalidExpression, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExpr_String(t *testing.T) {
+	e, err := ParseExpr(`NOT name = "a\"b" and id > 3`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := `(NOT name = "a\"b" AND id > 3)`
+	if got := e.String(); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if _, err := ParseExpr(e.String()); err != nil {
+		t.Errorf("expected String output to parse, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxListLimit caps how many users a single listing may return.
+const MaxListLimit = 1000
+
+// ErrInvalidFilter is returned (wrapped) when a Filter fails validation.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// SortField names a user attribute listings can be ordered by.
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByName      SortField = "name"
+	SortByEmail     SortField = "email"
+	SortByCreatedAt SortField = "created_at"
+)
+
+func (f SortField) valid() bool {
+	switch f {
+	case SortByID, SortByName, SortByEmail, SortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// SortSpec orders a listing by Field, ascending unless Descending. Ties
+// are broken by ID in the same direction.
+type SortSpec struct {
+	Field      SortField
+	Descending bool
+}
+
+// ParseSortSpec parses "field" or "-field" for descending order. The empty
+// string is the default order. Fields are checked by Validate.
+func ParseSortSpec(s string) SortSpec {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return SortSpec{Field: SortField(rest), Descending: true}
+	}
+	return SortSpec{Field: SortField(s)}
+}
+
+// String is the form ParseSortSpec reads.
+func (s SortSpec) String() string {
+	if s.Descending {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+// Validate rejects unknown sort fields.
+func (s SortSpec) Validate() error {
+	if s.Field != "" && !s.Field.valid() {
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidFilter, s.Field)
+	}
+	return nil
+}
+
+// UserFilter holds the constraints on user attributes. CreatedAfter and
+// CreatedBefore are exclusive bounds; Tag selects users carrying the
+// normalized tag. OrgID selects the organization's members; use cases
+// resolve it into Filter.IDs, since users don't record their memberships.
+type UserFilter struct {
+	NameContains  string
+	EmailEq       string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Tag           string
+	OrgID         int64
+}
+
+// Validate checks that the creation bounds leave a range.
+func (f UserFilter) Validate() error {
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		return fmt.Errorf("%w: created_after must be before created_before", ErrInvalidFilter)
+	}
+	if f.OrgID < 0 {
+		return fmt.Errorf("%w: org must be an organization ID", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Filter is the typed query shared by all UserRepository implementations.
+// Each backend translates it into its own query language; zero values mean
+// "no constraint". Users are ordered by Sort (ascending ID when empty), and
+// Cursor resumes a listing right after the user it was built from.
+type Filter struct {
+	UserFilter
+	Status UserStatus
+	Sort   SortSpec
+	Cursor string
+	PageRequest
+	// Expr further restricts the listing; see ParseExpr.
+	Expr Expr
+	// IDs restricts the listing to these users when non-nil; an empty
+	// slice selects none. Repositories apply it and ignore OrgID.
+	IDs []int64
+}
+
+// Validate checks the filter for values no backend can honor.
+func (f Filter) Validate() error {
+	if err := f.PageRequest.Validate(); err != nil {
+		return err
+	}
+	if err := f.UserFilter.Validate(); err != nil {
+		return err
+	}
+	if f.Status != "" && !f.Status.Valid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, f.Status)
+	}
+	if err := f.Sort.Validate(); err != nil {
+		return err
+	}
+	if f.Cursor != "" {
+		if _, err := f.DecodeCursor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match reports whether u satisfies the predicate part of the filter:
+// NameContains, EmailEq, Tag, Status, the CreatedAt bounds and Expr.
+// Backends with a query language translate these instead; IDs and OrgID
+// select candidates and are not checked here.
+func (f Filter) Match(u *User) bool {
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(u.Name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	if f.EmailEq != "" && !strings.EqualFold(u.Email, f.EmailEq) {
+		return false
+	}
+	if f.Tag != "" && !u.HasTag(f.Tag) {
+		return false
+	}
+	if f.Status != "" && u.Status != f.Status {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !u.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !u.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if f.Expr != nil && !f.Expr.Match(u) {
+		return false
+	}
+	return true
+}
+
+// EffectiveSort returns the sort order, defaulting the field to ID.
+func (f Filter) EffectiveSort() SortSpec {
+	if f.Sort.Field == "" {
+		return SortSpec{Field: SortByID, Descending: f.Sort.Descending}
+	}
+	return f.Sort
+}
+
+// Cursor is the decoded position a listing resumes after. Sort is the
+// listing's SortSpec in string form.
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"id"`
+}
+
+// SortKey returns the string form of the user's value for the given sort field.
+// Keys of the same field compare correctly as strings, except for IDs which
+// callers compare numerically.
+func SortKey(u *User, field SortField) string {
+	switch field {
+	case SortByName:
+		return u.Name
+	case SortByEmail:
+		return u.Email
+	case SortByCreatedAt:
+		return u.CreatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatInt(u.ID, 10)
+	}
+}
+
+// Compare orders a and b by the spec, breaking ties by ascending ID so
+// listings are stable across calls.
+func (s SortSpec) Compare(a, b *User) int {
+	if s.Descending {
+		a, b = b, a
+	}
+	var c int
+	switch s.Field {
+	case SortByName:
+		c = strings.Compare(a.Name, b.Name)
+	case SortByEmail:
+		c = strings.Compare(a.Email, b.Email)
+	case SortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	switch {
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
+
+// EncodeCursor builds an opaque cursor pointing just after u in a listing
+// ordered by sort.
+func EncodeCursor(u *User, sort SortSpec) string {
+	sort = Filter{Sort: sort}.EffectiveSort()
+	b, _ := json.Marshal(Cursor{Sort: sort.String(), Key: SortKey(u, sort.Field), ID: u.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses the filter's cursor and checks it matches the sort order.
+func (f Filter) DecodeCursor() (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(f.Cursor))
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	if c.Sort != f.EffectiveSort().String() {
+		return c, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidFilter)
+	}
+	return c, nil
+}
+
+// Position rebuilds the sort position the cursor refers to as a user value,
+// so listings can resume with SortSpec.Compare: users comparing at or
+// before it were on earlier pages.
+func (c Cursor) Position() *User {
+	u := &User{ID: c.ID}
+	switch ParseSortSpec(c.Sort).Field {
+	case SortByName:
+		u.Name = c.Key
+	case SortByEmail:
+		u.Email = c.Key
+	case SortByCreatedAt:
+		u.CreatedAt, _ = time.Parse(time.RFC3339Nano, c.Key)
+	}
+	return u
+}
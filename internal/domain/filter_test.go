@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFilter_Validate(t *testing.T) {
+	t.Run("Zero filter is valid", func(t *testing.T) {
+		if err := (Filter{}).Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Limit out of range", func(t *testing.T) {
+		for _, limit := range []int{-1, MaxListLimit + 1} {
+			err := Filter{PageRequest: PageRequest{Limit: limit}}.Validate()
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("expected ErrInvalidFilter for limit %d, got %v", limit, err)
+			}
+		}
+	})
+
+	t.Run("Negative offset", func(t *testing.T) {
+		err := Filter{PageRequest: PageRequest{Offset: -1}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Negative organization", func(t *testing.T) {
+		err := Filter{UserFilter: UserFilter{OrgID: -1}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Unknown sort field", func(t *testing.T) {
+		err := Filter{Sort: SortSpec{Field: "password"}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Empty creation range", func(t *testing.T) {
+		at := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		err := Filter{UserFilter: UserFilter{CreatedAfter: at, CreatedBefore: at}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Malformed cursor", func(t *testing.T) {
+		err := Filter{Cursor: "not-a-cursor!"}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Cursor from a different sort order", func(t *testing.T) {
+		cursor := EncodeCursor(&User{ID: 1, Name: "John Doe"}, SortSpec{Field: SortByName})
+		err := Filter{Cursor: cursor, Sort: SortSpec{Field: SortByEmail}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("Cursor from the opposite direction", func(t *testing.T) {
+		cursor := EncodeCursor(&User{ID: 1, Name: "John Doe"}, SortSpec{Field: SortByName})
+		err := Filter{Cursor: cursor, Sort: SortSpec{Field: SortByName, Descending: true}}.Validate()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
+
+func TestFilter_Cursor(t *testing.T) {
+	t.Run("Cursor round trip", func(t *testing.T) {
+		created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+		user := &User{ID: 42, CreatedAt: created}
+
+		c, err := Filter{Cursor: EncodeCursor(user, SortSpec{Field: SortByCreatedAt}), Sort: SortSpec{Field: SortByCreatedAt}}.DecodeCursor()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if c.ID != 42 {
+			t.Errorf("expected ID 42, got %d", c.ID)
+		}
+		if c.Key != created.Format(time.RFC3339Nano) {
+			t.Errorf("expected key %s, got %s", created.Format(time.RFC3339Nano), c.Key)
+		}
+	})
+
+	t.Run("Empty sort defaults to ID", func(t *testing.T) {
+		cursor := EncodeCursor(&User{ID: 7}, SortSpec{})
+		if err := (Filter{Cursor: cursor}).Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestParseSortSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SortSpec
+	}{
+		{"", SortSpec{}},
+		{"name", SortSpec{Field: SortByName}},
+		{"-created_at", SortSpec{Field: SortByCreatedAt, Descending: true}},
+	}
+	for _, tt := range tests {
+		got := ParseSortSpec(tt.in)
+		if got != tt.want {
+			t.Errorf("expected %+v for %q, got %+v", tt.want, tt.in, got)
+		}
+		if got.String() != tt.in {
+			t.Errorf("expected %q to round trip, got %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestSortSpec_Compare(t *testing.T) {
+	older := &User{ID: 2, Name: "Ada", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := &User{ID: 1, Name: "Ada", CreatedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		sort string
+		want int
+	}{
+		{"", 1},
+		{"-id", -1},
+		{"created_at", -1},
+		{"-created_at", 1},
+		{"name", 1},
+		{"-name", -1},
+	}
+	for _, tt := range tests {
+		if got := ParseSortSpec(tt.sort).Compare(older, newer); got != tt.want {
+			t.Errorf("expected %d for %q, got %d", tt.want, tt.sort, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+// Metadata limits, enforced by the validate tags on User.
+const (
+	MaxMetadataKeys  = 50
+	MaxMetadataBytes = 16 << 10
+)
+
+// MergeMetadata applies an RFC 7386 JSON merge patch to metadata and
+// returns the result; neither argument is modified. Keys set to null in
+// the patch are removed, objects are merged recursively and any other value
+// replaces the old one. A nil patch (the JSON null document) clears all
+// keys. The result is never nil, so it can replace a user's metadata.
+func MergeMetadata(metadata, patch map[string]any) map[string]any {
+	if patch == nil {
+		return map[string]any{}
+	}
+	out := CloneMetadata(metadata)
+	if out == nil {
+		out = make(map[string]any, len(patch))
+	}
+	for k, v := range patch {
+		out[k] = mergeValue(out[k], v)
+		if out[k] == nil {
+			delete(out, k)
+		}
+	}
+	return out
+}
+
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return cloneValue(patch)
+	}
+	t, _ := target.(map[string]any)
+	return MergeMetadata(t, p)
+}
+
+// CloneMetadata deep-copies metadata decoded from JSON.
+func CloneMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	out := make(map[string]any, len(metadata))
+	for k, v := range metadata {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return CloneMetadata(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	}
+	return v
+}
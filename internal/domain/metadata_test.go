@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	decode := func(s string) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatalf("invalid test JSON %s: %v", s, err)
+		}
+		return m
+	}
+
+	// Cases from RFC 7386, Appendix A, where target and patch are objects.
+	for _, tc := range []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		target := decode(tc.target)
+		got := MergeMetadata(target, decode(tc.patch))
+		if !reflect.DeepEqual(got, decode(tc.want)) {
+			t.Errorf("merging %s into %s: expected %s, got %v", tc.patch, tc.target, tc.want, got)
+		}
+		if !reflect.DeepEqual(target, decode(tc.target)) {
+			t.Errorf("merging %s modified the target to %v", tc.patch, target)
+		}
+	}
+
+	t.Run("Null patch clears", func(t *testing.T) {
+		if got := MergeMetadata(decode(`{"a":1}`), nil); got == nil || len(got) != 0 {
+			t.Errorf("expected an empty non-nil map, got %#v", got)
+		}
+	})
+}
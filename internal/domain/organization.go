@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Organization groups users. A user may belong to any number of
+// organizations; membership is stored with the organization, not the user.
+type Organization struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=200"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationRepository persists organizations and their memberships.
+type OrganizationRepository interface {
+	// Delete also removes the organization's memberships.
+	Repository[Organization, int64]
+	Create(ctx context.Context, org *Organization) (*Organization, error)
+
+	// AddMember returns ErrAlreadyMember if the user is already a member.
+	AddMember(ctx context.Context, orgID, userID int64) error
+	// RemoveMember returns ErrMemberNotFound if the user is not a member.
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	// Members returns the IDs of the organization's members in ascending order.
+	Members(ctx context.Context, orgID int64) ([]int64, error)
+	// Memberships returns the IDs of the organizations the user belongs to
+	// in ascending order.
+	Memberships(ctx context.Context, userID int64) ([]int64, error)
+	// RemoveUser drops the user from every organization.
+	RemoveUser(ctx context.Context, userID int64) error
+}
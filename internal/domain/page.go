@@ -0,0 +1,34 @@
+package domain
+
+import "fmt"
+
+// DefaultPageLimit is the page size listings use when a client doesn't ask
+// for one.
+const DefaultPageLimit = 100
+
+// PageRequest selects a window of a listing. A zero Limit means no limit;
+// Offset skips that many results, after any cursor.
+type PageRequest struct {
+	Limit  int
+	Offset int
+}
+
+// Validate checks the request for values no backend can honor.
+func (p PageRequest) Validate() error {
+	if p.Limit < 0 || p.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be between 0 and %d", ErrInvalidFilter, MaxListLimit)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Page is one window of a listing. Total counts every match of the query,
+// regardless of the window; NextCursor resumes the listing after Items and
+// is empty on the last page.
+type Page[T any] struct {
+	Items      []*T   `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ScopePIIRead lets a principal see sensitive fields unmasked.
+const ScopePIIRead = "pii:read"
+
+// MaskedValue replaces sensitive metadata values in responses.
+const MaskedValue = "***"
+
+// SensitiveFields names the user fields a deployment treats as personal
+// data: they are stored encrypted and masked in responses for principals
+// without ScopePIIRead. The zero value marks no fields.
+type SensitiveFields struct {
+	Email bool
+	// Metadata lists the sensitive metadata keys, sorted.
+	Metadata []string
+}
+
+// ParseSensitiveFields parses a comma-separated list such as
+// "email,metadata.ssn,metadata.phone". The empty string marks no fields.
+func ParseSensitiveFields(s string) (SensitiveFields, error) {
+	var f SensitiveFields
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		switch {
+		case field == "":
+		case field == "email":
+			f.Email = true
+		case strings.HasPrefix(field, "metadata.") && len(field) > len("metadata."):
+			f.Metadata = append(f.Metadata, strings.TrimPrefix(field, "metadata."))
+		default:
+			return SensitiveFields{}, fmt.Errorf("unknown sensitive field %q; want email or metadata.<key>", field)
+		}
+	}
+	slices.Sort(f.Metadata)
+	f.Metadata = slices.Compact(f.Metadata)
+	return f, nil
+}
+
+// Empty reports whether no field is sensitive.
+func (f SensitiveFields) Empty() bool {
+	return !f.Email && len(f.Metadata) == 0
+}
+
+// MetadataKey reports whether the metadata key is sensitive.
+func (f SensitiveFields) MetadataKey(key string) bool {
+	_, found := slices.BinarySearch(f.Metadata, key)
+	return found
+}
+
+// Mask returns u with the sensitive fields masked, copying it if anything
+// changes; u itself is never modified.
+func (f SensitiveFields) Mask(u *User) *User {
+	if u == nil || f.Empty() {
+		return u
+	}
+	masked := *u
+	if f.Email {
+		masked.Email = MaskEmail(u.Email)
+	}
+	cloned := false
+	for _, key := range f.Metadata {
+		if _, ok := u.Metadata[key]; ok {
+			if !cloned {
+				masked.Metadata, cloned = maps.Clone(u.Metadata), true
+			}
+			masked.Metadata[key] = MaskedValue
+		}
+	}
+	return &masked
+}
+
+// MaskEmail keeps the first character of the local part and the domain,
+// e.g. "a***@example.com".
+func MaskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return MaskedValue
+	}
+	_, first := utf8.DecodeRuneInString(email)
+	return email[:first] + MaskedValue + email[at:]
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestParseSensitiveFields(t *testing.T) {
+	f, err := ParseSensitiveFields(" email, metadata.ssn ,metadata.phone,metadata.ssn,")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !f.Email || len(f.Metadata) != 2 || !f.MetadataKey("ssn") || !f.MetadataKey("phone") || f.MetadataKey("plan") {
+		t.Errorf("unexpected fields %+v", f)
+	}
+	if f, _ := ParseSensitiveFields(""); !f.Empty() {
+		t.Errorf("expected no fields, got %+v", f)
+	}
+	for _, s := range []string{"name", "metadata.", "phone"} {
+		if _, err := ParseSensitiveFields(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestSensitiveFields_Mask(t *testing.T) {
+	f := SensitiveFields{Email: true, Metadata: []string{"ssn"}}
+	u := &User{Email: "ada@example.com", Metadata: map[string]any{"ssn": "078-05-1120", "plan": "pro"}}
+
+	masked := f.Mask(u)
+	if masked.Email != "a***@example.com" || masked.Metadata["ssn"] != MaskedValue || masked.Metadata["plan"] != "pro" {
+		t.Errorf("unexpected masked user %+v", masked)
+	}
+	if u.Email != "ada@example.com" || u.Metadata["ssn"] != "078-05-1120" {
+		t.Errorf("expected original unchanged, got %+v", u)
+	}
+	if got := (SensitiveFields{}).Mask(u); got != u {
+		t.Error("expected no copy without sensitive fields")
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := map[string]string{
+		"ada@example.com": "a***@example.com",
+		"élise@corp.fr":   "é***@corp.fr",
+		"@example.com":    MaskedValue,
+		"not-an-email":    MaskedValue,
+	}
+	for in, want := range tests {
+		if got := MaskEmail(in); got != want {
+			t.Errorf("expected %q for %q, got %q", want, in, got)
+		}
+	}
+}
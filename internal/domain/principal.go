@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Principal says who a request acts for. The actor made the call: a user
+// (ActorID) or, for calls from another system, a service account
+// (Service). Subject is the user the call takes effect as; it is the actor
+// unless the actor is impersonating someone, and zero for a service
+// account acting on its own behalf. Scopes lists the extra permissions
+// granted to the call, space-separated, such as ScopePIIRead. The zero
+// Principal is an anonymous call.
+type Principal struct {
+	ActorID   int64  `json:"actor,omitempty"`
+	Service   string `json:"service,omitempty"`
+	SubjectID int64  `json:"subject,omitempty"`
+	Scopes    string `json:"scopes,omitempty"`
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(p.Scopes), scope)
+}
+
+// Impersonating reports whether the subject is a user other than the actor.
+func (p Principal) Impersonating() bool {
+	return p.SubjectID != 0 && p.SubjectID != p.ActorID
+}
+
+// String describes the principal for logs, e.g. "user 1",
+// "service billing as user 7" or "anonymous".
+func (p Principal) String() string {
+	var actor string
+	switch {
+	case p.Service != "":
+		actor = "service " + p.Service
+	case p.ActorID != 0:
+		actor = fmt.Sprintf("user %d", p.ActorID)
+	default:
+		return "anonymous"
+	}
+	if p.Impersonating() {
+		return fmt.Sprintf("%s as user %d", actor, p.SubjectID)
+	}
+	return actor
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal ctx carries, or the anonymous one.
+func PrincipalFrom(ctx context.Context) Principal {
+	p, _ := ctx.Value(principalKey{}).(Principal)
+	return p
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestPrincipal_String(t *testing.T) {
+	tests := []struct {
+		p    Principal
+		want string
+	}{
+		{Principal{}, "anonymous"},
+		{Principal{ActorID: 1, SubjectID: 1}, "user 1"},
+		{Principal{ActorID: 1, SubjectID: 7}, "user 1 as user 7"},
+		{Principal{Service: "billing"}, "service billing"},
+		{Principal{Service: "billing", SubjectID: 7}, "service billing as user 7"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("expected %q for %+v, got %q", tt.want, tt.p, got)
+		}
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := Principal{ActorID: 1, Scopes: "audit:read pii:read"}
+	if !p.HasScope(ScopePIIRead) {
+		t.Error("expected pii:read scope")
+	}
+	if p.HasScope("pii") || (Principal{}).HasScope(ScopePIIRead) {
+		t.Error("expected only granted scopes")
+	}
+}
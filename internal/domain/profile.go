@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Profile limits, enforced by the validate tags on Profile.
+const (
+	MaxBioLength = 1000
+	MaxAddresses = 5
+)
+
+// Address is a postal address on a profile.
+type Address struct {
+	Label      string `json:"label,omitempty"`
+	Line1      string `json:"line1" validate:"required"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city" validate:"required"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country" validate:"required"`
+}
+
+// Profile holds optional personal details about a user. Each user has at
+// most one, keyed by UserID, and it goes away with the user.
+type Profile struct {
+	UserID    int64     `json:"user_id"`
+	Bio       string    `json:"bio" validate:"max=1000"`
+	Phone     string    `json:"phone" validate:"phone"`
+	Addresses []Address `json:"addresses" validate:"max=5"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the profile's fields, returning a *ValidationError. The
+// tags' limits are MaxBioLength and MaxAddresses.
+func (p *Profile) Validate() error {
+	return Validate(p)
+}
+
+func validPhone(s string) bool {
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0, r == ' ', r == '-', r == '(', r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 3 && digits <= 15
+}
+
+// ProfileRepository persists profiles by user ID.
+type ProfileRepository interface {
+	Repository[Profile, int64]
+	// Put creates or replaces the user's profile.
+	Put(ctx context.Context, profile *Profile) (*Profile, error)
+}
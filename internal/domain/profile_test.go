@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProfile_Validate(t *testing.T) {
+	home := Address{Line1: "1 Main St", City: "Springfield", Country: "US"}
+	valid := []Profile{
+		{},
+		{Bio: "Hello", Phone: "+1 (555) 010-0000", Addresses: []Address{home}},
+		{Bio: strings.Repeat("é", MaxBioLength)},
+	}
+	for _, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", p, err)
+		}
+	}
+
+	invalid := map[string]Profile{
+		"Long bio":         {Bio: strings.Repeat("a", MaxBioLength+1)},
+		"Letters in phone": {Phone: "555-CALL-NOW"},
+		"Plus not leading": {Phone: "1+555"},
+		"Too few digits":   {Phone: "12"},
+		"Too many":         {Addresses: make([]Address, MaxAddresses+1)},
+		"Incomplete":       {Addresses: []Address{{Line1: "1 Main St"}}},
+	}
+	for name, p := range invalid {
+		if err := p.Validate(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
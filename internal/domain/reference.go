@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUserReferenced is returned (as a *ReferenceError) when a user can't be
+// deleted because other records still refer to it.
+var ErrUserReferenced = errors.New("user is still referenced")
+
+// Reference identifies a record that refers to a user, such as an
+// organization membership. Kind names the aggregate and ID the record
+// within it.
+type Reference struct {
+	Kind string `json:"kind"`
+	ID   int64  `json:"id"`
+}
+
+// ReferenceError lists the references blocking a user's deletion. It
+// matches ErrUserReferenced with errors.Is.
+type ReferenceError struct {
+	References []Reference
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s by %d record(s)", ErrUserReferenced, len(e.References))
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return ErrUserReferenced
+}
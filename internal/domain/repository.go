@@ -0,0 +1,13 @@
+package domain
+
+import "context"
+
+// Repository is the read and delete side shared by aggregate ports. Each
+// port embeds it and adds its own writes, since how an aggregate is created
+// (assigned IDs, keyed by owner, ...) differs between aggregates.
+type Repository[T any, ID comparable] interface {
+	// GetByID returns the aggregate's not-found error if there is no such ID.
+	GetByID(ctx context.Context, id ID) (*T, error)
+	List(ctx context.Context) ([]*T, error)
+	Delete(ctx context.Context, id ID) error
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StatsGroup names the attribute user statistics are grouped by.
+type StatsGroup string
+
+const (
+	GroupByCreated     StatsGroup = "created"
+	GroupByEmailDomain StatsGroup = "email_domain"
+)
+
+// TimeBucket is the width of the buckets GroupByCreated counts users in.
+type TimeBucket string
+
+const (
+	BucketDay   TimeBucket = "day"
+	BucketWeek  TimeBucket = "week"
+	BucketMonth TimeBucket = "month"
+)
+
+// StatsQuery describes an aggregation over all users.
+type StatsQuery struct {
+	GroupBy StatsGroup
+	Bucket  TimeBucket
+}
+
+// Normalize fills in defaults: grouping by creation date in daily buckets.
+func (q StatsQuery) Normalize() StatsQuery {
+	if q.GroupBy == "" {
+		q.GroupBy = GroupByCreated
+	}
+	if q.Bucket == "" && q.GroupBy == GroupByCreated {
+		q.Bucket = BucketDay
+	}
+	return q
+}
+
+// Validate checks the query for groupings no backend can compute.
+func (q StatsQuery) Validate() error {
+	switch q.GroupBy {
+	case GroupByCreated, GroupByEmailDomain:
+	default:
+		return fmt.Errorf("%w: unknown group_by %q", ErrInvalidFilter, q.GroupBy)
+	}
+	switch q.Bucket {
+	case BucketDay, BucketWeek, BucketMonth:
+	case "":
+		if q.GroupBy == GroupByCreated {
+			return fmt.Errorf("%w: bucket is required", ErrInvalidFilter)
+		}
+	default:
+		return fmt.Errorf("%w: unknown bucket %q", ErrInvalidFilter, q.Bucket)
+	}
+	return nil
+}
+
+// StatsBucket is the number of users sharing a group key.
+type StatsBucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Stats is the result of a StatsQuery. Buckets are ordered by key.
+type Stats struct {
+	Total   int           `json:"total"`
+	GroupBy StatsGroup    `json:"group_by"`
+	Bucket  TimeBucket    `json:"bucket,omitempty"`
+	Buckets []StatsBucket `json:"buckets"`
+}
+
+// GroupKey returns the key u is counted under. Creation buckets are keyed by
+// their first day in UTC (weeks start on Monday); backends computing the
+// aggregation natively must produce the same keys.
+func GroupKey(u *User, q StatsQuery) string {
+	switch q.GroupBy {
+	case GroupByEmailDomain:
+		if i := strings.LastIndexByte(u.Email, '@'); i >= 0 {
+			return strings.ToLower(u.Email[i+1:])
+		}
+		return ""
+	default:
+		t := u.CreatedAt.UTC()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		switch q.Bucket {
+		case BucketWeek:
+			day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		case BucketMonth:
+			day = day.AddDate(0, 0, 1-day.Day())
+		}
+		return day.Format(time.DateOnly)
+	}
+}
+
+// NewStats assembles the result of q from backend buckets, ordering them by key.
+func NewStats(q StatsQuery, buckets []StatsBucket) *Stats {
+	sorted := append([]StatsBucket(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	stats := &Stats{GroupBy: q.GroupBy, Bucket: q.Bucket, Buckets: sorted}
+	for _, b := range sorted {
+		stats.Total += b.Count
+	}
+	return stats
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatsQuery_Validate(t *testing.T) {
+	valid := []StatsQuery{
+		{GroupBy: GroupByCreated, Bucket: BucketWeek},
+		{GroupBy: GroupByEmailDomain},
+		StatsQuery{}.Normalize(),
+	}
+	for _, q := range valid {
+		if err := q.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", q, err)
+		}
+	}
+	invalid := []StatsQuery{
+		{GroupBy: "status"},
+		{GroupBy: GroupByCreated},
+		{GroupBy: GroupByCreated, Bucket: "hour"},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter for %+v, got %v", q, err)
+		}
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	// Thursday
+	u := &User{Email: "Ann@Corp.COM", CreatedAt: time.Date(2024, 2, 15, 23, 30, 0, 0, time.UTC)}
+
+	cases := map[StatsQuery]string{
+		{GroupBy: GroupByCreated, Bucket: BucketDay}:   "2024-02-15",
+		{GroupBy: GroupByCreated, Bucket: BucketWeek}:  "2024-02-12",
+		{GroupBy: GroupByCreated, Bucket: BucketMonth}: "2024-02-01",
+		{GroupBy: GroupByEmailDomain}:                  "corp.com",
+	}
+	for q, want := range cases {
+		if got := GroupKey(u, q); got != want {
+			t.Errorf("expected %s for %+v, got %s", want, q, got)
+		}
+	}
+}
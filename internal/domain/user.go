@@ -1,22 +1,201 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// UserStatus is where a user is in its lifecycle.
+type UserStatus string
+
+const (
+	StatusActive    UserStatus = "active"
+	StatusSuspended UserStatus = "suspended"
+	// StatusDeleted is terminal. It is for backends that retain removed users;
+	// the memory repository deletes them outright.
+	StatusDeleted UserStatus = "deleted"
+)
+
+// transitions lists the statuses each status may move to.
+var transitions = map[UserStatus][]UserStatus{
+	StatusActive:    {StatusSuspended, StatusDeleted},
+	StatusSuspended: {StatusActive, StatusDeleted},
+}
+
+// Valid reports whether s is a known status.
+func (s UserStatus) Valid() bool {
+	switch s {
+	case StatusActive, StatusSuspended, StatusDeleted:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether a user in status s may move to status to.
+func (s UserStatus) CanTransition(to UserStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Role decides what a user may do through the API.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleMember, RoleViewer:
+		return true
+	}
+	return false
+}
 
 // User represents the core domain entity.
 // In a real system, avoid exposing persistence-specific concerns here.
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
+	ID     int64      `json:"id"`
+	Name   string     `json:"name" validate:"required,max=200"`
+	Email  string     `json:"email" validate:"required,email,max=254"`
+	Status UserStatus `json:"status" validate:"oneof=active suspended deleted"`
+	Role   Role       `json:"role" validate:"oneof=admin member viewer"`
+	// Tags label the user for segmentation; see NormalizeTags.
+	Tags []string `json:"tags,omitempty" validate:"max=20,tags"`
+	// Metadata holds arbitrary JSON for callers; see MergeMetadata. The
+	// limits are MaxMetadataKeys and MaxMetadataBytes.
+	Metadata map[string]any `json:"metadata,omitempty" validate:"max=50,maxjson=16384"`
+	// ExternalIDs maps an identity provider, such as "google" or
+	// "saml:acme", to the user's subject there. Each pair belongs to at
+	// most one user; see UserRepository.GetByExternalID.
+	ExternalIDs map[string]string `json:"external_ids,omitempty" validate:"max=10,externalids"`
+	// Version counts the writes to the user, starting at 1 on create.
+	Version   int64     `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Validate checks the user's fields, returning a *ValidationError. An empty
+// status or role is allowed, for updates that leave them unchanged.
+func (u *User) Validate() error {
+	return Validate(u)
+}
+
+// Transition moves u to status to, or returns ErrInvalidTransition if the
+// lifecycle doesn't allow it.
+func (u *User) Transition(to UserStatus) error {
+	if !u.Status.CanTransition(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, u.Status, to)
+	}
+	u.Status = to
+	return nil
+}
+
+// HasTag reports whether the user carries tag.
+func (u *User) HasTag(tag string) bool {
+	return slices.Contains(u.Tags, tag)
+}
+
+// NormalizeTag lower-cases and trims a tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes each tag and returns them sorted without
+// duplicates or blanks. The result is never nil, so it can replace a user's
+// tags with none.
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t = NormalizeTag(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// NormalizeExternalIDs lower-cases and trims providers and trims subjects,
+// dropping pairs with a blank provider or subject. The result is never nil,
+// so it can replace a user's external IDs with none.
+func NormalizeExternalIDs(ids map[string]string) map[string]string {
+	out := make(map[string]string, len(ids))
+	for provider, subject := range ids {
+		provider, subject = NormalizeTag(provider), strings.TrimSpace(subject)
+		if provider != "" && subject != "" {
+			out[provider] = subject
+		}
+	}
+	return out
+}
+
+// AnonymizedEmailDomain is the domain of the placeholder emails Anonymize
+// assigns. The .invalid TLD is reserved, so they can never be delivered.
+const AnonymizedEmailDomain = "anonymized.invalid"
+
+// Anonymize replaces the user's name and email with placeholders, unlinks
+// its external IDs and drops its metadata, which callers may have filled
+// with anything. The email is derived from the ID so it stays unique.
+func (u *User) Anonymize() {
+	u.Name = anonymizedName
+	u.Email = u.anonymizedEmail()
+	u.ExternalIDs = map[string]string{}
+	u.Metadata = nil
+}
+
+// Anonymized reports whether the user's name and email are the
+// placeholders Anonymize assigns.
+func (u *User) Anonymized() bool {
+	return u.Name == anonymizedName && u.Email == u.anonymizedEmail()
+}
+
+const anonymizedName = "Anonymized user"
+
+func (u *User) anonymizedEmail() string {
+	return fmt.Sprintf("user-%d@%s", u.ID, AnonymizedEmailDomain)
+}
+
 // UserRepository defines the persistence port for the User aggregate.
 type UserRepository interface {
-	Create(user *User) (*User, error)
-	GetByID(id int64) (*User, error)
-	List() ([]*User, error)
-	Update(user *User) (*User, error)
-	Delete(id int64) error
+	// Create and Update return ErrDuplicateEmail or ErrDuplicateExternalID
+	// if another user has the email or one of the external IDs.
+	Create(ctx context.Context, user *User) (*User, error)
+	GetByID(ctx context.Context, id int64) (*User, error)
+	// List returns the page of users the filter selects.
+	List(ctx context.Context, filter Filter) (*Page[User], error)
+	// Count returns how many users the filter selects, ignoring its
+	// paging, cursor and sort.
+	Count(ctx context.Context, filter Filter) (int, error)
+	// GetByEmail returns the user with email, compared case-insensitively,
+	// or ErrUserNotFound.
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	// SearchByNamePrefix returns up to limit users whose name starts with
+	// prefix, compared case-insensitively, ordered by name and then ID. A
+	// limit of zero means no limit.
+	SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*User, error)
+	// GetByExternalID returns the user linked to subject at provider, or
+	// ErrUserNotFound.
+	GetByExternalID(ctx context.Context, provider, subject string) (*User, error)
+	// Update stores the user's name and email, its status and role unless
+	// they are empty, and its tags, metadata and external IDs unless they
+	// are nil. A non-zero Version must match the stored one, or Update
+	// returns ErrVersionConflict.
+	Update(ctx context.Context, user *User) (*User, error)
+	// Delete removes the user. A non-zero version must match the stored one,
+	// as for Update.
+	Delete(ctx context.Context, id int64, version int64) error
+	// LastModified reports when the collection last changed (create, update or delete).
+	LastModified(ctx context.Context) (time.Time, error)
+	// Stats counts users per group key (see GroupKey), ordered by key.
+	Stats(ctx context.Context, q StatsQuery) ([]StatsBucket, error)
 }
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -60,3 +63,74 @@ func TestUser(t *testing.T) {
 		}
 	})
 }
+
+func TestUser_Transition(t *testing.T) {
+	tests := []struct {
+		from, to UserStatus
+		ok       bool
+	}{
+		{StatusActive, StatusSuspended, true},
+		{StatusSuspended, StatusActive, true},
+		{StatusActive, StatusDeleted, true},
+		{StatusSuspended, StatusDeleted, true},
+		{StatusActive, StatusActive, false},
+		{StatusDeleted, StatusActive, false},
+		{StatusDeleted, StatusSuspended, false},
+		{"", StatusActive, false},
+	}
+	for _, tt := range tests {
+		u := &User{Status: tt.from}
+		err := u.Transition(tt.to)
+		if tt.ok && (err != nil || u.Status != tt.to) {
+			t.Errorf("%q -> %q: expected success, got %v (status %q)", tt.from, tt.to, err, u.Status)
+		}
+		if !tt.ok && (!errors.Is(err, ErrInvalidTransition) || u.Status != tt.from) {
+			t.Errorf("%q -> %q: expected ErrInvalidTransition and unchanged status, got %v (status %q)", tt.from, tt.to, err, u.Status)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" VIP", "beta", "vip", "", "  "})
+	if strings.Join(got, ",") != "beta,vip" {
+		t.Errorf("expected beta,vip, got %v", got)
+	}
+	if got := NormalizeTags(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty non-nil slice, got %#v", got)
+	}
+}
+
+func TestNormalizeExternalIDs(t *testing.T) {
+	got := NormalizeExternalIDs(map[string]string{" GitHub ": " 583231 ", "google": "", "": "x"})
+	if !reflect.DeepEqual(got, map[string]string{"github": "583231"}) {
+		t.Errorf("expected only github, got %v", got)
+	}
+	if got := NormalizeExternalIDs(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty non-nil map, got %#v", got)
+	}
+}
+
+func TestUser_Anonymize(t *testing.T) {
+	u := &User{ID: 7, Name: "Ann", Email: "ann@example.com", Status: StatusActive, ExternalIDs: map[string]string{"google": "1"}, Metadata: map[string]any{"phone": "555"}}
+	if u.Anonymized() {
+		t.Fatal("expected a fresh user not to be anonymized")
+	}
+	u.Anonymize()
+	if u.Name != "Anonymized user" || u.Email != "user-7@"+AnonymizedEmailDomain || u.Status != StatusActive {
+		t.Errorf("unexpected anonymized user %+v", u)
+	}
+	if u.ExternalIDs == nil || len(u.ExternalIDs) != 0 {
+		t.Errorf("expected external IDs to be unlinked, got %v", u.ExternalIDs)
+	}
+	if u.Metadata != nil {
+		t.Errorf("expected metadata to be dropped, got %v", u.Metadata)
+	}
+	if !u.Anonymized() {
+		t.Error("expected the user to be anonymized")
+	}
+
+	lookalike := &User{ID: 8, Name: "Bob", Email: "user-8@" + AnonymizedEmailDomain}
+	if lookalike.Anonymized() {
+		t.Error("expected a placeholder email alone not to count as anonymized")
+	}
+}
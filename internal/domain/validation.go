@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is a validation failure of one field, named by its JSON path
+// such as "addresses[1].city".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field that failed validation. It matches
+// ErrInvalidInput with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrInvalidInput }
+
+// Invalid returns a ValidationError for a single field.
+func Invalid(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Validate checks v, a struct or pointer to one, against the rules in its
+// fields' `validate` tags and returns a *ValidationError naming every field
+// that fails. Nested structs and slices of structs are checked too.
+//
+// Rules are comma-separated: required, min=N, max=N, maxjson=N, email,
+// oneof=a b c, phone, tags and externalids. min and max count characters in
+// strings, items in slices and maps and the value of numbers; maxjson limits
+// the encoded size in bytes. Every rule but required passes for an empty
+// value.
+func Validate(v any) error {
+	var fields []FieldError
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &fields)
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string, out *[]FieldError) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous {
+			validateStruct(reflect.Indirect(fv), prefix, out)
+			continue
+		}
+		path := prefix + name
+		if tag := sf.Tag.Get("validate"); tag != "" {
+			if msg := checkRules(fv, tag); msg != "" {
+				*out = append(*out, FieldError{Field: path, Message: msg})
+				continue
+			}
+		}
+		switch fv.Kind() {
+		case reflect.Struct:
+			validateStruct(fv, path+".", out)
+		case reflect.Pointer:
+			validateStruct(reflect.Indirect(fv), path+".", out)
+		case reflect.Slice:
+			for j := range fv.Len() {
+				validateStruct(reflect.Indirect(fv.Index(j)), fmt.Sprintf("%s[%d].", path, j), out)
+			}
+		}
+	}
+}
+
+// fieldName is the field's JSON name, falling back to its Go name.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// checkRules returns the message of the first rule in tag that v fails.
+func checkRules(v reflect.Value, tag string) string {
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if isBlank(v) {
+				return "is required"
+			}
+			continue
+		}
+		if isBlank(v) {
+			continue
+		}
+		check, ok := rules[name]
+		if !ok {
+			panic(fmt.Sprintf("domain: unknown validation rule %q", name))
+		}
+		if msg := check(v, param); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func isBlank(v reflect.Value) bool {
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
+
+// rules maps each rule name to a check returning a message when v fails.
+var rules = map[string]func(v reflect.Value, param string) string{
+	"min": func(v reflect.Value, param string) string {
+		return checkBound(v, param, func(n, bound float64) bool { return n >= bound }, "at least")
+	},
+	"max": func(v reflect.Value, param string) string {
+		return checkBound(v, param, func(n, bound float64) bool { return n <= bound }, "at most")
+	},
+	"email": func(v reflect.Value, _ string) string {
+		s := v.String()
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "invalid format"
+		}
+		return ""
+	},
+	"oneof": func(v reflect.Value, param string) string {
+		options := strings.Fields(param)
+		for _, o := range options {
+			if v.String() == o {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	},
+	"tags": func(v reflect.Value, _ string) string {
+		for _, tag := range v.Interface().([]string) {
+			if !validTag(tag) {
+				return fmt.Sprintf("tag %q must be 1 to %d lowercase letters, digits, '-', '_' or ':'", tag, maxTagLength)
+			}
+		}
+		return ""
+	},
+	"externalids": func(v reflect.Value, _ string) string {
+		for provider, subject := range v.Interface().(map[string]string) {
+			if !validTag(provider) {
+				return fmt.Sprintf("provider %q must be 1 to %d lowercase letters, digits, '-', '_' or ':'", provider, maxTagLength)
+			}
+			if subject == "" || len(subject) > maxSubjectLength {
+				return fmt.Sprintf("subject for %q must be 1 to %d bytes", provider, maxSubjectLength)
+			}
+		}
+		return ""
+	},
+	"maxjson": func(v reflect.Value, param string) string {
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("domain: invalid validation bound %q", param))
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "must be valid JSON"
+		}
+		if len(b) > limit {
+			return fmt.Sprintf("must encode to at most %d bytes of JSON", limit)
+		}
+		return ""
+	},
+	"phone": func(v reflect.Value, _ string) string {
+		if !validPhone(v.String()) {
+			return "may only contain digits, spaces, dashes, parentheses and a leading +"
+		}
+		return ""
+	},
+}
+
+// checkBound compares v's size (characters, items or value) to param.
+func checkBound(v reflect.Value, param string, ok func(n, bound float64) bool, relation string) string {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("domain: invalid validation bound %q", param))
+	}
+	var n float64
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic(fmt.Sprintf("domain: min and max don't apply to %s", v.Kind()))
+	}
+	if ok(n, bound) {
+		return ""
+	}
+	return fmt.Sprintf("must be %s %s%s", relation, param, unit)
+}
+
+// maxTagLength is the longest tag the tags rule accepts, and the longest
+// provider the externalids rule does.
+const maxTagLength = 32
+
+// maxSubjectLength is the longest external subject the externalids rule
+// accepts.
+const maxSubjectLength = 255
+
+func validTag(tag string) bool {
+	if tag == "" || len(tag) > maxTagLength {
+		return false
+	}
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Valid values pass", func(t *testing.T) {
+		u := &User{Name: "Ada", Email: "ada@example.com", Role: RoleAdmin}
+		if err := Validate(u); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Every failing field is reported", func(t *testing.T) {
+		err := Validate(&User{Name: " ", Email: "ada", Role: "owner"})
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+		want := []FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "email", Message: "invalid format"},
+			{Field: "role", Message: "must be one of admin, member, viewer"},
+		}
+		if len(invalid.Fields) != len(want) {
+			t.Fatalf("expected %v, got %v", want, invalid.Fields)
+		}
+		for i := range want {
+			if invalid.Fields[i] != want[i] {
+				t.Errorf("expected %v, got %v", want[i], invalid.Fields[i])
+			}
+		}
+	})
+
+	t.Run("Matches ErrInvalidInput", func(t *testing.T) {
+		err := Invalid("password", "is too short")
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if err.Error() != "invalid input: password: is too short" {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	})
+
+	t.Run("Nested fields are named by path", func(t *testing.T) {
+		p := &Profile{
+			Bio:       strings.Repeat("x", MaxBioLength+1),
+			Addresses: []Address{{Line1: "1 Main St", City: "Springfield", Country: "US"}, {Line1: "2 Main St"}},
+		}
+		var invalid *ValidationError
+		if !errors.As(p.Validate(), &invalid) {
+			t.Fatalf("expected a validation error")
+		}
+		var fields []string
+		for _, f := range invalid.Fields {
+			fields = append(fields, f.Field+": "+f.Message)
+		}
+		want := "bio: must be at most 1000 characters, addresses[1].city: is required, addresses[1].country: is required"
+		if got := strings.Join(fields, ", "); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("Numbers and slices use their value and length", func(t *testing.T) {
+		var req struct {
+			UserID int64    `json:"user_id" validate:"required,min=1"`
+			Tags   []string `json:"tags" validate:"max=2"`
+		}
+		req.UserID, req.Tags = -1, []string{"a", "b", "c"}
+		err := Validate(&req)
+		if err == nil || !strings.Contains(err.Error(), "user_id: must be at least 1") || !strings.Contains(err.Error(), "tags: must be at most 2 items") {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// View is a saved, named user query. Filter holds an expression in the
+// ParseExpr grammar and SortBy the order its results are listed in.
+type View struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=200"`
+	Filter    string    `json:"filter"`
+	SortBy    SortField `json:"sort,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Query returns the Filter the view executes, without paging.
+func (v *View) Query() (Filter, error) {
+	f := Filter{Sort: SortSpec{Field: v.SortBy}}
+	if v.Filter != "" {
+		expr, err := ParseExpr(v.Filter)
+		if err != nil {
+			return f, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+		}
+		f.Expr = expr
+	}
+	return f, f.Validate()
+}
+
+// ViewRepository persists saved views.
+type ViewRepository interface {
+	Repository[View, int64]
+	Create(ctx context.Context, view *View) (*View, error)
+}
@@ -0,0 +1,137 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanarch/internal/jsonpath"
+)
+
+// Result is the outcome of one scenario.
+type Result struct {
+	Scenario string
+	Passed   bool
+	// Failure describes the first failed step; empty when Passed.
+	Failure  string
+	Duration time.Duration
+}
+
+// Runner executes scenarios against BaseURL.
+type Runner struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Run executes the steps of s in order and stops at the first failure.
+func (r *Runner) Run(ctx context.Context, s Scenario) Result {
+	start := time.Now()
+	vars := map[string]string{"run_id": strconv.FormatInt(time.Now().UnixNano(), 36)}
+	for i, step := range s.Steps {
+		if err := r.runStep(ctx, step, vars); err != nil {
+			name := step.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return Result{Scenario: s.Name, Failure: fmt.Sprintf("step %s: %v", name, err), Duration: time.Since(start)}
+		}
+	}
+	return Result{Scenario: s.Name, Passed: true, Duration: time.Since(start)}
+}
+
+func (r *Runner) runStep(ctx context.Context, step Step, vars map[string]string) error {
+	var body io.Reader
+	if len(step.Body) > 0 {
+		body = strings.NewReader(expand(string(step.Body), vars))
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, r.BaseURL+expand(step.Path, vars), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if step.Expect.Status != 0 && resp.StatusCode != step.Expect.Status {
+		return fmt.Errorf("expected status %d, got %d: %s", step.Expect.Status, resp.StatusCode, bytes.TrimSpace(raw))
+	}
+	if len(step.Expect.JSON) == 0 && step.Expect.Length == nil && len(step.Capture) == 0 {
+		return nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("expected JSON body: %v", err)
+	}
+	if step.Expect.Length != nil {
+		if list, ok := doc.(map[string]any); ok {
+			doc = list["items"]
+		}
+		arr, ok := doc.([]any)
+		if !ok {
+			return fmt.Errorf("expected a JSON array")
+		}
+		if len(arr) != *step.Expect.Length {
+			return fmt.Errorf("expected %d items, got %d", *step.Expect.Length, len(arr))
+		}
+	}
+	for field, want := range step.Expect.JSON {
+		if s, ok := want.(string); ok {
+			want = expand(s, vars)
+		}
+		got, ok := jsonpath.Lookup(doc, field)
+		if !ok {
+			return fmt.Errorf("field %q missing", field)
+		}
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("field %q: expected %v, got %v", field, want, got)
+		}
+	}
+	for name, field := range step.Capture {
+		v, ok := jsonpath.Lookup(doc, field)
+		if !ok {
+			return fmt.Errorf("capture %q: field %q missing", name, field)
+		}
+		vars[name] = stringify(v)
+	}
+	return nil
+}
+
+// expand replaces {{name}} references with captured variables.
+func expand(s string, vars map[string]string) string {
+	for name, v := range vars {
+		s = strings.ReplaceAll(s, "{{"+name+"}}", v)
+	}
+	return s
+}
+
+func stringify(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"cleanarch/internal/testsupport"
+)
+
+func parse(t *testing.T, doc string) Scenario {
+	t.Helper()
+	var s Scenario
+	if err := json.Unmarshal([]byte(doc), &s); err != nil {
+		t.Fatalf("invalid scenario: %v", err)
+	}
+	return s
+}
+
+func TestRunner_Run(t *testing.T) {
+	t.Run("Captured variables flow between steps", func(t *testing.T) {
+		ts := testsupport.NewServer(t, testsupport.BackendMemory)
+		s := parse(t, `{"name": "crud", "steps": [
+			{"method": "POST", "path": "/api/v1/users", "body": {"name": "A", "email": "a-{{run_id}}@example.com"},
+			 "expect": {"status": 201}, "capture": {"id": "id"}},
+			{"method": "GET", "path": "/api/v1/users/{{id}}", "expect": {"status": 200, "json": {"name": "A"}}},
+			{"method": "GET", "path": "/api/v1/users", "expect": {"status": 200, "length": 1}}
+		]}`)
+
+		res := (&Runner{BaseURL: ts.URL}).Run(context.Background(), s)
+		if !res.Passed {
+			t.Errorf("expected scenario to pass, got %s", res.Failure)
+		}
+	})
+
+	t.Run("Failed expectation stops the scenario", func(t *testing.T) {
+		ts := testsupport.NewServer(t, testsupport.BackendMemory)
+		s := parse(t, `{"name": "bad", "steps": [
+			{"name": "missing", "method": "GET", "path": "/api/v1/users/999", "expect": {"status": 200}},
+			{"name": "never", "method": "GET", "path": "/healthz", "expect": {"status": 200}}
+		]}`)
+
+		res := (&Runner{BaseURL: ts.URL}).Run(context.Background(), s)
+		if res.Passed {
+			t.Fatal("expected scenario to fail")
+		}
+		if !strings.Contains(res.Failure, "step missing") || !strings.Contains(res.Failure, "expected status 200, got 404") {
+			t.Errorf("unexpected failure message %q", res.Failure)
+		}
+	})
+}
+
+func TestLoadScenarios(t *testing.T) {
+	t.Run("Loads JSON files in name order", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"s/b.json":   {Data: []byte(`{"name": "second"}`)},
+			"s/a.json":   {Data: []byte(`{"steps": []}`)},
+			"s/notes.md": {Data: []byte(`ignored`)},
+		}
+		scenarios, err := LoadScenarios(fsys, "s")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(scenarios) != 2 || scenarios[0].Name != "a.json" || scenarios[1].Name != "second" {
+			t.Errorf("unexpected scenarios %+v", scenarios)
+		}
+	})
+
+	t.Run("Invalid JSON reports the file", func(t *testing.T) {
+		fsys := fstest.MapFS{"s/broken.json": {Data: []byte(`{`)}}
+		if _, err := LoadScenarios(fsys, "s"); err == nil || !strings.Contains(err.Error(), "broken.json") {
+			t.Errorf("expected error naming broken.json, got %v", err)
+		}
+	})
+}
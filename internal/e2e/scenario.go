@@ -0,0 +1,67 @@
+// Package e2e runs declarative HTTP scenarios against a running server.
+//
+// A scenario is a JSON document with ordered steps. Each step sends one
+// request, checks the response, and may capture response fields into
+// variables that later steps reference as {{name}}. The variable {{run_id}}
+// is always defined and unique per run, for building unique test data.
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// Scenario is a named sequence of steps.
+type Scenario struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// Step is one request and its expectations.
+type Step struct {
+	Name   string          `json:"name"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Expect Expect          `json:"expect"`
+	// Capture maps variable names to dotted response fields, e.g. {"id": "id"}.
+	Capture map[string]string `json:"capture,omitempty"`
+}
+
+// Expect lists what a response must satisfy. Zero values are not checked.
+type Expect struct {
+	Status int `json:"status"`
+	// JSON maps dotted response fields to their expected values.
+	JSON map[string]any `json:"json,omitempty"`
+	// Length is the expected length of a top-level JSON array, or of the
+	// items of a list response.
+	Length *int `json:"length,omitempty"`
+}
+
+// LoadScenarios reads every *.json file in dir of fsys, sorted by file name.
+func LoadScenarios(fsys fs.FS, dir string) ([]Scenario, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	scenarios := make([]Scenario, 0, len(names))
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var s Scenario
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if s.Name == "" {
+			s.Name = path.Base(name)
+		}
+		scenarios = append(scenarios, s)
+	}
+	return scenarios, nil
+}
@@ -0,0 +1,62 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAESGCM(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("Encrypt then decrypt", func(t *testing.T) {
+		sealed := c.Encrypt("ada@example.com")
+		if !IsEncrypted(sealed) || strings.Contains(sealed, "ada") {
+			t.Errorf("expected an opaque ciphertext, got %q", sealed)
+		}
+		if got, err := c.Decrypt(sealed); err != nil || got != "ada@example.com" {
+			t.Errorf("expected ada@example.com, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("Equal plaintexts give equal ciphertexts", func(t *testing.T) {
+		if c.Encrypt("x") != c.Encrypt("x") {
+			t.Error("expected deterministic ciphertexts")
+		}
+		if c.Encrypt("x") == c.Encrypt("y") {
+			t.Error("expected different plaintexts to differ")
+		}
+	})
+
+	t.Run("Plaintext passes through", func(t *testing.T) {
+		if got, err := c.Decrypt("ada@example.com"); err != nil || got != "ada@example.com" {
+			t.Errorf("expected value unchanged, got %q, %v", got, err)
+		}
+	})
+
+	t.Run("Tampered or foreign ciphertexts fail", func(t *testing.T) {
+		other, _ := New(bytes.Repeat([]byte{8}, KeySize))
+		sealed := c.Encrypt("ada@example.com")
+		for _, v := range []string{sealed[:len(sealed)-2] + "00", other.Encrypt("ada@example.com"), prefix + "zz", prefix} {
+			if _, err := c.Decrypt(v); !errors.Is(err, ErrMalformed) {
+				t.Errorf("expected ErrMalformed for %q, got %v", v, err)
+			}
+		}
+	})
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey(base64.StdEncoding.EncodeToString(make([]byte, KeySize))); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	for _, s := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package fixture records HTTP request/response pairs to disk and replays
+// them, for deterministic consumer contract tests and offline demos.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Fixture is one recorded exchange, stored as <key>.json in the fixture directory.
+type Fixture struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Options controls what a fixture keeps of an exchange. Record and Replay
+// must be given the same options, or recorded fixtures won't be found.
+type Options struct {
+	// Headers are the request headers that identify a fixture along with
+	// the method, path, query and body, such as the caller's identity, so
+	// a response recorded for one caller isn't replayed to another.
+	Headers []string
+	// Redact names JSON fields, at any depth, whose values are replaced
+	// before a fixture is written. Fields named "password" always are.
+	Redact []string
+}
+
+// redacted replaces the values of redacted fields.
+const redacted = "[redacted]"
+
+// Key identifies a request by method, path, query, headers and body.
+func (r Request) Key() string {
+	h := sha256.New()
+	parts := []string{r.Method, r.Path, r.Query}
+	for _, k := range slices.Sorted(maps.Keys(r.Header)) {
+		parts = append(parts, k, strings.Join(r.Header[k], ","))
+	}
+	for _, part := range append(parts, r.Body) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// readRequest captures r's identifying parts, redacted, and restores its
+// body for the next handler.
+func readRequest(r *http.Request, opts Options) (Request, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return Request{}, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: opts.redact(body)}
+	for _, k := range opts.Headers {
+		if v := r.Header.Values(k); len(v) > 0 {
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			req.Header[http.CanonicalHeaderKey(k)] = slices.Clone(v)
+		}
+	}
+	return req, nil
+}
+
+// redact returns body with the values of redacted fields replaced. Bodies
+// that aren't JSON, or have nothing to redact, are returned unchanged.
+func (o Options) redact(body []byte) string {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&v) != nil {
+		return string(body)
+	}
+	fields := append([]string{"password"}, o.Redact...)
+	if !redactFields(v, fields) {
+		return string(body)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+// redactFields replaces the values of fields throughout v and reports
+// whether it replaced any.
+func redactFields(v any, fields []string) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if slices.Contains(fields, k) {
+				v[k] = redacted
+				changed = true
+			} else if redactFields(field, fields) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactFields(item, fields) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// Record passes requests through to next and writes each exchange to dir,
+// redacted as opts says. Recording failures are logged and never affect
+// the response.
+func Record(dir string, opts Options, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := readRequest(r, opts)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		tee := &teeWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(tee, r)
+
+		f := Fixture{
+			Request:  req,
+			Response: Response{Status: tee.status, Header: w.Header().Clone(), Body: opts.redact(tee.body.Bytes())},
+		}
+		if err := save(dir, f); err != nil {
+			log.Printf("fixture record error: %v", err)
+		}
+	})
+}
+
+// Replay serves recorded responses from dir. Requests without a fixture get 404.
+// Replay doesn't authorize anything; a caller that needs that puts it in front.
+func Replay(dir string, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := readRequest(r, opts)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		f, err := load(dir, req.Key())
+		if errors.Is(err, os.ErrNotExist) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "no recorded fixture for request"})
+			return
+		}
+		if err != nil {
+			log.Printf("fixture replay error: %v", err)
+			http.Error(w, "failed to load fixture", http.StatusInternalServerError)
+			return
+		}
+		for k, v := range f.Response.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(f.Response.Status)
+		_, _ = io.WriteString(w, f.Response.Body)
+	})
+}
+
+func save(dir string, f Fixture) error {
+	// Fixtures hold whatever callers sent that wasn't redacted, so only
+	// the owner may read them.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, f.Request.Key()+".json"), b, 0o600)
+}
+
+func load(dir, key string) (Fixture, error) {
+	var f Fixture
+	b, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return f, err
+	}
+	err = json.Unmarshal(b, &f)
+	return f, err
+}
+
+// teeWriter copies the response body and remembers the status code.
+type teeWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (t *teeWriter) WriteHeader(code int) {
+	t.status = code
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	t.body.Write(p)
+	return t.ResponseWriter.Write(p)
+}
@@ -0,0 +1,112 @@
+package fixture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Run("Replay serves what was recorded", func(t *testing.T) {
+		dir := t.TempDir()
+		calls := 0
+		upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(body)
+		})
+
+		rec := httptest.NewRecorder()
+		Record(dir, Options{}, upstream).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users?x=1", strings.NewReader(`{"name":"John"}`)))
+		if rec.Body.String() != `{"name":"John"}` {
+			t.Fatalf("expected upstream body to pass through, got %q", rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		Replay(dir, Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users?x=1", strings.NewReader(`{"name":"John"}`)))
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d", rec.Code)
+		}
+		if rec.Body.String() != `{"name":"John"}` {
+			t.Errorf("expected recorded body, got %q", rec.Body.String())
+		}
+		if rec.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("expected recorded Content-Type, got %q", rec.Header().Get("Content-Type"))
+		}
+		if calls != 1 {
+			t.Errorf("expected upstream to be called once, got %d", calls)
+		}
+	})
+
+	t.Run("Different body is a different fixture", func(t *testing.T) {
+		dir := t.TempDir()
+		Record(dir, Options{}, http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("1")))
+
+		rec := httptest.NewRecorder()
+		Replay(dir, Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("2")))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "no recorded fixture") {
+			t.Errorf("expected missing fixture error, got %q", rec.Body.String())
+		}
+	})
+
+	t.Run("Fixtures are readable JSON files", func(t *testing.T) {
+		dir := t.TempDir()
+		Record(dir, Options{}, http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".json") {
+			t.Errorf("expected one .json fixture, got %v", entries)
+		}
+	})
+
+	t.Run("Fixtures are redacted and private", func(t *testing.T) {
+		dir := t.TempDir()
+		upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id":1,"email":"ann@example.com"}`))
+		})
+		Record(dir, Options{Redact: []string{"email"}}, upstream).ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodPut, "/users/1/password", strings.NewReader(`{"password":"hunter22"}`)))
+
+		entries, _ := os.ReadDir(dir)
+		info, _ := entries[0].Info()
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+		if strings.Contains(string(b), "hunter22") || strings.Contains(string(b), "ann@example.com") {
+			t.Errorf("expected the password and email to be redacted, got %s", b)
+		}
+
+		rec := httptest.NewRecorder()
+		Replay(dir, Options{Redact: []string{"email"}}).ServeHTTP(rec,
+			httptest.NewRequest(http.MethodPut, "/users/1/password", strings.NewReader(`{"password":"other"}`)))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), redacted) {
+			t.Errorf("expected the redacted fixture to be replayed, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Identifying headers are part of the key", func(t *testing.T) {
+		dir := t.TempDir()
+		opts := Options{Headers: []string{"X-User-ID"}}
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		req.Header.Set("X-User-ID", "1")
+		Record(dir, opts, http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+
+		other := httptest.NewRequest(http.MethodGet, "/a", nil)
+		other.Header.Set("X-User-ID", "2")
+		rec := httptest.NewRecorder()
+		Replay(dir, opts).ServeHTTP(rec, other)
+		if !strings.Contains(rec.Body.String(), "no recorded fixture") {
+			t.Errorf("expected another caller to miss the fixture, got %q", rec.Body.String())
+		}
+	})
+}
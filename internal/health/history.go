@@ -0,0 +1,221 @@
+package health
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// maxIncidents bounds the incident history kept by a registry; the
+	// oldest markers are dropped first.
+	maxIncidents = 50
+	// sampleSize is how many recent results are kept per check.
+	sampleSize = 20
+	// flapTransitions is how many status changes within the kept samples
+	// mark a check as flapping.
+	flapTransitions = 4
+)
+
+// Incident marks a period during which a check was down. Resolved is nil
+// while the check is still failing.
+type Incident struct {
+	Component string     `json:"component"`
+	Started   time.Time  `json:"started"`
+	Resolved  *time.Time `json:"resolved,omitempty"`
+}
+
+// Component is the recorded state of one check.
+type Component struct {
+	Name   string    `json:"name"`
+	Status Status    `json:"status"`
+	Since  time.Time `json:"since"`
+	Checks int64     `json:"checks"`
+	Up     int64     `json:"up"`
+	// Uptime is the fraction of recorded runs in which the check passed.
+	Uptime float64 `json:"uptime"`
+	// Flapping is set while the check keeps changing status.
+	Flapping bool `json:"flapping"`
+}
+
+// History is the state of a registry as recorded by its runs.
+type History struct {
+	Status     Status      `json:"status"`
+	Started    time.Time   `json:"started"`
+	Uptime     float64     `json:"uptime_seconds"`
+	Components []Component `json:"components"`
+	Incidents  []Incident  `json:"incidents"`
+}
+
+// Sample is one recorded result of a check.
+type Sample struct {
+	At time.Time `json:"at"`
+	Result
+}
+
+// Detail is the admin view of the history: the public History plus the
+// recent samples of every check, oldest first, errors included.
+type Detail struct {
+	History
+	Samples map[string][]Sample `json:"samples"`
+}
+
+// Transition reports a check changing status. Checks are taken to start
+// up, so a check that fails on its first run transitions from up to down.
+type Transition struct {
+	Component string    `json:"component"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	At        time.Time `json:"at"`
+	Error     string    `json:"error,omitempty"`
+	Flapping  bool      `json:"flapping"`
+}
+
+type componentHistory struct {
+	status   Status
+	since    time.Time
+	checks   int64
+	up       int64
+	open     bool
+	flapping bool
+	samples  []Sample
+}
+
+// add appends s to the kept samples, dropping the oldest beyond sampleSize,
+// and reports whether the samples now show the check flapping.
+func (h *componentHistory) add(s Sample) bool {
+	h.samples = append(h.samples, s)
+	if len(h.samples) > sampleSize {
+		h.samples = h.samples[len(h.samples)-sampleSize:]
+	}
+	changes := 0
+	for i := 1; i < len(h.samples); i++ {
+		if h.samples[i].Status != h.samples[i-1].Status {
+			changes++
+		}
+	}
+	return changes >= flapTransitions
+}
+
+// OnTransition registers fn to be called after a run for every check whose
+// status changed. Calls happen on the goroutine running the checks.
+func (r *Registry) OnTransition(fn func(Transition)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// record folds the outcome of a run into the history, opening an incident
+// when a check goes down and resolving it when the check recovers, then
+// notifies transition listeners.
+func (r *Registry) record(report Report, now time.Time) {
+	r.mu.Lock()
+	var transitions []Transition
+	for name, result := range report.Checks {
+		h, ok := r.history[name]
+		if !ok {
+			h = &componentHistory{status: StatusUp, since: now}
+			r.history[name] = h
+		}
+		h.checks++
+		if result.Status == StatusUp {
+			h.up++
+		}
+		h.flapping = h.add(Sample{At: now, Result: result})
+		if h.flapping {
+			setGauge(name+".flapping", 1)
+		} else {
+			setGauge(name+".flapping", 0)
+		}
+		if h.status != result.Status {
+			transitions = append(transitions, Transition{
+				Component: name,
+				From:      h.status,
+				To:        result.Status,
+				At:        now,
+				Error:     result.Error,
+				Flapping:  h.flapping,
+			})
+			h.status = result.Status
+			h.since = now
+		}
+		switch {
+		case result.Status == StatusDown && !h.open:
+			r.incidents = append(r.incidents, Incident{Component: name, Started: now})
+			if len(r.incidents) > maxIncidents {
+				r.incidents = r.incidents[len(r.incidents)-maxIncidents:]
+			}
+			h.open = true
+		case result.Status == StatusUp && h.open:
+			r.resolve(name, now)
+			h.open = false
+		}
+	}
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].Component < transitions[j].Component })
+	for _, t := range transitions {
+		for _, fn := range listeners {
+			fn(t)
+		}
+	}
+}
+
+// resolve closes the open incident for name, if it is still in the history.
+func (r *Registry) resolve(name string, now time.Time) {
+	for i := len(r.incidents) - 1; i >= 0; i-- {
+		if r.incidents[i].Component == name && r.incidents[i].Resolved == nil {
+			resolved := now
+			r.incidents[i].Resolved = &resolved
+			return
+		}
+	}
+}
+
+// History returns the recorded component states, newest incidents first.
+// Checks that have not run yet are not listed.
+func (r *Registry) History() History {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.historyLocked()
+}
+
+func (r *Registry) historyLocked() History {
+	h := History{
+		Status:     StatusUp,
+		Started:    r.started,
+		Uptime:     time.Since(r.started).Seconds(),
+		Components: make([]Component, 0, len(r.history)),
+		Incidents:  make([]Incident, 0, len(r.incidents)),
+	}
+	for name, c := range r.history {
+		if c.status == StatusDown {
+			h.Status = StatusDown
+		}
+		h.Components = append(h.Components, Component{
+			Name:     name,
+			Status:   c.status,
+			Since:    c.since,
+			Checks:   c.checks,
+			Up:       c.up,
+			Uptime:   float64(c.up) / float64(c.checks),
+			Flapping: c.flapping,
+		})
+	}
+	sort.Slice(h.Components, func(i, j int) bool { return h.Components[i].Name < h.Components[j].Name })
+	for i := len(r.incidents) - 1; i >= 0; i-- {
+		h.Incidents = append(h.Incidents, r.incidents[i])
+	}
+	return h
+}
+
+// Detail returns the history together with each check's recent samples.
+func (r *Registry) Detail() Detail {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d := Detail{History: r.historyLocked(), Samples: make(map[string][]Sample, len(r.history))}
+	for name, c := range r.history {
+		d.Samples[name] = append([]Sample(nil), c.samples...)
+	}
+	return d
+}
@@ -0,0 +1,186 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_History(t *testing.T) {
+	t.Run("Records incidents when a check goes down and recovers", func(t *testing.T) {
+		r := NewRegistry()
+		var failing bool
+		r.Register("queue", func(ctx context.Context) error {
+			if failing {
+				return errors.New("unreachable")
+			}
+			return nil
+		})
+
+		r.Run(context.Background())
+		failing = true
+		r.Run(context.Background())
+		r.Run(context.Background())
+
+		h := r.History()
+		if h.Status != StatusDown {
+			t.Errorf("expected status down, got %s", h.Status)
+		}
+		if len(h.Incidents) != 1 || h.Incidents[0].Resolved != nil {
+			t.Fatalf("expected one open incident, got %+v", h.Incidents)
+		}
+
+		failing = false
+		r.Run(context.Background())
+
+		h = r.History()
+		if h.Status != StatusUp {
+			t.Errorf("expected status up, got %s", h.Status)
+		}
+		if len(h.Incidents) != 1 || h.Incidents[0].Resolved == nil {
+			t.Fatalf("expected one resolved incident, got %+v", h.Incidents)
+		}
+		c := h.Components[0]
+		if c.Checks != 4 || c.Up != 2 || c.Uptime != 0.5 {
+			t.Errorf("expected 2 of 4 checks up, got %d of %d (%g)", c.Up, c.Checks, c.Uptime)
+		}
+	})
+
+	t.Run("Keeps the most recent incidents", func(t *testing.T) {
+		r := NewRegistry()
+		var failing bool
+		r.Register("queue", func(ctx context.Context) error {
+			if failing {
+				return errors.New("unreachable")
+			}
+			return nil
+		})
+		for i := 0; i < maxIncidents+5; i++ {
+			failing = true
+			r.Run(context.Background())
+			failing = false
+			r.Run(context.Background())
+		}
+
+		h := r.History()
+		if len(h.Incidents) != maxIncidents {
+			t.Fatalf("expected %d incidents, got %d", maxIncidents, len(h.Incidents))
+		}
+		if !h.Incidents[0].Started.After(h.Incidents[len(h.Incidents)-1].Started) {
+			t.Error("expected newest incident first")
+		}
+	})
+}
+
+func TestRegistry_Flapping(t *testing.T) {
+	r := NewRegistry()
+	var failing bool
+	r.Register("cache", func(ctx context.Context) error {
+		if failing {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+	var transitions []Transition
+	r.OnTransition(func(tr Transition) { transitions = append(transitions, tr) })
+
+	t.Run("Transitions are reported once per change", func(t *testing.T) {
+		r.Run(context.Background())
+		failing = true
+		r.Run(context.Background())
+		r.Run(context.Background())
+
+		if len(transitions) != 1 {
+			t.Fatalf("expected 1 transition, got %+v", transitions)
+		}
+		if tr := transitions[0]; tr.From != StatusUp || tr.To != StatusDown || tr.Error != "timeout" || tr.Flapping {
+			t.Errorf("expected up -> down with error and no flapping, got %+v", tr)
+		}
+	})
+
+	t.Run("Repeated changes mark the check flapping", func(t *testing.T) {
+		for i := 0; i < flapTransitions; i++ {
+			failing = !failing
+			r.Run(context.Background())
+		}
+		if last := transitions[len(transitions)-1]; !last.Flapping {
+			t.Errorf("expected last transition flapping, got %+v", last)
+		}
+		if c := r.History().Components[0]; !c.Flapping {
+			t.Errorf("expected component flapping, got %+v", c)
+		}
+	})
+
+	t.Run("Steady results clear flapping", func(t *testing.T) {
+		for i := 0; i < sampleSize; i++ {
+			r.Run(context.Background())
+		}
+		if c := r.History().Components[0]; c.Flapping {
+			t.Errorf("expected flapping cleared, got %+v", c)
+		}
+	})
+
+	t.Run("Detail keeps the most recent samples", func(t *testing.T) {
+		samples := r.Detail().Samples["cache"]
+		if len(samples) != sampleSize {
+			t.Fatalf("expected %d samples, got %d", sampleSize, len(samples))
+		}
+		if samples[0].At.After(samples[len(samples)-1].At) {
+			t.Error("expected samples oldest first")
+		}
+	})
+}
+
+func TestRegistry_StatusHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Register("queue", func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:5672") })
+
+	t.Run("Serves JSON by default", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+		var h History
+		if err := json.NewDecoder(rec.Body).Decode(&h); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		if h.Status != StatusDown || len(h.Components) != 1 || len(h.Incidents) != 1 {
+			t.Errorf("expected one down component with an incident, got %+v", h)
+		}
+	})
+
+	t.Run("Serves HTML to browsers without check errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml")
+		rec := httptest.NewRecorder()
+		r.StatusHandler().ServeHTTP(rec, req)
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("expected HTML content type, got %q", ct)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "queue") || !strings.Contains(body, "ongoing") {
+			t.Errorf("expected queue incident on the page, got %s", body)
+		}
+		if strings.Contains(body, "10.0.0.5") {
+			t.Error("expected check errors to be left out")
+		}
+	})
+
+	t.Run("Admin detail includes check errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.DetailHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/health", nil))
+		var d Detail
+		if err := json.NewDecoder(rec.Body).Decode(&d); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		samples := d.Samples["queue"]
+		if len(samples) == 0 || samples[0].Error != "dial tcp 10.0.0.5:5672" {
+			t.Errorf("expected queue samples with errors, got %+v", samples)
+		}
+	})
+}
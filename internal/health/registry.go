@@ -0,0 +1,181 @@
+// Package health implements the readiness registry served at /readyz.
+// Subsystems (repository backends, queues, caches, webhook delivery)
+// register their own checks; the registry runs them concurrently and
+// reports a combined result. The registry also keeps a short history of
+// check outcomes, served as a public status page at /status.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single check when the registry has no timeout set.
+const DefaultTimeout = 2 * time.Second
+
+// Status is the outcome of a check or of the whole registry.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckFunc probes a dependency and returns nil when it is ready.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status   Status        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Report is the combined outcome served by Handler.
+type Report struct {
+	Status  Status            `json:"status"`
+	Checks  map[string]Result `json:"checks"`
+	Details map[string]any    `json:"details,omitempty"`
+}
+
+// readinessGauges is published at /debug/vars as "readiness": 1 for up,
+// 0 for down, plus any values reported by Threshold checks.
+var readinessGauges = expvar.NewMap("readiness")
+
+// Registry holds named readiness checks.
+type Registry struct {
+	mu      sync.RWMutex
+	timeout time.Duration
+	checks  map[string]CheckFunc
+	details map[string]func() any
+
+	started   time.Time
+	history   map[string]*componentHistory
+	incidents []Incident
+	listeners []func(Transition)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		timeout: DefaultTimeout,
+		checks:  make(map[string]CheckFunc),
+		details: make(map[string]func() any),
+		started: time.Now(),
+		history: make(map[string]*componentHistory),
+	}
+}
+
+// Register adds or replaces the check called name.
+func (r *Registry) Register(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// RegisterDetail adds informational state to every report under name.
+// Details do not affect readiness.
+func (r *Registry) RegisterDetail(name string, detail func() any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[name] = detail
+}
+
+// Names returns the registered check names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes every check concurrently, each bounded by the registry timeout.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]CheckFunc, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	var details map[string]any
+	if len(r.details) > 0 {
+		details = make(map[string]any, len(r.details))
+		for name, detail := range r.details {
+			details[name] = detail()
+		}
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks)), Details: details}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := r.runOne(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+				setGauge(name, 0)
+			} else {
+				setGauge(name, 1)
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	r.record(report, time.Now())
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := Result{Status: StatusUp, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Handler serves the registry report as JSON: 200 when every check is up, 503 otherwise.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+		status := http.StatusOK
+		if report.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+func setGauge(key string, v float64) {
+	g := new(expvar.Float)
+	g.Set(v)
+	readinessGauges.Set(key, g)
+}
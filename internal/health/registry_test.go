@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	t.Run("Empty registry is up", func(t *testing.T) {
+		report := NewRegistry().Run(context.Background())
+		if report.Status != StatusUp {
+			t.Errorf("expected status up, got %s", report.Status)
+		}
+	})
+
+	t.Run("Failing check marks registry down", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("ok", func(ctx context.Context) error { return nil })
+		r.Register("broken", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		report := r.Run(context.Background())
+		if report.Status != StatusDown {
+			t.Errorf("expected status down, got %s", report.Status)
+		}
+		if report.Checks["ok"].Status != StatusUp {
+			t.Errorf("expected ok check up, got %s", report.Checks["ok"].Status)
+		}
+		if report.Checks["broken"].Error != "connection refused" {
+			t.Errorf("expected error 'connection refused', got %q", report.Checks["broken"].Error)
+		}
+	})
+
+	t.Run("Details are reported without affecting status", func(t *testing.T) {
+		r := NewRegistry()
+		r.RegisterDetail("read_only", func() any { return true })
+
+		report := r.Run(context.Background())
+		if report.Status != StatusUp {
+			t.Errorf("expected status up, got %s", report.Status)
+		}
+		if report.Details["read_only"] != true {
+			t.Errorf("expected read_only detail true, got %v", report.Details["read_only"])
+		}
+	})
+
+	t.Run("Slow check times out", func(t *testing.T) {
+		r := NewRegistry()
+		r.timeout = 10 * time.Millisecond
+		r.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		report := r.Run(context.Background())
+		if report.Checks["slow"].Status != StatusDown {
+			t.Errorf("expected slow check down, got %s", report.Checks["slow"].Status)
+		}
+	})
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	t.Run("Responds 503 with JSON report when down", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("queue", func(ctx context.Context) error { return errors.New("unreachable") })
+
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+		var report Report
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("expected JSON body, got %v", err)
+		}
+		if report.Checks["queue"].Status != StatusDown {
+			t.Errorf("expected queue check down, got %s", report.Checks["queue"].Status)
+		}
+	})
+}
+
+func TestThreshold(t *testing.T) {
+	probe := func(v float64) func(context.Context) (float64, error) {
+		return func(context.Context) (float64, error) { return v, nil }
+	}
+
+	t.Run("Below threshold passes", func(t *testing.T) {
+		if err := Threshold("lag", probe(5), 10)(context.Background()); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Above threshold fails", func(t *testing.T) {
+		if err := Threshold("lag", probe(11), 10)(context.Background()); err == nil {
+			t.Error("expected error above threshold")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+//go:embed status.html
+var statusHTML string
+
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) },
+}).Parse(statusHTML))
+
+// StatusHandler serves the public status page: it runs the checks so the
+// page is current, then renders the recorded history as HTML for browsers
+// and as JSON otherwise. It always responds 200; /readyz is the probe.
+// Check errors are left out, since the page is public.
+func (r *Registry) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Run(req.Context())
+		history := r.History()
+		if strings.Contains(req.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = statusPage.Execute(w, history)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(history)
+	})
+}
+
+// DetailHandler serves Detail as JSON for the admin API. It reports the
+// recorded history without running the checks.
+func (r *Registry) DetailHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.Detail())
+	})
+}
@@ -0,0 +1,23 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// Threshold turns a numeric probe (consumer lag, queue depth, pending
+// deliveries) into a check that fails once the value exceeds max. The
+// latest value is published under "<name>.value" in the readiness gauges.
+func Threshold(name string, probe func(ctx context.Context) (float64, error), max float64) CheckFunc {
+	return func(ctx context.Context) error {
+		v, err := probe(ctx)
+		if err != nil {
+			return err
+		}
+		setGauge(name+".value", v)
+		if v > max {
+			return fmt.Errorf("%s is %g, above threshold %g", name, v, max)
+		}
+		return nil
+	}
+}
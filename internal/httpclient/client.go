@@ -0,0 +1,87 @@
+// Package httpclient builds the *http.Client used for outbound integrations
+// so every caller gets the same timeouts, retry policy, proxy handling and
+// metrics instead of relying on http.DefaultClient.
+package httpclient
+
+import (
+	"expvar"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Defaults applied by New for zero-valued Options fields.
+const (
+	DefaultTimeout      = 10 * time.Second
+	DefaultDialTimeout  = 5 * time.Second
+	DefaultMaxRetries   = 2
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// clientMetrics is published at /debug/vars as "http_client". Keys are
+// "<name>.requests", "<name>.errors", "<name>.retries" and "<name>.duration_ns".
+var clientMetrics = expvar.NewMap("http_client")
+
+// Options configures New.
+type Options struct {
+	// Name labels the client's metrics, e.g. "webhooks" or "geoip".
+	Name string
+	// Timeout bounds the whole request, including retries.
+	Timeout time.Duration
+	// MaxRetries is how many times an idempotent request is retried. Use -1 to disable.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// retry. A longer Retry-After on a 429 or 503 is honored, unless it
+	// exceeds Timeout, in which case the response is returned as is.
+	RetryBackoff time.Duration
+	// Proxy selects the proxy per request. Nil uses the HTTP(S)_PROXY environment.
+	Proxy func(*http.Request) (*url.URL, error)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Name == "" {
+		o.Name = "default"
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = DefaultRetryBackoff
+	}
+	if o.Proxy == nil {
+		o.Proxy = http.ProxyFromEnvironment
+	}
+	return o
+}
+
+// New returns a client configured from opts.
+func New(opts Options) *http.Client {
+	opts = opts.withDefaults()
+	base := &http.Transport{
+		Proxy:                 opts.Proxy,
+		DialContext:           (&net.Dialer{Timeout: DefaultDialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   DefaultDialTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &transport{
+			next:    base,
+			name:    opts.Name,
+			retries: opts.MaxRetries,
+			backoff: opts.RetryBackoff,
+			maxWait: opts.Timeout,
+		},
+	}
+}
@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer answers 503 for the first failures requests, then 200.
+func flakyServer(failures int32) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return srv, &calls
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Run("Idempotent request is retried", func(t *testing.T) {
+		srv, calls := flakyServer(2)
+		defer srv.Close()
+		client := New(Options{Name: "test", RetryBackoff: time.Millisecond})
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200, got %d", resp.StatusCode)
+		}
+		if *calls != 3 {
+			t.Errorf("expected 3 calls, got %d", *calls)
+		}
+	})
+
+	t.Run("Replayable body is resent", func(t *testing.T) {
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer srv.Close()
+		client := New(Options{Name: "test", RetryBackoff: time.Millisecond})
+
+		req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if len(bodies) != 2 || bodies[1] != "payload" {
+			t.Errorf("expected payload resent on retry, got %q", bodies)
+		}
+	})
+
+	t.Run("Retries leave the caller's request alone", func(t *testing.T) {
+		srv, calls := flakyServer(1)
+		defer srv.Close()
+		rt := &transport{next: http.DefaultTransport, name: "test", retries: 2, backoff: time.Millisecond}
+
+		req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+		body := req.Body
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if *calls != 2 {
+			t.Errorf("expected 2 calls, got %d", *calls)
+		}
+		if req.Body != body {
+			t.Error("expected the request's body to be left in place")
+		}
+	})
+
+	t.Run("Non-idempotent request is not retried", func(t *testing.T) {
+		srv, calls := flakyServer(5)
+		defer srv.Close()
+		client := New(Options{Name: "test", RetryBackoff: time.Millisecond})
+
+		resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if *calls != 1 {
+			t.Errorf("expected 1 call, got %d", *calls)
+		}
+	})
+
+	t.Run("Retries can be disabled", func(t *testing.T) {
+		srv, calls := flakyServer(5)
+		defer srv.Close()
+		client := New(Options{Name: "test", MaxRetries: -1})
+
+		resp, _ := client.Get(srv.URL)
+		resp.Body.Close()
+		if *calls != 1 {
+			t.Errorf("expected 1 call, got %d", *calls)
+		}
+	})
+
+	t.Run("No retry the deadline can't wait for", func(t *testing.T) {
+		srv, calls := flakyServer(5)
+		defer srv.Close()
+		client := New(Options{Name: "test", RetryBackoff: time.Second})
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("expected the failed response, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || *calls != 1 {
+			t.Errorf("expected a single 503, got %d after %d calls", resp.StatusCode, *calls)
+		}
+	})
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	// throttled answers 429 with Retry-After once, then 200.
+	throttled := func(after string) (*httptest.Server, *int32) {
+		var calls int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", after)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		})), &calls
+	}
+
+	t.Run("Waits as long as asked", func(t *testing.T) {
+		srv, calls := throttled("1")
+		defer srv.Close()
+		client := New(Options{Name: "test", RetryBackoff: time.Millisecond})
+
+		start := time.Now()
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || *calls != 2 {
+			t.Errorf("expected 200 after 2 calls, got %d after %d", resp.StatusCode, *calls)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("expected to wait the second asked for, waited %s", elapsed)
+		}
+	})
+
+	t.Run("Doesn't wait past the timeout", func(t *testing.T) {
+		srv, calls := throttled("60")
+		defer srv.Close()
+		client := New(Options{Name: "test", Timeout: time.Second, RetryBackoff: time.Millisecond})
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected the throttled response, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || *calls != 1 {
+			t.Errorf("expected a single 429, got %d after %d calls", resp.StatusCode, *calls)
+		}
+	})
+}
+
+func TestClient_Timeout(t *testing.T) {
+	t.Run("Slow server times out", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer srv.Close()
+		client := New(Options{Name: "test", Timeout: 20 * time.Millisecond, MaxRetries: -1})
+
+		if _, err := client.Get(srv.URL); err == nil {
+			t.Error("expected timeout error")
+		}
+	})
+}
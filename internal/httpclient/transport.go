@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cleanarch/internal/deadline"
+)
+
+// transport retries idempotent requests and records metrics. It waits at
+// least as long as a 429 or 503 response's Retry-After asks, and doesn't
+// retry when the wait would outlast the request's deadline or maxWait.
+// Retries send a clone with a fresh body, leaving the caller's request as
+// it was.
+type transport struct {
+	next    http.RoundTripper
+	name    string
+	retries int
+	backoff time.Duration
+	// maxWait caps a single wait; zero means no cap.
+	maxWait time.Duration
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	clientMetrics.Add(t.name+".requests", 1)
+	defer func() { clientMetrics.Add(t.name+".duration_ns", int64(time.Since(start))) }()
+
+	backoff := t.backoff
+	r := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(r)
+		wait := backoff
+		if after, ok := retryAfter(resp); ok {
+			wait = max(wait, after)
+		}
+		if attempt >= t.retries || !retryable(req, resp, err) ||
+			(t.maxWait > 0 && wait > t.maxWait) || !deadline.Covers(req.Context(), wait) {
+			if err != nil {
+				clientMetrics.Add(t.name+".errors", 1)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		r = req.Clone(req.Context())
+		if req.Body != nil {
+			var gerr error
+			if r.Body, gerr = req.GetBody(); gerr != nil {
+				clientMetrics.Add(t.name+".errors", 1)
+				return nil, gerr
+			}
+		}
+		clientMetrics.Add(t.name+".retries", 1)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			clientMetrics.Add(t.name+".errors", 1)
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a failed attempt may be repeated: the method
+// must be idempotent, the body replayable, and the failure transient.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !idempotent(req.Method) {
+		return false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns how long a 429 or 503 response asks the client to
+// wait, given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
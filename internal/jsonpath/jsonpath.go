@@ -0,0 +1,35 @@
+// Package jsonpath resolves dotted paths such as "items.0.name" in JSON
+// documents decoded into any (maps, slices and scalars).
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Lookup returns the value at path in doc. An empty path returns doc itself.
+func Lookup(doc any, path string) (any, bool) {
+	if path == "" {
+		return doc, true
+	}
+	cur := doc
+	for _, part := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
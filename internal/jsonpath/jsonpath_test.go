@@ -0,0 +1,34 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"items": [{"name": "a"}, {"name": "b"}], "total": 2}`), &doc)
+
+	cases := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{"total", float64(2), true},
+		{"items.1.name", "b", true},
+		{"items.2.name", nil, false},
+		{"items.x", nil, false},
+		{"missing", nil, false},
+		{"total.deeper", nil, false},
+	}
+	for _, c := range cases {
+		got, ok := Lookup(doc, c.path)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("Lookup(%q) = %v, %v; expected %v, %v", c.path, got, ok, c.want, c.ok)
+		}
+	}
+
+	if got, ok := Lookup(doc, ""); !ok || got == nil {
+		t.Error("expected empty path to return the document")
+	}
+}
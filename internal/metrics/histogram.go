@@ -0,0 +1,194 @@
+// Package metrics provides expvar-compatible metric types missing from the
+// standard library. Values are served as JSON at /debug/vars.
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bucket boundaries shared by the service's histograms.
+var (
+	// LatencyBuckets are upper bounds in seconds.
+	LatencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+	// SizeBuckets are upper bounds in bytes.
+	SizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+)
+
+// ParseBuckets parses comma-separated bucket upper bounds such as
+// "0.005,0.01,0.1,1" and validates them with ValidateBuckets.
+func ParseBuckets(s string) ([]float64, error) {
+	fields := strings.Split(s, ",")
+	bounds := make([]float64, len(fields))
+	for i, f := range fields {
+		b, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket bound %q", strings.TrimSpace(f))
+		}
+		bounds[i] = b
+	}
+	if err := ValidateBuckets(bounds); err != nil {
+		return nil, err
+	}
+	return bounds, nil
+}
+
+// ValidateBuckets checks bounds follow the OpenMetrics histogram rules: at
+// least one bound, all finite and strictly increasing. The +Inf bucket is
+// always added and must not be listed.
+func ValidateBuckets(bounds []float64) error {
+	if len(bounds) == 0 {
+		return errors.New("no bucket bounds")
+	}
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("bucket bound %g is not finite", b)
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("bucket bounds must increase: %g follows %g", b, bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// FormatBuckets renders bounds in the format read by ParseBuckets.
+func FormatBuckets(bounds []float64) string {
+	fields := make([]string, len(bounds))
+	for i, b := range bounds {
+		fields[i] = strconv.FormatFloat(b, 'g', -1, 64)
+	}
+	return strings.Join(fields, ",")
+}
+
+// ValidatePrefix checks a metric name prefix. Each dot-separated segment must
+// be a valid OpenMetrics name ([a-zA-Z_][a-zA-Z0-9_]*), so the prefix stays
+// valid whether a backend joins segments with dots or underscores. An empty
+// prefix is allowed.
+func ValidatePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	for _, seg := range strings.Split(prefix, ".") {
+		if seg == "" {
+			return fmt.Errorf("empty segment in metric prefix %q", prefix)
+		}
+		for i, r := range seg {
+			letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_'
+			if !letter && (i == 0 || r < '0' || r > '9') {
+				return fmt.Errorf("invalid character %q in metric prefix %q", r, prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// Histogram counts observations into cumulative buckets with the given upper
+// bounds, Prometheus-style. It implements expvar.Var.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // counts[i] observations <= bounds[i]; last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(h.bounds)]++
+	h.sum += v
+	h.count++
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Sum returns the sum of all observations.
+func (h *Histogram) Sum() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// String renders the histogram as JSON for expvar.
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets := make(map[string]uint64, len(h.counts))
+	for i, b := range h.bounds {
+		buckets[strconv.FormatFloat(b, 'g', -1, 64)] = h.counts[i]
+	}
+	buckets["+Inf"] = h.counts[len(h.bounds)]
+	sum := h.sum
+	if math.IsInf(sum, 0) || math.IsNaN(sum) {
+		sum = 0
+	}
+	b, _ := json.Marshal(struct {
+		Buckets map[string]uint64 `json:"buckets"`
+		Sum     float64           `json:"sum"`
+		Count   uint64            `json:"count"`
+	}{buckets, sum, h.count})
+	return string(b)
+}
+
+// HistogramVec is a family of histograms sharing bucket bounds, keyed by a
+// label such as the route. It is published under its name in expvar.
+type HistogramVec struct {
+	mu     sync.Mutex
+	bounds []float64
+	vars   *expvar.Map
+}
+
+// NewHistogramVec publishes a new histogram family called name.
+func NewHistogramVec(name string, bounds []float64) *HistogramVec {
+	return &HistogramVec{bounds: bounds, vars: expvar.NewMap(name)}
+}
+
+// SetBounds replaces the family's bucket bounds. Histograms observed with
+// the old bounds are discarded, so it is meant to be called at startup;
+// setting the current bounds again is a no-op.
+func (v *HistogramVec) SetBounds(bounds []float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if slices.Equal(v.bounds, bounds) {
+		return
+	}
+	v.bounds = slices.Clone(bounds)
+	v.vars.Init()
+}
+
+// With returns the histogram for label, creating it on first use.
+func (v *HistogramVec) With(label string) *Histogram {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if h, ok := v.vars.Get(label).(*Histogram); ok {
+		return h
+	}
+	h := NewHistogram(v.bounds)
+	v.vars.Set(label, h)
+	return h
+}
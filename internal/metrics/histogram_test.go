@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	t.Run("Buckets are cumulative", func(t *testing.T) {
+		h := NewHistogram([]float64{1, 10})
+		h.Observe(0.5)
+		h.Observe(5)
+		h.Observe(50)
+
+		var out struct {
+			Buckets map[string]uint64 `json:"buckets"`
+			Sum     float64           `json:"sum"`
+			Count   uint64            `json:"count"`
+		}
+		if err := json.Unmarshal([]byte(h.String()), &out); err != nil {
+			t.Fatalf("expected valid JSON, got %v", err)
+		}
+		if out.Buckets["1"] != 1 || out.Buckets["10"] != 2 || out.Buckets["+Inf"] != 3 {
+			t.Errorf("unexpected buckets %v", out.Buckets)
+		}
+		if out.Sum != 55.5 {
+			t.Errorf("expected sum 55.5, got %g", out.Sum)
+		}
+		if out.Count != 3 {
+			t.Errorf("expected count 3, got %d", out.Count)
+		}
+	})
+}
+
+func TestHistogramVec(t *testing.T) {
+	t.Run("With returns the same histogram per label", func(t *testing.T) {
+		v := NewHistogramVec("test_histogram_vec", []float64{1})
+		v.With("a").Observe(1)
+		v.With("a").Observe(1)
+		v.With("b").Observe(1)
+
+		if got := v.With("a").Count(); got != 2 {
+			t.Errorf("expected 2 observations for a, got %d", got)
+		}
+		if got := v.With("b").Count(); got != 1 {
+			t.Errorf("expected 1 observation for b, got %d", got)
+		}
+	})
+}
+
+func TestHistogramVec_SetBounds(t *testing.T) {
+	t.Run("New bounds reset the family", func(t *testing.T) {
+		v := NewHistogramVec("test_histogram_vec_bounds", []float64{1})
+		v.With("a").Observe(1)
+		v.SetBounds([]float64{1, 2})
+
+		if got := v.With("a").Count(); got != 0 {
+			t.Errorf("expected observations to be discarded, got %d", got)
+		}
+		if got := v.With("a").String(); !strings.Contains(got, `"2":0`) {
+			t.Errorf("expected a bucket for 2, got %s", got)
+		}
+	})
+
+	t.Run("Same bounds keep observations", func(t *testing.T) {
+		v := NewHistogramVec("test_histogram_vec_same_bounds", []float64{1})
+		v.With("a").Observe(1)
+		v.SetBounds([]float64{1})
+
+		if got := v.With("a").Count(); got != 1 {
+			t.Errorf("expected 1 observation, got %d", got)
+		}
+	})
+}
+
+func TestParseBuckets(t *testing.T) {
+	t.Run("Parses increasing bounds", func(t *testing.T) {
+		got, err := ParseBuckets("0.005, 0.01,1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if FormatBuckets(got) != "0.005,0.01,1" {
+			t.Errorf("expected 0.005,0.01,1, got %s", FormatBuckets(got))
+		}
+	})
+
+	t.Run("Rejects invalid bounds", func(t *testing.T) {
+		for _, s := range []string{"", "0.1,abc", "1,1", "2,1", "0.1,+Inf", "NaN"} {
+			if _, err := ParseBuckets(s); err == nil {
+				t.Errorf("expected error for %q", s)
+			}
+		}
+	})
+}
+
+func TestValidatePrefix(t *testing.T) {
+	for _, p := range []string{"", "cleanarch", "team.users_api", "_x1"} {
+		if err := ValidatePrefix(p); err != nil {
+			t.Errorf("expected %q to be valid, got %v", p, err)
+		}
+	}
+	for _, p := range []string{"1users", "users-api", "users.", "a..b", "users:api"} {
+		if err := ValidatePrefix(p); err == nil {
+			t.Errorf("expected error for %q", p)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxPacketSize keeps StatsD datagrams within a typical 1500-byte MTU.
+const MaxPacketSize = 1432
+
+// pushMetrics is published at /debug/vars as "metrics_push".
+var pushMetrics = expvar.NewMap("metrics_push")
+
+// PushOptions configures a Pusher.
+type PushOptions struct {
+	// Addr is the StatsD (or DogStatsD) UDP address, e.g. "127.0.0.1:8125".
+	Addr string
+	// Interval between snapshots; defaults to 10s.
+	Interval time.Duration
+	// Prefix is prepended to every metric name, e.g. "cleanarch".
+	Prefix string
+	// QueueSize is how many packets may wait to be sent before new ones are
+	// dropped; defaults to 64.
+	QueueSize int
+}
+
+// Pusher periodically sends every numeric value published through expvar to
+// StatsD as gauges, so environments that don't scrape /debug/vars receive the
+// same metric set. Packets are batched up to MaxPacketSize and dropped rather
+// than queued without bound when the network can't keep up.
+type Pusher struct {
+	opts  PushOptions
+	conn  net.Conn
+	queue chan []byte
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewPusher(opts PushOptions) *Pusher {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 64
+	}
+	return &Pusher{opts: opts}
+}
+
+// Start dials the StatsD address and starts pushing in the background.
+func (p *Pusher) Start(ctx context.Context) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", p.opts.Addr)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.queue = make(chan []byte, p.opts.QueueSize)
+	p.stop = make(chan struct{})
+
+	p.wg.Add(2)
+	go p.send()
+	go func() {
+		defer p.wg.Done()
+		defer close(p.queue)
+		ticker := time.NewTicker(p.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.enqueue()
+			case <-p.stop:
+				p.enqueue() // final flush
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop pushes a final snapshot and waits for queued packets to be sent.
+func (p *Pusher) Stop(ctx context.Context) error {
+	close(p.stop)
+	done := make(chan struct{})
+	go func() { p.wg.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return p.conn.Close()
+}
+
+func (p *Pusher) enqueue() {
+	for _, packet := range Packets(p.opts.Prefix, Snapshot(), MaxPacketSize) {
+		select {
+		case p.queue <- packet:
+		default:
+			pushMetrics.Add("dropped_packets", 1)
+		}
+	}
+}
+
+func (p *Pusher) send() {
+	defer p.wg.Done()
+	for packet := range p.queue {
+		if _, err := p.conn.Write(packet); err != nil {
+			pushMetrics.Add("send_errors", 1)
+			log.Printf("metrics push: %v", err)
+			continue
+		}
+		pushMetrics.Add("sent_packets", 1)
+	}
+}
+
+// Snapshot flattens every numeric value published through expvar into
+// dot-separated names such as "user_repository.create.calls". Arrays and
+// non-numeric values are skipped.
+func Snapshot() map[string]float64 {
+	out := make(map[string]float64)
+	expvar.Do(func(kv expvar.KeyValue) {
+		var v any
+		if err := json.Unmarshal([]byte(kv.Value.String()), &v); err != nil {
+			return
+		}
+		flatten(out, sanitize(kv.Key), v)
+	})
+	return out
+}
+
+func flatten(out map[string]float64, name string, v any) {
+	switch v := v.(type) {
+	case float64:
+		out[name] = v
+	case bool:
+		if v {
+			out[name] = 1
+		} else {
+			out[name] = 0
+		}
+	case map[string]any:
+		for k, child := range v {
+			flatten(out, name+"."+sanitize(k), child)
+		}
+	}
+}
+
+// sanitize replaces characters StatsD treats as syntax (":", "|", "@", spaces
+// and the like) so labels such as routes can be used in metric names.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Packets renders snapshot as StatsD gauge lines ("name:value|g"), sorted by
+// name and packed into newline-separated datagrams of at most max bytes.
+func Packets(prefix string, snapshot map[string]float64, max int) [][]byte {
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var packets [][]byte
+	var cur []byte
+	for _, name := range names {
+		full := name
+		if prefix != "" {
+			full = prefix + "." + name
+		}
+		line := full + ":" + strconv.FormatFloat(snapshot[name], 'g', -1, 64) + "|g"
+		if len(line) > max {
+			continue
+		}
+		if len(cur) > 0 && len(cur)+1+len(line) > max {
+			packets = append(packets, cur)
+			cur = nil
+		}
+		if len(cur) > 0 {
+			cur = append(cur, '\n')
+		}
+		cur = append(cur, line...)
+	}
+	if len(cur) > 0 {
+		packets = append(packets, cur)
+	}
+	return packets
+}
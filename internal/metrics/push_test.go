@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPackets(t *testing.T) {
+	t.Run("Lines are sorted, prefixed and batched", func(t *testing.T) {
+		snapshot := map[string]float64{"b.count": 2, "a.count": 1, "c.sum": 0.5}
+
+		packets := Packets("svc", snapshot, 32)
+		if len(packets) != 2 {
+			t.Fatalf("expected 2 packets, got %d: %q", len(packets), packets)
+		}
+		if got := string(packets[0]); got != "svc.a.count:1|g\nsvc.b.count:2|g" {
+			t.Errorf("unexpected first packet %q", got)
+		}
+		if got := string(packets[1]); got != "svc.c.sum:0.5|g" {
+			t.Errorf("unexpected second packet %q", got)
+		}
+	})
+
+	t.Run("Oversized lines are skipped", func(t *testing.T) {
+		packets := Packets("", map[string]float64{strings.Repeat("x", 40): 1}, 32)
+		if len(packets) != 0 {
+			t.Errorf("expected no packets, got %q", packets)
+		}
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	m := expvar.NewMap("push_test")
+	m.Add("GET /users/{id}", 3)
+	NewHistogramVec("push_test_seconds", []float64{1}).With("route").Observe(0.5)
+
+	snapshot := Snapshot()
+	if got := snapshot["push_test.GET__users__id_"]; got != 3 {
+		t.Errorf("expected sanitized counter 3, got %v", got)
+	}
+	if got := snapshot["push_test_seconds.route.count"]; got != 1 {
+		t.Errorf("expected histogram count 1, got %v", got)
+	}
+	if _, ok := snapshot["cmdline"]; ok {
+		t.Error("expected non-numeric values to be skipped")
+	}
+}
+
+func TestPusher(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	expvar.NewInt("push_test_gauge").Set(42)
+
+	p := NewPusher(PushOptions{Addr: conn.LocalAddr().String(), Interval: time.Hour, Prefix: "svc"})
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var received strings.Builder
+	buf := make([]byte, MaxPacketSize)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		received.Write(buf[:n])
+		received.WriteByte('\n')
+	}
+	if !strings.Contains(received.String(), "svc.push_test_gauge:42|g") {
+		t.Errorf("expected final flush to include gauge, got %q", received.String())
+	}
+}
@@ -0,0 +1,111 @@
+// Package password hashes passwords for storage and checks them against
+// stored hashes.
+//
+// Hashes use PBKDF2-HMAC-SHA256 (RFC 8018) and are encoded in the PHC string
+// format, "$pbkdf2-sha256$i=<iterations>$<salt>$<key>" with unpadded
+// base64, so the algorithm and cost travel with each hash and can be raised
+// without invalidating stored ones.
+package password
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultIterations is the PBKDF2 cost for new hashes, OWASP's
+// recommendation for HMAC-SHA256.
+const DefaultIterations = 600_000
+
+const (
+	algorithm = "pbkdf2-sha256"
+	saltLen   = 16
+	keyLen    = 32
+)
+
+// ErrMalformedHash is returned for a stored hash that isn't in a format
+// this package produces.
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// PBKDF2 hashes passwords with PBKDF2-HMAC-SHA256. The zero value uses
+// DefaultIterations.
+type PBKDF2 struct {
+	Iterations int
+}
+
+// Hash returns the encoded hash of password under a fresh random salt.
+func (h PBKDF2) Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	iter := h.iterations()
+	dk, err := key([]byte(password), salt, iter, keyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$%s$i=%d$%s$%s", algorithm, iter,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(dk),
+	), nil
+}
+
+// Verify reports whether password matches the encoded hash, using the cost
+// recorded in the hash rather than h's.
+func (h PBKDF2) Verify(encoded, password string) (bool, error) {
+	iter, salt, want, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	got, err := key([]byte(password), salt, iter, len(want))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// NeedsRehash reports whether encoded was made with fewer iterations than h
+// uses now, so the password should be hashed again once it's known.
+func (h PBKDF2) NeedsRehash(encoded string) bool {
+	iter, _, _, err := decode(encoded)
+	return err != nil || iter < h.iterations()
+}
+
+func (h PBKDF2) iterations() int {
+	if h.Iterations <= 0 {
+		return DefaultIterations
+	}
+	return h.Iterations
+}
+
+func decode(encoded string) (iter int, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != algorithm {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	iter, err = strconv.Atoi(strings.TrimPrefix(parts[2], "i="))
+	if err != nil || iter <= 0 || !strings.HasPrefix(parts[2], "i=") {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	salt, err = base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(salt) == 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	return iter, salt, key, nil
+}
+
+// key derives a keyLen-byte key from password and salt with PBKDF2 over
+// HMAC-SHA256.
+func key(password, salt []byte, iter, keyLen int) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, string(password), salt, iter, keyLen)
+}
@@ -0,0 +1,75 @@
+package password
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vectors from RFC 7914, section 11.
+	tests := []struct {
+		password, salt string
+		iter           int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		dk, err := key([]byte(tt.password), []byte(tt.salt), tt.iter, len(tt.want)/2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := hex.EncodeToString(dk); got != tt.want {
+			t.Errorf("expected %s for %q, got %s", tt.want, tt.password, got)
+		}
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	h := PBKDF2{Iterations: 1000}
+
+	t.Run("Hash then verify", func(t *testing.T) {
+		encoded, err := h.Hash("correct horse")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !strings.HasPrefix(encoded, "$pbkdf2-sha256$i=1000$") {
+			t.Errorf("unexpected encoding %q", encoded)
+		}
+		if ok, err := h.Verify(encoded, "correct horse"); !ok || err != nil {
+			t.Errorf("expected a match, got %v, %v", ok, err)
+		}
+		if ok, _ := h.Verify(encoded, "battery staple"); ok {
+			t.Error("expected a mismatch for the wrong password")
+		}
+	})
+
+	t.Run("Salted", func(t *testing.T) {
+		a, _ := h.Hash("secret")
+		b, _ := h.Hash("secret")
+		if a == b {
+			t.Error("expected different hashes for the same password")
+		}
+	})
+
+	t.Run("Malformed hash", func(t *testing.T) {
+		for _, encoded := range []string{"", "secret", "$pbkdf2-sha256$1000$c2FsdA$a2V5", "$bcrypt$i=1$c2FsdA$a2V5", "$pbkdf2-sha256$i=0$c2FsdA$a2V5"} {
+			if _, err := h.Verify(encoded, "secret"); !errors.Is(err, ErrMalformedHash) {
+				t.Errorf("expected ErrMalformedHash for %q, got %v", encoded, err)
+			}
+		}
+	})
+
+	t.Run("Needs rehash", func(t *testing.T) {
+		encoded, _ := h.Hash("secret")
+		if h.NeedsRehash(encoded) {
+			t.Error("expected no rehash at the same cost")
+		}
+		if !(PBKDF2{Iterations: 2000}).NeedsRehash(encoded) {
+			t.Error("expected a rehash at a higher cost")
+		}
+	})
+}
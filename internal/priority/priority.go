@@ -0,0 +1,96 @@
+// Package priority classifies requests so overload protection can favour
+// admin and interactive traffic over batch work.
+package priority
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Class is a request's priority; higher classes are protected longer.
+type Class int
+
+const (
+	// Batch is expensive, retryable work such as listings and exports.
+	Batch Class = iota
+	// Interactive is single-resource traffic from users waiting on a response.
+	Interactive
+	// Admin is operator traffic, which must get through to fix an overload.
+	Admin
+)
+
+// Header lets clients lower (never raise) their request's class.
+const Header = "X-Priority"
+
+func (c Class) String() string {
+	switch c {
+	case Batch:
+		return "batch"
+	case Admin:
+		return "admin"
+	default:
+		return "interactive"
+	}
+}
+
+// Parse returns the class named s.
+func Parse(s string) (Class, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "batch":
+		return Batch, true
+	case "interactive":
+		return Interactive, true
+	case "admin":
+		return Admin, true
+	}
+	return Interactive, false
+}
+
+// Classify derives r's class from its route: /admin/ paths are Admin,
+// collection reads (listings, stats, view results) are Batch and everything
+// else is Interactive. A lower class requested via Header wins.
+func Classify(r *http.Request) Class {
+	c := Interactive
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case strings.HasPrefix(path, "/admin/"):
+		c = Admin
+	case r.Method == http.MethodGet && (strings.HasSuffix(path, "/users") || strings.HasSuffix(path, "/stats") ||
+		strings.HasSuffix(path, "/views") || strings.HasSuffix(path, "/results")):
+		c = Batch
+	}
+	if requested, ok := Parse(r.Header.Get(Header)); ok && requested < c {
+		c = requested
+	}
+	return c
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying c.
+func NewContext(ctx context.Context, c Class) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the class stored in ctx, if any.
+func FromContext(ctx context.Context) (Class, bool) {
+	c, ok := ctx.Value(contextKey{}).(Class)
+	return c, ok
+}
+
+// Of returns r's class from its context, classifying it if none was stored.
+func Of(r *http.Request) Class {
+	if c, ok := FromContext(r.Context()); ok {
+		return c
+	}
+	return Classify(r)
+}
+
+// Middleware classifies each request once and stores the class in its
+// context for downstream consumers.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), Classify(r))))
+	})
+}
@@ -0,0 +1,45 @@
+package priority
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		method, target, header string
+		want                   Class
+	}{
+		{"GET", "/api/v1/users", "", Batch},
+		{"GET", "/api/v1/users/stats", "", Batch},
+		{"GET", "/api/v1/views/1/results", "", Batch},
+		{"GET", "/api/v1/users/1", "", Interactive},
+		{"POST", "/api/v1/users", "", Interactive},
+		{"PUT", "/admin/read-only", "", Admin},
+		{"POST", "/api/v1/users", "batch", Batch},
+		{"GET", "/api/v1/users", "admin", Batch},
+		{"GET", "/admin/config", "interactive", Interactive},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.target, nil)
+		if tc.header != "" {
+			r.Header.Set(Header, tc.header)
+		}
+		if got := Classify(r); got != tc.want {
+			t.Errorf("%s %s (%q): expected %s, got %s", tc.method, tc.target, tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got Class
+	var ok bool
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = FromContext(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin/slo", nil))
+	if !ok || got != Admin {
+		t.Errorf("expected admin class in context, got %v (%v)", got, ok)
+	}
+}
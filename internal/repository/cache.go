@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// Cache stores users by ID for WithCache.
+type Cache interface {
+	Get(id int64) (*domain.User, bool)
+	Set(user *domain.User)
+	Delete(id int64)
+}
+
+// WithCache serves GetByID from c and keeps it in sync with writes that go
+// through the decorated repository. Dry-run writes aren't cached.
+func WithCache(c Cache) Decorator {
+	return func(next domain.UserRepository) domain.UserRepository {
+		return &cachedRepository{UserRepository: next, cache: c}
+	}
+}
+
+type cachedRepository struct {
+	domain.UserRepository
+	cache Cache
+}
+
+func (r *cachedRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	created, err := r.UserRepository.Create(ctx, user)
+	if err == nil && !domain.IsDryRun(ctx) {
+		r.cache.Set(created)
+	}
+	return created, err
+}
+
+func (r *cachedRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	if u, ok := r.cache.Get(id); ok {
+		return u, nil
+	}
+	u, err := r.UserRepository.GetByID(ctx, id)
+	if err == nil {
+		r.cache.Set(u)
+	}
+	return u, err
+}
+
+func (r *cachedRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
+	updated, err := r.UserRepository.Update(ctx, user)
+	if err != nil {
+		if user != nil {
+			r.cache.Delete(user.ID)
+		}
+		return nil, err
+	}
+	if !domain.IsDryRun(ctx) {
+		r.cache.Set(updated)
+	}
+	return updated, nil
+}
+
+func (r *cachedRepository) Delete(ctx context.Context, id int64, version int64) error {
+	r.cache.Delete(id)
+	return r.UserRepository.Delete(ctx, id, version)
+}
+
+// MemoryCache is a threadsafe TTL cache. It stores and returns copies so
+// callers can't mutate cached entries.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[int64]cacheEntry
+}
+
+type cacheEntry struct {
+	user      domain.User
+	expiresAt time.Time
+}
+
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[int64]cacheEntry),
+	}
+}
+
+func (c *MemoryCache) Get(id int64) (*domain.User, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[id]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	copy := e.user
+	copy.Tags = slices.Clone(e.user.Tags)
+	copy.Metadata = domain.CloneMetadata(e.user.Metadata)
+	copy.ExternalIDs = maps.Clone(e.user.ExternalIDs)
+	return &copy, true
+}
+
+func (c *MemoryCache) Set(user *domain.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := cacheEntry{user: *user, expiresAt: time.Now().Add(c.ttl)}
+	e.user.Tags = slices.Clone(user.Tags)
+	e.user.Metadata = domain.CloneMetadata(user.Metadata)
+	e.user.ExternalIDs = maps.Clone(user.ExternalIDs)
+	c.entries[user.ID] = e
+}
+
+func (c *MemoryCache) Delete(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleanarch/internal/deadline"
+	"cleanarch/internal/domain"
+)
+
+// WithDeadline bounds each repository call to fraction of the time left
+// before the caller's deadline (see deadline.Share), so a slow backend
+// fails the call while the request can still answer instead of running
+// past it. Calls without a deadline are unbounded.
+func WithDeadline(fraction float64) Decorator {
+	return func(next domain.UserRepository) domain.UserRepository {
+		return &deadlineRepository{next: next, fraction: fraction}
+	}
+}
+
+type deadlineRepository struct {
+	next     domain.UserRepository
+	fraction float64
+}
+
+func (r *deadlineRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.Create(ctx, user)
+}
+
+func (r *deadlineRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *deadlineRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.GetByEmail(ctx, email)
+}
+
+func (r *deadlineRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.SearchByNamePrefix(ctx, prefix, limit)
+}
+
+func (r *deadlineRepository) GetByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.GetByExternalID(ctx, provider, subject)
+}
+
+func (r *deadlineRepository) List(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.List(ctx, filter)
+}
+
+func (r *deadlineRepository) Count(ctx context.Context, filter domain.Filter) (int, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.Count(ctx, filter)
+}
+
+func (r *deadlineRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.Update(ctx, user)
+}
+
+func (r *deadlineRepository) Delete(ctx context.Context, id int64, version int64) error {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.Delete(ctx, id, version)
+}
+
+func (r *deadlineRepository) LastModified(ctx context.Context) (time.Time, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.LastModified(ctx)
+}
+
+func (r *deadlineRepository) Stats(ctx context.Context, q domain.StatsQuery) ([]domain.StatsBucket, error) {
+	ctx, cancel := deadline.Share(ctx, r.fraction)
+	defer cancel()
+	return r.next.Stats(ctx, q)
+}
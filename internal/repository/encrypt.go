@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// Cipher seals field values for storage. Encrypt must be deterministic so
+// that stored values can be matched by equality; see fieldcrypt.AESGCM.
+// Decrypt returns values it didn't seal unchanged.
+type Cipher interface {
+	Encrypt(plaintext string) string
+	Decrypt(value string) (string, error)
+}
+
+// WithFieldEncryption stores the sensitive fields encrypted with c, and
+// decrypts them on the way out, so the layers above see plaintext.
+//
+// Emails are lower-cased before they are sealed, so uniqueness, GetByEmail
+// and the email filter keep ignoring case; they come back lower-cased.
+// Sensitive metadata values are sealed as their JSON encoding. Orderings
+// and expressions that need the plaintext of an encrypted email (sorting by
+// email, filter expressions on email, statistics by email domain) are
+// rejected with domain.ErrInvalidFilter.
+func WithFieldEncryption(c Cipher, fields domain.SensitiveFields) Decorator {
+	return func(next domain.UserRepository) domain.UserRepository {
+		if fields.Empty() {
+			return next
+		}
+		return &encryptingRepository{next: next, fieldSealer: fieldSealer{cipher: c, fields: fields}}
+	}
+}
+
+type encryptingRepository struct {
+	next domain.UserRepository
+	fieldSealer
+}
+
+// fieldSealer encrypts and decrypts the sensitive fields of users.
+type fieldSealer struct {
+	cipher Cipher
+	fields domain.SensitiveFields
+}
+
+// seal returns a copy of u with the sensitive fields encrypted.
+func (r *fieldSealer) seal(u *domain.User) (*domain.User, error) {
+	if u == nil {
+		return nil, nil
+	}
+	sealed := *u
+	if r.fields.Email {
+		sealed.Email = r.sealEmail(u.Email)
+	}
+	if len(u.Metadata) > 0 && len(r.fields.Metadata) > 0 {
+		sealed.Metadata = domain.CloneMetadata(u.Metadata)
+		for key, v := range sealed.Metadata {
+			if !r.fields.MetadataKey(key) {
+				continue
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: metadata %q: %w", domain.ErrInvalidInput, key, err)
+			}
+			sealed.Metadata[key] = r.cipher.Encrypt(string(b))
+		}
+	}
+	return &sealed, nil
+}
+
+func (r *fieldSealer) sealEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	return r.cipher.Encrypt(strings.ToLower(email))
+}
+
+// open decrypts the sensitive fields of u in place; u is a copy the next
+// repository handed out.
+func (r *fieldSealer) open(u *domain.User) (*domain.User, error) {
+	if u == nil {
+		return nil, nil
+	}
+	if r.fields.Email {
+		email, err := r.cipher.Decrypt(u.Email)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting email of user %d: %w", u.ID, err)
+		}
+		u.Email = email
+	}
+	for key, v := range u.Metadata {
+		s, ok := v.(string)
+		if !ok || !r.fields.MetadataKey(key) {
+			continue
+		}
+		plain, err := r.cipher.Decrypt(s)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting metadata %q of user %d: %w", key, u.ID, err)
+		}
+		if plain == s {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(plain), &value); err != nil {
+			return nil, fmt.Errorf("decoding metadata %q of user %d: %w", key, u.ID, err)
+		}
+		u.Metadata[key] = value
+	}
+	return u, nil
+}
+
+func (r *fieldSealer) openAll(users []*domain.User) ([]*domain.User, error) {
+	for i, u := range users {
+		opened, err := r.open(u)
+		if err != nil {
+			return nil, err
+		}
+		users[i] = opened
+	}
+	return users, nil
+}
+
+func (r *encryptingRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	sealed, err := r.seal(user)
+	if err != nil {
+		return nil, err
+	}
+	created, err := r.next.Create(ctx, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(created)
+}
+
+func (r *encryptingRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	u, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(u)
+}
+
+func (r *encryptingRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if r.fields.Email {
+		email = r.sealEmail(email)
+	}
+	u, err := r.next.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(u)
+}
+
+func (r *encryptingRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	users, err := r.next.SearchByNamePrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(users)
+}
+
+func (r *encryptingRepository) GetByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	u, err := r.next.GetByExternalID(ctx, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(u)
+}
+
+// sealFilter rewrites the filter to match stored ciphertexts, rejecting
+// what can't be evaluated on them.
+func (r *encryptingRepository) sealFilter(filter domain.Filter) (domain.Filter, error) {
+	if !r.fields.Email {
+		return filter, nil
+	}
+	if filter.Sort.Field == domain.SortByEmail {
+		return filter, fmt.Errorf("%w: email is encrypted and can't be sorted by", domain.ErrInvalidFilter)
+	}
+	if filter.Expr != nil && references(filter.Expr, "email") {
+		return filter, fmt.Errorf("%w: email is encrypted and can't be used in filter expressions", domain.ErrInvalidFilter)
+	}
+	filter.EmailEq = r.sealEmail(filter.EmailEq)
+	return filter, nil
+}
+
+func (r *encryptingRepository) List(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	filter, err := r.sealFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	page, err := r.next.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.openAll(page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (r *encryptingRepository) Count(ctx context.Context, filter domain.Filter) (int, error) {
+	filter, err := r.sealFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	return r.next.Count(ctx, filter)
+}
+
+func (r *encryptingRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
+	sealed, err := r.seal(user)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := r.next.Update(ctx, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(updated)
+}
+
+func (r *encryptingRepository) Delete(ctx context.Context, id int64, version int64) error {
+	return r.next.Delete(ctx, id, version)
+}
+
+func (r *encryptingRepository) LastModified(ctx context.Context) (time.Time, error) {
+	return r.next.LastModified(ctx)
+}
+
+func (r *encryptingRepository) Stats(ctx context.Context, q domain.StatsQuery) ([]domain.StatsBucket, error) {
+	if r.fields.Email && q.Normalize().GroupBy == domain.GroupByEmailDomain {
+		return nil, fmt.Errorf("%w: email is encrypted and can't be grouped by domain", domain.ErrInvalidFilter)
+	}
+	return r.next.Stats(ctx, q)
+}
+
+// WithAuditEncryption stores the sensitive fields of audit snapshots
+// encrypted with c, as WithFieldEncryption does for users, and decrypts
+// them on the way out.
+func WithAuditEncryption(c Cipher, fields domain.SensitiveFields) func(domain.AuditRepository) domain.AuditRepository {
+	return func(next domain.AuditRepository) domain.AuditRepository {
+		if fields.Empty() {
+			return next
+		}
+		return &encryptingAuditRepository{next: next, fieldSealer: fieldSealer{cipher: c, fields: fields}}
+	}
+}
+
+type encryptingAuditRepository struct {
+	next domain.AuditRepository
+	fieldSealer
+}
+
+// openEntry decrypts the snapshots of e in place; e is a copy the next
+// repository handed out.
+func (r *encryptingAuditRepository) openEntry(e *domain.AuditEntry) (*domain.AuditEntry, error) {
+	var err error
+	if e.Before, err = r.open(e.Before); err != nil {
+		return nil, err
+	}
+	if e.After, err = r.open(e.After); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *encryptingAuditRepository) Append(ctx context.Context, e *domain.AuditEntry) (*domain.AuditEntry, error) {
+	if e == nil {
+		return r.next.Append(ctx, e)
+	}
+	sealed := *e
+	var err error
+	if sealed.Before, err = r.seal(e.Before); err != nil {
+		return nil, err
+	}
+	if sealed.After, err = r.seal(e.After); err != nil {
+		return nil, err
+	}
+	appended, err := r.next.Append(ctx, &sealed)
+	if err != nil {
+		return nil, err
+	}
+	return r.openEntry(appended)
+}
+
+func (r *encryptingAuditRepository) List(ctx context.Context, q domain.AuditQuery) (*domain.Page[domain.AuditEntry], error) {
+	page, err := r.next.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range page.Items {
+		if _, err := r.openEntry(e); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// Redact hands fn each snapshot decrypted and stores what fn leaves
+// sealed again. The first snapshot that fails to decrypt or seal is
+// reported once the others are done.
+func (r *encryptingAuditRepository) Redact(ctx context.Context, entityID int64, fn func(*domain.User)) error {
+	var firstErr error
+	err := r.next.Redact(ctx, entityID, func(u *domain.User) {
+		if _, err := r.open(u); err != nil {
+			firstErr = cmp.Or(firstErr, err)
+			return
+		}
+		fn(u)
+		sealed, err := r.seal(u)
+		if err != nil {
+			firstErr = cmp.Or(firstErr, err)
+			return
+		}
+		*u = *sealed
+	})
+	return cmp.Or(err, firstErr)
+}
+
+// references reports whether the expression compares field.
+func references(e domain.Expr, field string) bool {
+	switch e := e.(type) {
+	case domain.And:
+		return references(e.Left, field) || references(e.Right, field)
+	case domain.Or:
+		return references(e.Left, field) || references(e.Right, field)
+	case domain.Not:
+		return references(e.X, field)
+	case domain.Comparison:
+		return e.Field == field
+	}
+	return false
+}
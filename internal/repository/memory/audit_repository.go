@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// InMemoryAuditRepository is a threadsafe, append-only in-memory
+// implementation of AuditRepository.
+type InMemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry // in ID order
+}
+
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+func (r *InMemoryAuditRepository) Append(ctx context.Context, e *domain.AuditEntry) (*domain.AuditEntry, error) {
+	if e == nil {
+		return nil, fmt.Errorf("%w: nil audit entry", domain.ErrInvalidInput)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copy := copyAuditEntry(e)
+	copy.ID = int64(len(r.entries)) + 1
+	if copy.At.IsZero() {
+		copy.At = time.Now().UTC()
+	}
+	if domain.IsDryRun(ctx) {
+		return copy, nil
+	}
+	r.entries = append(r.entries, *copy)
+	return copyAuditEntry(copy), nil
+}
+
+// List walks the log backwards from the cursor, so entries come newest
+// first.
+func (r *InMemoryAuditRepository) List(ctx context.Context, q domain.AuditQuery) (*domain.Page[domain.AuditEntry], error) {
+	var before int64
+	if q.Cursor != "" {
+		id, err := q.DecodeCursor()
+		if err != nil {
+			return nil, err
+		}
+		before = id
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	page := &domain.Page[domain.AuditEntry]{Items: []*domain.AuditEntry{}}
+	skip := q.Offset
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if q.EntityID != 0 && e.EntityID != q.EntityID {
+			continue
+		}
+		page.Total++
+		if before != 0 && e.ID >= before {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if q.Limit > 0 && len(page.Items) == q.Limit {
+			page.NextCursor = domain.AuditCursor(page.Items[len(page.Items)-1])
+			continue
+		}
+		page.Items = append(page.Items, copyAuditEntry(&e))
+	}
+	return page, nil
+}
+
+// Redact rewrites the stored snapshots in place; dry runs change nothing.
+func (r *InMemoryAuditRepository) Redact(ctx context.Context, entityID int64, fn func(*domain.User)) error {
+	if domain.IsDryRun(ctx) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.entries {
+		e := &r.entries[i]
+		if e.EntityID != entityID {
+			continue
+		}
+		if e.Before != nil {
+			fn(e.Before)
+		}
+		if e.After != nil {
+			fn(e.After)
+		}
+	}
+	return nil
+}
+
+// copyAuditEntry copies e including the users it points to, so callers
+// can't reach stored state.
+func copyAuditEntry(e *domain.AuditEntry) *domain.AuditEntry {
+	copy := *e
+	if e.Before != nil {
+		copy.Before = copyUser(e.Before)
+	}
+	if e.After != nil {
+		copy.After = copyUser(e.After)
+	}
+	return &copy
+}
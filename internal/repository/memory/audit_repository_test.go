@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestInMemoryAuditRepository(t *testing.T) {
+	seed := func() *InMemoryAuditRepository {
+		repo := NewInMemoryAuditRepository()
+		for _, id := range []int64{1, 2, 1, 1} {
+			_, _ = repo.Append(context.Background(), &domain.AuditEntry{Action: domain.AuditUpdate, EntityID: id})
+		}
+		return repo
+	}
+
+	t.Run("Newest first", func(t *testing.T) {
+		page, err := seed().List(context.Background(), domain.AuditQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page.Total != 4 || len(page.Items) != 4 || page.Items[0].ID != 4 || page.Items[3].ID != 1 {
+			t.Errorf("expected entries 4 to 1, got %+v", page)
+		}
+		if page.Items[0].At.IsZero() {
+			t.Error("expected a timestamp")
+		}
+	})
+
+	t.Run("Entity filter with limit and cursor", func(t *testing.T) {
+		repo := seed()
+		q := domain.AuditQuery{EntityID: 1, PageRequest: domain.PageRequest{Limit: 2}}
+		first, _ := repo.List(context.Background(), q)
+		if first.Total != 3 || len(first.Items) != 2 || first.Items[0].ID != 4 || first.Items[1].ID != 3 {
+			t.Fatalf("expected entries 4 and 3 of 3, got %+v", first)
+		}
+		if first.NextCursor != domain.AuditCursor(first.Items[1]) {
+			t.Errorf("expected a cursor after entry 3, got %q", first.NextCursor)
+		}
+
+		q.Cursor = first.NextCursor
+		second, _ := repo.List(context.Background(), q)
+		if len(second.Items) != 1 || second.Items[0].ID != 1 || second.NextCursor != "" {
+			t.Errorf("expected only entry 1 on the last page, got %+v", second)
+		}
+	})
+
+	t.Run("Users are copied", func(t *testing.T) {
+		repo := NewInMemoryAuditRepository()
+		before := &domain.User{ID: 1, Name: "Ann"}
+		_, _ = repo.Append(context.Background(), &domain.AuditEntry{Action: domain.AuditDelete, EntityID: 1, Before: before})
+		before.Name = "Bob"
+
+		page, _ := repo.List(context.Background(), domain.AuditQuery{})
+		page.Items[0].Before.Name = "Carol"
+		page, _ = repo.List(context.Background(), domain.AuditQuery{})
+		if page.Items[0].Before.Name != "Ann" {
+			t.Errorf("expected the stored entry to be unchanged, got %q", page.Items[0].Before.Name)
+		}
+	})
+
+	t.Run("Redact rewrites only the entity's snapshots", func(t *testing.T) {
+		repo := NewInMemoryAuditRepository()
+		ann := &domain.User{ID: 1, Name: "Ann"}
+		bob := &domain.User{ID: 2, Name: "Bob"}
+		_, _ = repo.Append(context.Background(), &domain.AuditEntry{Action: domain.AuditUpdate, EntityID: 1, Before: ann, After: ann})
+		_, _ = repo.Append(context.Background(), &domain.AuditEntry{Action: domain.AuditCreate, EntityID: 2, After: bob})
+
+		if err := repo.Redact(context.Background(), 1, func(u *domain.User) { u.Name = "x" }); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		page, _ := repo.List(context.Background(), domain.AuditQuery{})
+		if page.Items[1].Before.Name != "x" || page.Items[1].After.Name != "x" {
+			t.Errorf("expected entity 1 to be redacted, got %+v", page.Items[1])
+		}
+		if page.Items[0].After.Name != "Bob" {
+			t.Errorf("expected entity 2 to be unchanged, got %q", page.Items[0].After.Name)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// InMemoryCredentialRepository is a threadsafe in-memory implementation of
+// CredentialRepository.
+type InMemoryCredentialRepository struct {
+	*Store[domain.Credential, int64]
+}
+
+func NewInMemoryCredentialRepository() *InMemoryCredentialRepository {
+	return &InMemoryCredentialRepository{Store: NewStore[domain.Credential, int64](StoreOptions[domain.Credential]{
+		NotFound: domain.ErrCredentialNotFound,
+		Less:     func(a, b *domain.Credential) bool { return a.UserID < b.UserID },
+	})}
+}
+
+func (r *InMemoryCredentialRepository) Put(ctx context.Context, credential *domain.Credential) (*domain.Credential, error) {
+	if credential == nil {
+		return nil, fmt.Errorf("%w: nil credential", domain.ErrInvalidInput)
+	}
+	copy := *credential
+	copy.UpdatedAt = time.Now().UTC()
+	return r.Store.Put(ctx, copy.UserID, &copy), nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// InMemoryOrganizationRepository is a threadsafe in-memory implementation of
+// OrganizationRepository.
+type InMemoryOrganizationRepository struct {
+	*Store[domain.Organization, int64]
+
+	// mu guards the ID sequence and memberships, and is held across
+	// changes to the store so an organization and its members change
+	// together.
+	mu        sync.RWMutex
+	autoIncID int64
+	members   map[int64]map[int64]struct{} // org ID -> member user IDs
+}
+
+func NewInMemoryOrganizationRepository() *InMemoryOrganizationRepository {
+	return &InMemoryOrganizationRepository{
+		Store: NewStore[domain.Organization, int64](StoreOptions[domain.Organization]{
+			NotFound: domain.ErrOrgNotFound,
+			Less:     func(a, b *domain.Organization) bool { return a.ID < b.ID },
+		}),
+		members: make(map[int64]map[int64]struct{}),
+	}
+}
+
+func (r *InMemoryOrganizationRepository) Create(ctx context.Context, org *domain.Organization) (*domain.Organization, error) {
+	if org == nil {
+		return nil, fmt.Errorf("%w: nil organization", domain.ErrInvalidInput)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *org
+	copy.ID = r.autoIncID + 1
+	copy.CreatedAt = time.Now().UTC()
+	if !domain.IsDryRun(ctx) {
+		r.autoIncID++
+		r.members[copy.ID] = make(map[int64]struct{})
+	}
+	return r.Put(ctx, copy.ID, &copy), nil
+}
+
+func (r *InMemoryOrganizationRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	if !domain.IsDryRun(ctx) {
+		delete(r.members, id)
+	}
+	return nil
+}
+
+func (r *InMemoryOrganizationRepository) AddMember(ctx context.Context, orgID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	members, ok := r.members[orgID]
+	if !ok {
+		return domain.ErrOrgNotFound
+	}
+	if _, ok := members[userID]; ok {
+		return domain.ErrAlreadyMember
+	}
+	if !domain.IsDryRun(ctx) {
+		members[userID] = struct{}{}
+	}
+	return nil
+}
+
+func (r *InMemoryOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	members, ok := r.members[orgID]
+	if !ok {
+		return domain.ErrOrgNotFound
+	}
+	if _, ok := members[userID]; !ok {
+		return domain.ErrMemberNotFound
+	}
+	if !domain.IsDryRun(ctx) {
+		delete(members, userID)
+	}
+	return nil
+}
+
+func (r *InMemoryOrganizationRepository) Members(ctx context.Context, orgID int64) ([]int64, error) {
+	r.mu.RLock()
+	members, ok := r.members[orgID]
+	if !ok {
+		r.mu.RUnlock()
+		return nil, domain.ErrOrgNotFound
+	}
+	ids := make([]int64, 0, len(members))
+	for id := range members {
+		ids = append(ids, id)
+	}
+	r.mu.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (r *InMemoryOrganizationRepository) Memberships(ctx context.Context, userID int64) ([]int64, error) {
+	r.mu.RLock()
+	var ids []int64
+	for orgID, members := range r.members {
+		if _, ok := members[userID]; ok {
+			ids = append(ids, orgID)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (r *InMemoryOrganizationRepository) RemoveUser(ctx context.Context, userID int64) error {
+	if domain.IsDryRun(ctx) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, members := range r.members {
+		delete(members, userID)
+	}
+	return nil
+}
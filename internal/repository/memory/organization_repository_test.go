@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestInMemoryOrganizationRepository(t *testing.T) {
+	t.Run("Create, list and delete", func(t *testing.T) {
+		repo := NewInMemoryOrganizationRepository()
+		a, err := repo.Create(context.Background(), &domain.Organization{Name: "Corp"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		b, _ := repo.Create(context.Background(), &domain.Organization{Name: "Labs"})
+		if a.ID == 0 || b.ID <= a.ID || a.CreatedAt.IsZero() {
+			t.Errorf("expected increasing IDs and a creation time, got %+v %+v", a, b)
+		}
+
+		orgs, _ := repo.List(context.Background())
+		if len(orgs) != 2 || orgs[0].ID != a.ID {
+			t.Errorf("expected organizations in ID order, got %v", orgs)
+		}
+		if err := repo.Delete(context.Background(), a.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.GetByID(context.Background(), a.ID); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected ErrOrgNotFound, got %v", err)
+		}
+		if _, err := repo.Members(context.Background(), a.ID); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected memberships to go with the organization, got %v", err)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		repo := NewInMemoryOrganizationRepository()
+		corp, _ := repo.Create(context.Background(), &domain.Organization{Name: "Corp"})
+		labs, _ := repo.Create(context.Background(), &domain.Organization{Name: "Labs"})
+		for _, id := range []int64{3, 1, 2} {
+			if err := repo.AddMember(context.Background(), corp.ID, id); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		_ = repo.AddMember(context.Background(), labs.ID, 2)
+
+		if err := repo.AddMember(context.Background(), corp.ID, 1); !errors.Is(err, domain.ErrAlreadyMember) {
+			t.Errorf("expected ErrAlreadyMember, got %v", err)
+		}
+		if err := repo.AddMember(context.Background(), 99, 1); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected ErrOrgNotFound, got %v", err)
+		}
+		if ids, _ := repo.Members(context.Background(), corp.ID); len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+			t.Errorf("expected members 1, 2, 3, got %v", ids)
+		}
+
+		if err := repo.RemoveMember(context.Background(), corp.ID, 3); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := repo.RemoveMember(context.Background(), corp.ID, 3); !errors.Is(err, domain.ErrMemberNotFound) {
+			t.Errorf("expected ErrMemberNotFound, got %v", err)
+		}
+
+		if ids, _ := repo.Memberships(context.Background(), 2); len(ids) != 2 || ids[0] != corp.ID || ids[1] != labs.ID {
+			t.Errorf("expected user 2 in corp and labs, got %v", ids)
+		}
+		_ = repo.RemoveUser(context.Background(), 2)
+		if ids, _ := repo.Memberships(context.Background(), 2); len(ids) != 0 {
+			t.Errorf("expected no memberships, got %v", ids)
+		}
+		if ids, _ := repo.Members(context.Background(), corp.ID); len(ids) != 1 || ids[0] != 1 {
+			t.Errorf("expected only member 1 in corp, got %v", ids)
+		}
+		if ids, _ := repo.Members(context.Background(), labs.ID); len(ids) != 0 {
+			t.Errorf("expected labs to be empty, got %v", ids)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// InMemoryProfileRepository is a threadsafe in-memory implementation of
+// ProfileRepository.
+type InMemoryProfileRepository struct {
+	*Store[domain.Profile, int64]
+}
+
+func NewInMemoryProfileRepository() *InMemoryProfileRepository {
+	return &InMemoryProfileRepository{Store: NewStore[domain.Profile, int64](StoreOptions[domain.Profile]{
+		NotFound: domain.ErrProfileNotFound,
+		Less:     func(a, b *domain.Profile) bool { return a.UserID < b.UserID },
+		Clone:    copyProfile,
+	})}
+}
+
+func (r *InMemoryProfileRepository) Put(ctx context.Context, profile *domain.Profile) (*domain.Profile, error) {
+	if profile == nil {
+		return nil, fmt.Errorf("%w: nil profile", domain.ErrInvalidInput)
+	}
+	copy := *profile
+	copy.UpdatedAt = time.Now().UTC()
+	return r.Store.Put(ctx, copy.UserID, &copy), nil
+}
+
+// copyProfile copies p including its addresses, so callers can't reach
+// stored state.
+func copyProfile(p *domain.Profile) *domain.Profile {
+	copy := *p
+	copy.Addresses = append(make([]domain.Address, 0, len(p.Addresses)), p.Addresses...)
+	return &copy
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestInMemoryProfileRepository(t *testing.T) {
+	t.Run("Put, get and delete", func(t *testing.T) {
+		repo := NewInMemoryProfileRepository()
+		if _, err := repo.GetByID(context.Background(), 1); !errors.Is(err, domain.ErrProfileNotFound) {
+			t.Errorf("expected ErrProfileNotFound, got %v", err)
+		}
+		stored, err := repo.Put(context.Background(), &domain.Profile{UserID: 1, Bio: "Hi"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if stored.UpdatedAt.IsZero() {
+			t.Error("expected an update time")
+		}
+		_, _ = repo.Put(context.Background(), &domain.Profile{UserID: 1, Bio: "Hello"})
+		if got, _ := repo.GetByID(context.Background(), 1); got.Bio != "Hello" {
+			t.Errorf("expected the profile to be replaced, got %+v", got)
+		}
+		if err := repo.Delete(context.Background(), 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := repo.Delete(context.Background(), 1); !errors.Is(err, domain.ErrProfileNotFound) {
+			t.Errorf("expected ErrProfileNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Addresses are copied", func(t *testing.T) {
+		repo := NewInMemoryProfileRepository()
+		addresses := []domain.Address{{Line1: "1 Main St", City: "Springfield", Country: "US"}}
+		_, _ = repo.Put(context.Background(), &domain.Profile{UserID: 1, Addresses: addresses})
+		addresses[0].City = "Shelbyville"
+
+		got, _ := repo.GetByID(context.Background(), 1)
+		got.Addresses[0].Line1 = "2 Side St"
+		if again, _ := repo.GetByID(context.Background(), 1); again.Addresses[0].City != "Springfield" || again.Addresses[0].Line1 != "1 Main St" {
+			t.Errorf("expected stored addresses to be unaffected, got %+v", again.Addresses)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// StoreOptions configures a Store.
+type StoreOptions[T any] struct {
+	// NotFound is returned for IDs that aren't stored.
+	NotFound error
+	// Less orders List. Without it the order is unspecified.
+	Less func(a, b *T) bool
+	// Clone copies values into and out of the store. The default is a
+	// shallow copy, which is enough for values without slices or maps.
+	Clone func(*T) *T
+}
+
+// Store is a threadsafe map of values by ID that memory repositories build
+// on. It implements domain.Repository[T, ID]; repositories add the writes
+// their port needs on top of Put.
+type Store[T any, ID comparable] struct {
+	mu    sync.RWMutex
+	items map[ID]*T
+	opts  StoreOptions[T]
+}
+
+func NewStore[T any, ID comparable](opts StoreOptions[T]) *Store[T, ID] {
+	if opts.Clone == nil {
+		opts.Clone = func(v *T) *T {
+			copy := *v
+			return &copy
+		}
+	}
+	return &Store[T, ID]{items: make(map[ID]*T), opts: opts}
+}
+
+func (s *Store[T, ID]) GetByID(ctx context.Context, id ID) (*T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.items[id]
+	if !ok {
+		return nil, s.opts.NotFound
+	}
+	return s.opts.Clone(v), nil
+}
+
+func (s *Store[T, ID]) List(ctx context.Context) ([]*T, error) {
+	s.mu.RLock()
+	result := make([]*T, 0, len(s.items))
+	for _, v := range s.items {
+		result = append(result, s.opts.Clone(v))
+	}
+	s.mu.RUnlock()
+	if s.opts.Less != nil {
+		sort.Slice(result, func(i, j int) bool { return s.opts.Less(result[i], result[j]) })
+	}
+	return result, nil
+}
+
+func (s *Store[T, ID]) Delete(ctx context.Context, id ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[id]; !ok {
+		return s.opts.NotFound
+	}
+	if !domain.IsDryRun(ctx) {
+		delete(s.items, id)
+	}
+	return nil
+}
+
+// Put stores a copy of v under id, replacing any previous value, and
+// returns another copy. In a dry run it only returns the copy.
+func (s *Store[T, ID]) Put(ctx context.Context, id ID, v *T) *T {
+	stored := s.opts.Clone(v)
+	if !domain.IsDryRun(ctx) {
+		s.mu.Lock()
+		s.items[id] = stored
+		s.mu.Unlock()
+	}
+	return s.opts.Clone(stored)
+}
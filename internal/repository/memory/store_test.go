@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type item struct {
+	ID   int64
+	Tags []string
+}
+
+var errItemNotFound = errors.New("item not found")
+
+func newItemStore() *Store[item, int64] {
+	return NewStore[item, int64](StoreOptions[item]{
+		NotFound: errItemNotFound,
+		Less:     func(a, b *item) bool { return a.ID < b.ID },
+		Clone: func(v *item) *item {
+			copy := *v
+			copy.Tags = append([]string(nil), v.Tags...)
+			return &copy
+		},
+	})
+}
+
+func TestStore(t *testing.T) {
+	t.Run("Put, get, list and delete", func(t *testing.T) {
+		s := newItemStore()
+		s.Put(context.Background(), 2, &item{ID: 2})
+		s.Put(context.Background(), 1, &item{ID: 1})
+
+		got, err := s.GetByID(context.Background(), 1)
+		if err != nil || got.ID != 1 {
+			t.Fatalf("expected item 1, got %+v, %v", got, err)
+		}
+		items, _ := s.List(context.Background())
+		if len(items) != 2 || items[0].ID != 1 || items[1].ID != 2 {
+			t.Errorf("expected items in ID order, got %v", items)
+		}
+		if err := s.Delete(context.Background(), 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := s.GetByID(context.Background(), 1); !errors.Is(err, errItemNotFound) {
+			t.Errorf("expected errItemNotFound, got %v", err)
+		}
+		if err := s.Delete(context.Background(), 1); !errors.Is(err, errItemNotFound) {
+			t.Errorf("expected errItemNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Values are copied in and out", func(t *testing.T) {
+		s := newItemStore()
+		in := &item{ID: 1, Tags: []string{"a"}}
+		out := s.Put(context.Background(), 1, in)
+		in.Tags[0] = "changed"
+		out.Tags[0] = "changed"
+
+		got, _ := s.GetByID(context.Background(), 1)
+		if got.Tags[0] != "a" {
+			t.Errorf("expected stored tags to be unaffected, got %v", got.Tags)
+		}
+	})
+}
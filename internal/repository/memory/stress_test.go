@@ -0,0 +1,148 @@
+//go:build stress
+
+package memory
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// Run with: go test -tags stress -run Stress ./internal/repository/memory -stress.duration=1m
+var (
+	stressDuration   = flag.Duration("stress.duration", 10*time.Second, "how long the stress test runs")
+	stressGoroutines = flag.Int("stress.goroutines", 64, "number of concurrent workers")
+	stressWritePct   = flag.Int("stress.write-pct", 50, "percentage of operations that mutate (0-100)")
+)
+
+// stressWorker owns the users it creates, so it knows exactly what each of
+// them must look like and can detect lost or foreign updates.
+type stressWorker struct {
+	id    int
+	rnd   *rand.Rand
+	owned map[int64]string // user ID -> last written name
+	ops   int
+}
+
+func TestStress_InMemoryUserRepository(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	var seen sync.Map // user ID -> creating worker
+	var failures atomic.Int64
+	fail := func(format string, args ...any) {
+		if failures.Add(1) <= 20 {
+			t.Errorf(format, args...)
+		}
+	}
+
+	deadline := time.Now().Add(*stressDuration)
+	workers := make([]*stressWorker, *stressGoroutines)
+	var wg sync.WaitGroup
+	for i := range workers {
+		w := &stressWorker{id: i, rnd: rand.New(rand.NewSource(int64(i))), owned: make(map[int64]string)}
+		workers[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) && failures.Load() == 0 {
+				w.step(repo, &seen, fail)
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, w := range workers {
+		total += w.ops
+		for id, name := range w.owned {
+			u, err := repo.GetByID(context.Background(), id)
+			if err != nil {
+				fail("worker %d: user %d lost: %v", w.id, id, err)
+				continue
+			}
+			if u.Name != name {
+				fail("worker %d: user %d has name %q, last write was %q", w.id, id, u.Name, name)
+			}
+		}
+	}
+	t.Logf("%d operations across %d workers in %s", total, len(workers), *stressDuration)
+}
+
+func (w *stressWorker) step(repo *InMemoryUserRepository, seen *sync.Map, fail func(string, ...any)) {
+	w.ops++
+	if w.rnd.Intn(100) >= *stressWritePct {
+		w.read(repo, fail)
+		return
+	}
+	switch op := w.rnd.Intn(3); {
+	case op == 0 || len(w.owned) == 0:
+		name := fmt.Sprintf("w%d-%d", w.id, w.ops)
+		u, err := repo.Create(context.Background(), &domain.User{Name: name, Email: name + "@example.com"})
+		if err != nil {
+			fail("worker %d: create: %v", w.id, err)
+			return
+		}
+		if prev, dup := seen.LoadOrStore(u.ID, w.id); dup {
+			fail("worker %d: duplicate ID %d, first created by worker %v", w.id, u.ID, prev)
+		}
+		w.owned[u.ID] = name
+	case op == 1:
+		id := w.pick()
+		name := fmt.Sprintf("w%d-%d", w.id, w.ops)
+		u, err := repo.Update(context.Background(), &domain.User{ID: id, Name: name, Email: name + "@example.com"})
+		if err != nil {
+			fail("worker %d: update %d: %v", w.id, id, err)
+			return
+		}
+		if u.Name != name {
+			fail("worker %d: update %d returned name %q, wrote %q", w.id, id, u.Name, name)
+		}
+		w.owned[id] = name
+	default:
+		id := w.pick()
+		if err := repo.Delete(context.Background(), id, 0); err != nil {
+			fail("worker %d: delete %d: %v", w.id, id, err)
+			return
+		}
+		delete(w.owned, id)
+		if _, err := repo.GetByID(context.Background(), id); err == nil {
+			fail("worker %d: user %d still readable after delete", w.id, id)
+		}
+	}
+}
+
+func (w *stressWorker) read(repo *InMemoryUserRepository, fail func(string, ...any)) {
+	if len(w.owned) == 0 || w.rnd.Intn(10) == 0 {
+		if _, err := repo.List(context.Background(), domain.Filter{PageRequest: domain.PageRequest{Limit: 50}}); err != nil {
+			fail("worker %d: list: %v", w.id, err)
+		}
+		return
+	}
+	id := w.pick()
+	u, err := repo.GetByID(context.Background(), id)
+	if err != nil {
+		fail("worker %d: get %d: %v", w.id, id, err)
+		return
+	}
+	if u.Name != w.owned[id] {
+		fail("worker %d: user %d has name %q, last write was %q", w.id, id, u.Name, w.owned[id])
+	}
+}
+
+// pick returns a random owned user ID; callers ensure owned is non-empty.
+func (w *stressWorker) pick() int64 {
+	n := w.rnd.Intn(len(w.owned))
+	for id := range w.owned {
+		if n == 0 {
+			return id
+		}
+		n--
+	}
+	panic("unreachable")
+}
@@ -1,7 +1,13 @@
 package memory
 
 import (
-	"errors"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,81 +17,404 @@ import (
 
 // InMemoryUserRepository is a threadsafe in-memory implementation of UserRepository.
 type InMemoryUserRepository struct {
-	mu        sync.RWMutex
-	autoIncID int64
-	users     map[int64]*domain.User
+	mu           sync.RWMutex
+	autoIncID    int64
+	users        map[int64]*domain.User
+	emails       map[string]int64              // lower-cased email -> user ID
+	tags         map[string]map[int64]struct{} // tag -> IDs of users carrying it
+	externalIDs  map[externalID]int64          // provider and subject -> user ID
+	names        []nameEntry                   // sorted by lower-cased name, then ID
+	lastModified time.Time
+}
+
+type externalID struct {
+	provider, subject string
+}
+
+// nameEntry is one user in the name index.
+type nameEntry struct {
+	key string // lower-cased name
+	id  int64
+}
+
+func (e nameEntry) compare(o nameEntry) int {
+	if c := strings.Compare(e.key, o.key); c != 0 {
+		return c
+	}
+	return cmp.Compare(e.id, o.id)
 }
 
 func NewInMemoryUserRepository() *InMemoryUserRepository {
 	return &InMemoryUserRepository{
-		users: make(map[int64]*domain.User),
+		users:        make(map[int64]*domain.User),
+		emails:       make(map[string]int64),
+		tags:         make(map[string]map[int64]struct{}),
+		externalIDs:  make(map[externalID]int64),
+		lastModified: time.Now().UTC(),
 	}
 }
 
-func (r *InMemoryUserRepository) Create(user *domain.User) (*domain.User, error) {
+func (r *InMemoryUserRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if user == nil {
-		return nil, errors.New("nil user")
+		return nil, fmt.Errorf("%w: nil user", domain.ErrInvalidInput)
 	}
-	id := atomic.AddInt64(&r.autoIncID, 1)
-	now := time.Now().UTC()
-
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	copy := *user
-	copy.ID = id
-	copy.CreatedAt = now
-	copy.UpdatedAt = now
-	r.users[id] = &copy
-	return &copy, nil
+	key := emailKey(user.Email)
+	if _, taken := r.emails[key]; taken {
+		return nil, domain.ErrDuplicateEmail
+	}
+	if r.externalIDTaken(user.ExternalIDs, 0) {
+		return nil, domain.ErrDuplicateExternalID
+	}
+	now := time.Now().UTC()
+	stored := copyUser(user)
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.Version = 1
+	if domain.IsDryRun(ctx) {
+		stored.ID = atomic.LoadInt64(&r.autoIncID) + 1
+		return stored, nil
+	}
+	id := atomic.AddInt64(&r.autoIncID, 1)
+	stored.ID = id
+	r.users[id] = stored
+	r.emails[key] = id
+	r.indexTags(stored)
+	r.indexExternalIDs(stored)
+	r.indexName(stored)
+	r.lastModified = now
+	return copyUser(stored), nil
+}
+
+// copyUser copies u including its tags, metadata and external IDs, so
+// callers can't reach stored state.
+func copyUser(u *domain.User) *domain.User {
+	copy := *u
+	copy.Tags = slices.Clone(u.Tags)
+	copy.Metadata = domain.CloneMetadata(u.Metadata)
+	copy.ExternalIDs = maps.Clone(u.ExternalIDs)
+	return &copy
+}
+
+// indexTags adds u to the index of each of its tags.
+func (r *InMemoryUserRepository) indexTags(u *domain.User) {
+	for _, tag := range u.Tags {
+		ids, ok := r.tags[tag]
+		if !ok {
+			ids = make(map[int64]struct{})
+			r.tags[tag] = ids
+		}
+		ids[u.ID] = struct{}{}
+	}
 }
 
-func (r *InMemoryUserRepository) GetByID(id int64) (*domain.User, error) {
+// unindexTags removes u from the index of each of its tags.
+func (r *InMemoryUserRepository) unindexTags(u *domain.User) {
+	for _, tag := range u.Tags {
+		delete(r.tags[tag], u.ID)
+		if len(r.tags[tag]) == 0 {
+			delete(r.tags, tag)
+		}
+	}
+}
+
+// indexName adds u to the name index, keeping it sorted.
+func (r *InMemoryUserRepository) indexName(u *domain.User) {
+	e := nameEntry{strings.ToLower(u.Name), u.ID}
+	i, _ := slices.BinarySearchFunc(r.names, e, nameEntry.compare)
+	r.names = slices.Insert(r.names, i, e)
+}
+
+// unindexName removes u from the name index.
+func (r *InMemoryUserRepository) unindexName(u *domain.User) {
+	if i, ok := slices.BinarySearchFunc(r.names, nameEntry{strings.ToLower(u.Name), u.ID}, nameEntry.compare); ok {
+		r.names = slices.Delete(r.names, i, i+1)
+	}
+}
+
+// externalIDTaken reports whether any of ids is linked to a user other
+// than owner.
+func (r *InMemoryUserRepository) externalIDTaken(ids map[string]string, owner int64) bool {
+	for provider, subject := range ids {
+		if id, taken := r.externalIDs[externalID{provider, subject}]; taken && id != owner {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryUserRepository) indexExternalIDs(u *domain.User) {
+	for provider, subject := range u.ExternalIDs {
+		r.externalIDs[externalID{provider, subject}] = u.ID
+	}
+}
+
+func (r *InMemoryUserRepository) unindexExternalIDs(u *domain.User) {
+	for provider, subject := range u.ExternalIDs {
+		delete(r.externalIDs, externalID{provider, subject})
+	}
+}
+
+// candidates returns the users a filter can match, narrowed by the first
+// of its IDs, email and tag that is set, or all of them. IDs are applied
+// only here; matches checks the email and tag again.
+func (r *InMemoryUserRepository) candidates(f domain.Filter) []*domain.User {
+	if f.IDs != nil {
+		users := make([]*domain.User, 0, len(f.IDs))
+		seen := make(map[int64]bool, len(f.IDs))
+		for _, id := range f.IDs {
+			if u, ok := r.users[id]; ok && !seen[id] {
+				seen[id] = true
+				users = append(users, u)
+			}
+		}
+		return users
+	}
+	if f.EmailEq != "" {
+		if id, ok := r.emails[emailKey(f.EmailEq)]; ok {
+			return []*domain.User{r.users[id]}
+		}
+		return nil
+	}
+	if f.Tag == "" {
+		users := make([]*domain.User, 0, len(r.users))
+		for _, u := range r.users {
+			users = append(users, u)
+		}
+		return users
+	}
+	users := make([]*domain.User, 0, len(r.tags[f.Tag]))
+	for id := range r.tags[f.Tag] {
+		users = append(users, r.users[id])
+	}
+	return users
+}
+
+// emailKey normalises an email for the uniqueness index. Emails compare
+// case-insensitively, as they do in filters.
+func emailKey(email string) string {
+	return strings.ToLower(email)
+}
+
+func (r *InMemoryUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	u, ok := r.users[id]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
-	copy := *u
-	return &copy, nil
+	return copyUser(u), nil
 }
 
-func (r *InMemoryUserRepository) List() ([]*domain.User, error) {
+func (r *InMemoryUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	result := make([]*domain.User, 0, len(r.users))
-	for _, u := range r.users {
-		copy := *u
-		result = append(result, &copy)
+	id, ok := r.emails[emailKey(email)]
+	if !ok {
+		return nil, domain.ErrUserNotFound
 	}
-	return result, nil
+	return copyUser(r.users[id]), nil
 }
 
-func (r *InMemoryUserRepository) Update(user *domain.User) (*domain.User, error) {
+func (r *InMemoryUserRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	prefix = strings.ToLower(prefix)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, _ := slices.BinarySearchFunc(r.names, nameEntry{key: prefix}, nameEntry.compare)
+	var users []*domain.User
+	for _, e := range r.names[i:] {
+		if !strings.HasPrefix(e.key, prefix) || (limit > 0 && len(users) == limit) {
+			break
+		}
+		users = append(users, copyUser(r.users[e.id]))
+	}
+	return users, nil
+}
+
+func (r *InMemoryUserRepository) GetByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.externalIDs[externalID{provider, subject}]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return copyUser(r.users[id]), nil
+}
+
+func (r *InMemoryUserRepository) List(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	sortBy := filter.EffectiveSort()
+	var after *domain.User
+	if filter.Cursor != "" {
+		c, err := filter.DecodeCursor()
+		if err != nil {
+			return nil, err
+		}
+		after = c.Position()
+	}
+
+	// Matches are sorted and windowed as stored records, under the lock
+	// since Update changes them in place; only the page returned is copied.
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	total := 0
+	candidates := r.candidates(filter)
+	matched := make([]*domain.User, 0, len(candidates))
+	for _, u := range candidates {
+		if !filter.Match(u) {
+			continue
+		}
+		total++
+		if after != nil && sortBy.Compare(u, after) <= 0 {
+			continue
+		}
+		matched = append(matched, u)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return sortBy.Compare(matched[i], matched[j]) < 0
+	})
+	matched = matched[min(filter.Offset, len(matched)):]
+	more := filter.Limit > 0 && len(matched) > filter.Limit
+	if more {
+		matched = matched[:filter.Limit]
+	}
+
+	page := &domain.Page[domain.User]{Items: make([]*domain.User, len(matched)), Total: total}
+	for i, u := range matched {
+		page.Items[i] = copyUser(u)
+	}
+	if more {
+		page.NextCursor = domain.EncodeCursor(page.Items[len(page.Items)-1], sortBy)
+	}
+	return page, nil
+}
+
+func (r *InMemoryUserRepository) Count(ctx context.Context, filter domain.Filter) (int, error) {
+	filter.PageRequest, filter.Cursor = domain.PageRequest{}, ""
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, u := range r.candidates(filter) {
+		if filter.Match(u) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *InMemoryUserRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if user == nil {
-		return nil, errors.New("nil user")
+		return nil, fmt.Errorf("%w: nil user", domain.ErrInvalidInput)
 	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	existing, ok := r.users[user.ID]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
+	}
+	if user.Version != 0 && user.Version != existing.Version {
+		return nil, domain.ErrVersionConflict
 	}
-	existing.Name = user.Name
-	existing.Email = user.Email
-	existing.UpdatedAt = time.Now().UTC()
-	copy := *existing
-	return &copy, nil
+	key := emailKey(user.Email)
+	if owner, taken := r.emails[key]; taken && owner != user.ID {
+		return nil, domain.ErrDuplicateEmail
+	}
+	if r.externalIDTaken(user.ExternalIDs, user.ID) {
+		return nil, domain.ErrDuplicateExternalID
+	}
+	if domain.IsDryRun(ctx) {
+		updated := copyUser(existing)
+		applyUpdate(updated, user)
+		return updated, nil
+	}
+	delete(r.emails, emailKey(existing.Email))
+	r.emails[key] = user.ID
+	r.unindexTags(existing)
+	r.unindexExternalIDs(existing)
+	r.unindexName(existing)
+	applyUpdate(existing, user)
+	r.indexTags(existing)
+	r.indexExternalIDs(existing)
+	r.indexName(existing)
+	r.lastModified = existing.UpdatedAt
+	return copyUser(existing), nil
 }
 
-func (r *InMemoryUserRepository) Delete(id int64) error {
+// applyUpdate copies the fields Update stores from user to dst and bumps
+// its version.
+func applyUpdate(dst, user *domain.User) {
+	dst.Name = user.Name
+	dst.Email = user.Email
+	if user.Status != "" {
+		dst.Status = user.Status
+	}
+	if user.Role != "" {
+		dst.Role = user.Role
+	}
+	if user.Tags != nil {
+		dst.Tags = slices.Clone(user.Tags)
+	}
+	if user.Metadata != nil {
+		dst.Metadata = domain.CloneMetadata(user.Metadata)
+	}
+	if user.ExternalIDs != nil {
+		dst.ExternalIDs = maps.Clone(user.ExternalIDs)
+	}
+	dst.UpdatedAt = time.Now().UTC()
+	dst.Version++
+}
+
+func (r *InMemoryUserRepository) Delete(ctx context.Context, id int64, version int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.users[id]; !ok {
-		return errors.New("user not found")
+	u, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if version != 0 && version != u.Version {
+		return domain.ErrVersionConflict
 	}
+	if domain.IsDryRun(ctx) {
+		return nil
+	}
+	delete(r.emails, emailKey(u.Email))
+	r.unindexTags(u)
+	r.unindexExternalIDs(u)
+	r.unindexName(u)
 	delete(r.users, id)
+	r.lastModified = time.Now().UTC()
 	return nil
 }
+
+func (r *InMemoryUserRepository) LastModified(ctx context.Context) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastModified, nil
+}
+
+func (r *InMemoryUserRepository) Stats(ctx context.Context, q domain.StatsQuery) ([]domain.StatsBucket, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	r.mu.RLock()
+	for _, u := range r.users {
+		counts[domain.GroupKey(u, q)]++
+	}
+	r.mu.RUnlock()
+
+	buckets := make([]domain.StatsBucket, 0, len(counts))
+	for key, n := range counts {
+		buckets = append(buckets, domain.StatsBucket{Key: key, Count: n})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Key < buckets[j].Key })
+	return buckets, nil
+}
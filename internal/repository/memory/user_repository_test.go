@@ -2,6 +2,11 @@ package memory
 
 import (
 	"cleanarch/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -15,7 +20,7 @@ func TestInMemoryUserRepository_Create(t *testing.T) {
 			Email: "john@example.com",
 		}
 
-		created, err := repo.Create(user)
+		created, err := repo.Create(context.Background(), user)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -43,21 +48,20 @@ func TestInMemoryUserRepository_Create(t *testing.T) {
 	t.Run("Create nil user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		_, err := repo.Create(nil)
+		_, err := repo.Create(context.Background(), nil)
 		if err == nil {
 			t.Error("expected error for nil user")
 		}
-		expectedMsg := "nil user"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
 	})
 
 	t.Run("Create multiple users with incremental IDs", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		user1, _ := repo.Create(&domain.User{Name: "User1", Email: "user1@example.com"})
-		user2, _ := repo.Create(&domain.User{Name: "User2", Email: "user2@example.com"})
+		user1, _ := repo.Create(context.Background(), &domain.User{Name: "User1", Email: "user1@example.com"})
+		user2, _ := repo.Create(context.Background(), &domain.User{Name: "User2", Email: "user2@example.com"})
 
 		if user1.ID >= user2.ID {
 			t.Error("expected user IDs to be incremental")
@@ -71,9 +75,9 @@ func TestInMemoryUserRepository_Create(t *testing.T) {
 func TestInMemoryUserRepository_GetByID(t *testing.T) {
 	t.Run("Get existing user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		created, _ := repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
 
-		user, err := repo.GetByID(created.ID)
+		user, err := repo.GetByID(context.Background(), created.ID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -88,22 +92,21 @@ func TestInMemoryUserRepository_GetByID(t *testing.T) {
 	t.Run("Get non-existent user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		_, err := repo.GetByID(999)
+		_, err := repo.GetByID(context.Background(), 999)
 		if err == nil {
 			t.Error("expected error for non-existent user")
 		}
-		expectedMsg := "user not found"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
 		}
 	})
 
 	t.Run("Get user returns copy, not reference", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		created, _ := repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
 
-		user1, _ := repo.GetByID(created.ID)
-		user2, _ := repo.GetByID(created.ID)
+		user1, _ := repo.GetByID(context.Background(), created.ID)
+		user2, _ := repo.GetByID(context.Background(), created.ID)
 
 		// Modify one copy
 		user1.Name = "Modified Name"
@@ -119,50 +122,288 @@ func TestInMemoryUserRepository_List(t *testing.T) {
 	t.Run("List empty repository", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		users, err := repo.List()
+		users, err := repo.List(context.Background(), domain.Filter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(users) != 0 {
-			t.Errorf("expected 0 users, got %d", len(users))
+		if len(users.Items) != 0 {
+			t.Errorf("expected 0 users, got %d", len(users.Items))
 		}
 	})
 
 	t.Run("List multiple users", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		_, _ = repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
-		_, _ = repo.Create(&domain.User{Name: "Jane Doe", Email: "jane@example.com"})
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "Jane Doe", Email: "jane@example.com"})
 
-		users, err := repo.List()
+		users, err := repo.List(context.Background(), domain.Filter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(users) != 2 {
-			t.Errorf("expected 2 users, got %d", len(users))
+		if len(users.Items) != 2 {
+			t.Errorf("expected 2 users, got %d", len(users.Items))
 		}
 	})
 
 	t.Run("List returns copies, not references", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		_, _ = repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
 
-		users1, _ := repo.List()
-		users2, _ := repo.List()
+		users1, _ := repo.List(context.Background(), domain.Filter{})
+		users2, _ := repo.List(context.Background(), domain.Filter{})
 
 		// Modify one list
-		users1[0].Name = "Modified Name"
+		users1.Items[0].Name = "Modified Name"
 
 		// Check that the other list is not affected
-		if users2[0].Name == "Modified Name" {
+		if users2.Items[0].Name == "Modified Name" {
 			t.Error("expected List to return copies, not references")
 		}
 	})
+
+	t.Run("Pages are windows ordered by ID", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		for i := range 5 {
+			_, _ = repo.Create(context.Background(), &domain.User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)})
+		}
+
+		page, err := repo.List(context.Background(), domain.Filter{PageRequest: domain.PageRequest{Limit: 2, Offset: 2}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page.Total != 5 || len(page.Items) != 2 || page.Items[0].ID != 3 || page.Items[1].ID != 4 {
+			t.Fatalf("expected users 3 and 4 of 5, got %d and %v", page.Total, page.Items)
+		}
+		page.Items[0].Name = "Modified Name"
+		if got, _ := repo.GetByID(context.Background(), 3); got.Name != "User 2" {
+			t.Errorf("expected the stored user to be unaffected, got %q", got.Name)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_ListFilter(t *testing.T) {
+	seed := func() *InMemoryUserRepository {
+		repo := NewInMemoryUserRepository()
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "Charlie", Email: "charlie@corp.com"})
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "alice", Email: "alice@example.com"})
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "Bob", Email: "bob@corp.com"})
+		return repo
+	}
+
+	t.Run("Default order is by ID", func(t *testing.T) {
+		users, err := seed().List(context.Background(), domain.Filter{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for i := 1; i < len(users.Items); i++ {
+			if users.Items[i-1].ID >= users.Items[i].ID {
+				t.Errorf("expected ascending IDs, got %d before %d", users.Items[i-1].ID, users.Items[i].ID)
+			}
+		}
+	})
+
+	t.Run("Name contains is case-insensitive", func(t *testing.T) {
+		users, _ := seed().List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{NameContains: "LIC"}})
+		if len(users.Items) != 1 || users.Items[0].Name != "alice" {
+			t.Errorf("expected only alice, got %v", users.Items)
+		}
+	})
+
+	t.Run("Expression", func(t *testing.T) {
+		expr, err := domain.ParseExpr(`email endsWith "@corp.com" AND NOT name = "Bob"`)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		users, _ := seed().List(context.Background(), domain.Filter{Expr: expr})
+		if len(users.Items) != 1 || users.Items[0].Name != "Charlie" {
+			t.Errorf("expected only Charlie, got %v", users.Items)
+		}
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		repo := seed()
+		_, _ = repo.Update(context.Background(), &domain.User{ID: 2, Name: "alice", Email: "alice@example.com", Status: domain.StatusSuspended})
+		users, _ := repo.List(context.Background(), domain.Filter{Status: domain.StatusSuspended})
+		if len(users.Items) != 1 || users.Items[0].Name != "alice" {
+			t.Errorf("expected only alice, got %v", users.Items)
+		}
+	})
+
+	t.Run("Email equals", func(t *testing.T) {
+		users, _ := seed().List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{EmailEq: "Bob@Corp.com"}})
+		if len(users.Items) != 1 || users.Items[0].Name != "Bob" {
+			t.Errorf("expected only Bob, got %v", users.Items)
+		}
+	})
+
+	t.Run("Tag follows updates and deletes", func(t *testing.T) {
+		repo := seed()
+		ctx := context.Background()
+		_, _ = repo.Update(ctx, &domain.User{ID: 1, Name: "Charlie", Email: "charlie@corp.com", Tags: []string{"beta", "vip"}})
+		_, _ = repo.Update(ctx, &domain.User{ID: 3, Name: "Bob", Email: "bob@corp.com", Tags: []string{"beta"}})
+		users, _ := repo.List(ctx, domain.Filter{UserFilter: domain.UserFilter{Tag: "beta"}})
+		if users.Total != 2 || users.Items[0].ID != 1 || users.Items[1].ID != 3 {
+			t.Errorf("expected users 1 and 3, got %v", users.Items)
+		}
+
+		_, _ = repo.Update(ctx, &domain.User{ID: 1, Name: "Charlie", Email: "charlie@corp.com", Tags: []string{}})
+		_ = repo.Delete(ctx, 3, 0)
+		if users, _ := repo.List(ctx, domain.Filter{UserFilter: domain.UserFilter{Tag: "beta"}}); users.Total != 0 {
+			t.Errorf("expected no beta users, got %v", users.Items)
+		}
+		if users, _ := repo.List(ctx, domain.Filter{UserFilter: domain.UserFilter{Tag: "vip"}}); users.Total != 0 {
+			t.Errorf("expected no vip users, got %v", users.Items)
+		}
+	})
+
+	t.Run("IDs", func(t *testing.T) {
+		repo := seed()
+		page, _ := repo.List(context.Background(), domain.Filter{IDs: []int64{3, 1, 3, 99}, Sort: domain.SortSpec{Field: domain.SortByID, Descending: true}})
+		if page.Total != 2 || page.Items[0].ID != 3 || page.Items[1].ID != 1 {
+			t.Errorf("expected users 3 and 1, got %v", page.Items)
+		}
+		page, _ = repo.List(context.Background(), domain.Filter{IDs: []int64{1, 2}, UserFilter: domain.UserFilter{EmailEq: "Bob@Corp.com"}})
+		if page.Total != 0 {
+			t.Errorf("expected other constraints to still apply, got %v", page.Items)
+		}
+		if page, _ := repo.List(context.Background(), domain.Filter{IDs: []int64{}}); page.Total != 0 {
+			t.Errorf("expected no users for empty IDs, got %v", page.Items)
+		}
+	})
+
+	t.Run("Metadata is stored as a copy", func(t *testing.T) {
+		repo := seed()
+		ctx := context.Background()
+		metadata := map[string]any{"plan": map[string]any{"tier": "pro"}}
+		_, _ = repo.Update(ctx, &domain.User{ID: 2, Name: "alice", Email: "alice@example.com", Metadata: metadata})
+		metadata["plan"].(map[string]any)["tier"] = "free"
+		got, _ := repo.GetByID(ctx, 2)
+		if got.Metadata["plan"].(map[string]any)["tier"] != "pro" {
+			t.Errorf("expected stored metadata to be copied, got %v", got.Metadata)
+		}
+	})
+
+	t.Run("Nil tags are left unchanged", func(t *testing.T) {
+		repo := seed()
+		ctx := context.Background()
+		_, _ = repo.Update(ctx, &domain.User{ID: 2, Name: "alice", Email: "alice@example.com", Tags: []string{"beta"}})
+		updated, _ := repo.Update(ctx, &domain.User{ID: 2, Name: "Alice", Email: "alice@example.com"})
+		if len(updated.Tags) != 1 || updated.Tags[0] != "beta" {
+			t.Errorf("expected tags [beta], got %v", updated.Tags)
+		}
+		updated.Tags[0] = "mutated"
+		if got, _ := repo.GetByID(ctx, 2); got.Tags[0] != "beta" {
+			t.Errorf("expected stored tags to be copied, got %v", got.Tags)
+		}
+	})
+
+	t.Run("Created before", func(t *testing.T) {
+		repo := seed()
+		all, _ := repo.List(context.Background(), domain.Filter{})
+		users, _ := repo.List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{CreatedBefore: all.Items[0].CreatedAt.Add(time.Nanosecond)}})
+		if len(users.Items) == 0 || users.Items[0].ID != all.Items[0].ID {
+			t.Errorf("expected the first user to be included, got %v", users.Items)
+		}
+		users, _ = repo.List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{CreatedBefore: all.Items[0].CreatedAt}})
+		if len(users.Items) != 0 {
+			t.Errorf("expected no users created strictly before the first, got %d", len(users.Items))
+		}
+	})
+
+	t.Run("Created after", func(t *testing.T) {
+		repo := seed()
+		all, _ := repo.List(context.Background(), domain.Filter{})
+		last := all.Items[len(all.Items)-1]
+		users, _ := repo.List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{CreatedAfter: last.CreatedAt.Add(-time.Nanosecond)}})
+		if len(users.Items) == 0 || users.Items[len(users.Items)-1].ID != last.ID {
+			t.Errorf("expected the last user to be included, got %v", users.Items)
+		}
+		users, _ = repo.List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{CreatedAfter: last.CreatedAt}})
+		if len(users.Items) != 0 {
+			t.Errorf("expected no users created strictly after the last, got %d", len(users.Items))
+		}
+	})
+
+	t.Run("Descending sort with cursor", func(t *testing.T) {
+		repo := seed()
+		filter := domain.Filter{Sort: domain.SortSpec{Field: domain.SortByEmail, Descending: true}, PageRequest: domain.PageRequest{Limit: 2}}
+
+		first, err := repo.List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.Items) != 2 || first.Items[0].Name != "Charlie" || first.Items[1].Name != "Bob" {
+			t.Fatalf("expected Charlie and Bob, got %v", first.Items)
+		}
+
+		filter.Cursor = first.NextCursor
+		second, err := repo.List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(second.Items) != 1 || second.Items[0].Name != "alice" {
+			t.Errorf("expected alice, got %v", second.Items)
+		}
+	})
+
+	t.Run("Sort by email with limit and cursor", func(t *testing.T) {
+		repo := seed()
+		filter := domain.Filter{Sort: domain.SortSpec{Field: domain.SortByEmail}, PageRequest: domain.PageRequest{Limit: 2}}
+
+		first, err := repo.List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.Items) != 2 || first.Items[0].Name != "alice" || first.Items[1].Name != "Bob" {
+			t.Fatalf("expected alice and Bob, got %v", first.Items)
+		}
+
+		if first.Total != 3 || first.NextCursor != domain.EncodeCursor(first.Items[1], domain.SortSpec{Field: domain.SortByEmail}) {
+			t.Errorf("expected a total of 3 and a cursor after Bob, got %d and %q", first.Total, first.NextCursor)
+		}
+
+		filter.Cursor = first.NextCursor
+		second, err := repo.List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(second.Items) != 1 || second.Items[0].Name != "Charlie" {
+			t.Errorf("expected Charlie, got %v", second.Items)
+		}
+		if second.Total != 3 || second.NextCursor != "" {
+			t.Errorf("expected a total of 3 and no cursor on the last page, got %d and %q", second.Total, second.NextCursor)
+		}
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		filter := domain.Filter{PageRequest: domain.PageRequest{Limit: 1, Offset: 1}}
+		page, err := seed().List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Name != "alice" || page.NextCursor == "" {
+			t.Errorf("expected alice with a next cursor, got %+v", page)
+		}
+
+		filter.Offset = 10
+		page, _ = seed().List(context.Background(), filter)
+		if len(page.Items) != 0 || page.Total != 3 {
+			t.Errorf("expected an empty page of 3 users, got %+v", page)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		_, err := seed().List(context.Background(), domain.Filter{PageRequest: domain.PageRequest{Limit: -1}})
+		if !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
 }
 
 func TestInMemoryUserRepository_Update(t *testing.T) {
 	t.Run("Update existing user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		created, _ := repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
 
 		// Save the original UpdatedAt time
 		originalUpdatedAt := created.UpdatedAt
@@ -170,7 +411,7 @@ func TestInMemoryUserRepository_Update(t *testing.T) {
 		// Wait a bit to ensure UpdatedAt is different
 		time.Sleep(10 * time.Millisecond)
 
-		updated, err := repo.Update(&domain.User{
+		updated, err := repo.Update(context.Background(), &domain.User{
 			ID:    created.ID,
 			Name:  "Jane Doe",
 			Email: "jane@example.com",
@@ -193,7 +434,7 @@ func TestInMemoryUserRepository_Update(t *testing.T) {
 		}
 
 		// Alternative check: verify that the user in the repository was actually updated
-		retrieved, _ := repo.GetByID(created.ID)
+		retrieved, _ := repo.GetByID(context.Background(), created.ID)
 		if retrieved.Name != "Jane Doe" {
 			t.Errorf("expected retrieved name 'Jane Doe', got %s", retrieved.Name)
 		}
@@ -205,20 +446,19 @@ func TestInMemoryUserRepository_Update(t *testing.T) {
 	t.Run("Update nil user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		_, err := repo.Update(nil)
+		_, err := repo.Update(context.Background(), nil)
 		if err == nil {
 			t.Error("expected error for nil user")
 		}
-		expectedMsg := "nil user"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
 	})
 
 	t.Run("Update non-existent user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		_, err := repo.Update(&domain.User{
+		_, err := repo.Update(context.Background(), &domain.User{
 			ID:    999,
 			Name:  "Jane Doe",
 			Email: "jane@example.com",
@@ -226,9 +466,8 @@ func TestInMemoryUserRepository_Update(t *testing.T) {
 		if err == nil {
 			t.Error("expected error for non-existent user")
 		}
-		expectedMsg := "user not found"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
 		}
 	})
 }
@@ -236,15 +475,15 @@ func TestInMemoryUserRepository_Update(t *testing.T) {
 func TestInMemoryUserRepository_Delete(t *testing.T) {
 	t.Run("Delete existing user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
-		created, _ := repo.Create(&domain.User{Name: "John Doe", Email: "john@example.com"})
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
 
-		err := repo.Delete(created.ID)
+		err := repo.Delete(context.Background(), created.ID, 0)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Verify user is deleted
-		_, err = repo.GetByID(created.ID)
+		_, err = repo.GetByID(context.Background(), created.ID)
 		if err == nil {
 			t.Error("expected error when getting deleted user")
 		}
@@ -253,13 +492,273 @@ func TestInMemoryUserRepository_Delete(t *testing.T) {
 	t.Run("Delete non-existent user", func(t *testing.T) {
 		repo := NewInMemoryUserRepository()
 
-		err := repo.Delete(999)
+		err := repo.Delete(context.Background(), 999, 0)
 		if err == nil {
 			t.Error("expected error for non-existent user")
 		}
-		expectedMsg := "user not found"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_DryRun(t *testing.T) {
+	dry := domain.WithDryRun(context.Background())
+
+	t.Run("Writes are checked but not stored", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+
+		got, err := repo.Create(dry, &domain.User{Name: "Jane Doe", Email: "jane@example.com"})
+		if err != nil || got.ID != 2 {
+			t.Fatalf("expected the would-be user 2, got %+v, %v", got, err)
+		}
+		updated, err := repo.Update(dry, &domain.User{ID: created.ID, Name: "Johnny", Email: "john@example.com", Tags: []string{"vip"}})
+		if err != nil || updated.Name != "Johnny" || updated.Version != created.Version+1 {
+			t.Fatalf("expected the would-be update, got %+v, %v", updated, err)
+		}
+		if err := repo.Delete(dry, created.ID, 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		users, _ := repo.List(context.Background(), domain.Filter{})
+		if users.Total != 1 || !reflect.DeepEqual(users.Items[0], created) {
+			t.Errorf("expected only the unchanged user, got %v", users.Items)
+		}
+		if page, _ := repo.List(context.Background(), domain.Filter{UserFilter: domain.UserFilter{Tag: "vip"}}); page.Total != 0 {
+			t.Errorf("expected tags not to be indexed, got %d users", page.Total)
+		}
+		if next, _ := repo.Create(context.Background(), &domain.User{Name: "Jane Doe", Email: "jane@example.com"}); next.ID != 2 {
+			t.Errorf("expected ID 2 to still be free, got %d", next.ID)
+		}
+	})
+
+	t.Run("Conflicts are reported", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+
+		if _, err := repo.Create(dry, &domain.User{Name: "John", Email: "john@example.com"}); !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+		if err := repo.Delete(dry, created.ID, created.Version+1); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict, got %v", err)
+		}
+		if err := repo.Delete(dry, 999, 0); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_LastModified(t *testing.T) {
+	t.Run("New repository has last modified set", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+
+		lastModified, err := repo.LastModified(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if lastModified.IsZero() {
+			t.Error("expected last modified to be set")
+		}
+	})
+
+	t.Run("Mutations advance last modified", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		initial, _ := repo.LastModified(context.Background())
+
+		time.Sleep(10 * time.Millisecond)
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		afterCreate, _ := repo.LastModified(context.Background())
+		if !afterCreate.After(initial) {
+			t.Errorf("expected last modified to advance on create: initial=%v, after=%v", initial, afterCreate)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "Jane Doe", Email: "jane@example.com"})
+		afterUpdate, _ := repo.LastModified(context.Background())
+		if !afterUpdate.After(afterCreate) {
+			t.Errorf("expected last modified to advance on update: before=%v, after=%v", afterCreate, afterUpdate)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		_ = repo.Delete(context.Background(), created.ID, 0)
+		afterDelete, _ := repo.LastModified(context.Background())
+		if !afterDelete.After(afterUpdate) {
+			t.Errorf("expected last modified to advance on delete: before=%v, after=%v", afterUpdate, afterDelete)
+		}
+	})
+
+	t.Run("Reads do not change last modified", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		before, _ := repo.LastModified(context.Background())
+
+		_, _ = repo.GetByID(context.Background(), created.ID)
+		_, _ = repo.List(context.Background(), domain.Filter{})
+
+		after, _ := repo.LastModified(context.Background())
+		if !after.Equal(before) {
+			t.Errorf("expected last modified to be unchanged by reads: before=%v, after=%v", before, after)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_DuplicateEmail(t *testing.T) {
+	t.Run("Create rejects an email in use", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+
+		_, err := repo.Create(context.Background(), &domain.User{Name: "Johnny", Email: "John@Example.com"})
+		if !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("Update rejects another user's email", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		_, _ = repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		jane, _ := repo.Create(context.Background(), &domain.User{Name: "Jane Doe", Email: "jane@example.com"})
+
+		_, err := repo.Update(context.Background(), &domain.User{ID: jane.ID, Name: "Jane Doe", Email: "john@example.com"})
+		if !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+		if _, err := repo.Update(context.Background(), &domain.User{ID: jane.ID, Name: "Jane", Email: "jane@example.com"}); err != nil {
+			t.Errorf("expected keeping the same email to succeed, got %v", err)
+		}
+	})
+
+	t.Run("Update and delete release the old email", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		john, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		jane, _ := repo.Create(context.Background(), &domain.User{Name: "Jane Doe", Email: "jane@example.com"})
+
+		_, _ = repo.Update(context.Background(), &domain.User{ID: john.ID, Name: "John Doe", Email: "jd@example.com"})
+		_ = repo.Delete(context.Background(), jane.ID, 0)
+		for _, email := range []string{"john@example.com", "jane@example.com"} {
+			if _, err := repo.Create(context.Background(), &domain.User{Name: "New", Email: email}); err != nil {
+				t.Errorf("expected %s to be free, got %v", email, err)
+			}
+		}
+	})
+}
+
+func TestInMemoryUserRepository_SearchByNamePrefix(t *testing.T) {
+	names := func(users []*domain.User) string {
+		var s []string
+		for _, u := range users {
+			s = append(s, u.Name)
+		}
+		return strings.Join(s, ",")
+	}
+	seed := func() *InMemoryUserRepository {
+		repo := NewInMemoryUserRepository()
+		for _, name := range []string{"bob", "Alice", "alfred", "Al", "Zoe"} {
+			_, _ = repo.Create(context.Background(), &domain.User{Name: name, Email: name + "@example.com"})
+		}
+		return repo
+	}
+
+	t.Run("Matches ignore case and are ordered by name", func(t *testing.T) {
+		users, err := seed().SearchByNamePrefix(context.Background(), "AL", 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(users); got != "Al,alfred,Alice" {
+			t.Errorf("expected Al,alfred,Alice, got %s", got)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		users, _ := seed().SearchByNamePrefix(context.Background(), "al", 2)
+		if got := names(users); got != "Al,alfred" {
+			t.Errorf("expected Al,alfred, got %s", got)
+		}
+	})
+
+	t.Run("Index follows updates and deletes", func(t *testing.T) {
+		repo := seed()
+		_, _ = repo.Update(context.Background(), &domain.User{ID: 1, Name: "Alberto", Email: "bob@example.com"})
+		_ = repo.Delete(context.Background(), 2, 0)
+		users, _ := repo.SearchByNamePrefix(context.Background(), "al", 0)
+		if got := names(users); got != "Al,Alberto,alfred" {
+			t.Errorf("expected Al,Alberto,alfred, got %s", got)
+		}
+		if users, _ := repo.SearchByNamePrefix(context.Background(), "bob", 0); len(users) != 0 {
+			t.Errorf("expected the old name to be gone, got %s", names(users))
+		}
+	})
+
+	t.Run("Results are copies", func(t *testing.T) {
+		repo := seed()
+		users, _ := repo.SearchByNamePrefix(context.Background(), "zoe", 0)
+		users[0].Name = "Modified Name"
+		if again, _ := repo.SearchByNamePrefix(context.Background(), "zoe", 0); len(again) != 1 {
+			t.Error("expected stored users to be unaffected")
+		}
+	})
+}
+
+func TestInMemoryUserRepository_GetByEmail(t *testing.T) {
+	t.Run("Emails compare case-insensitively", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+
+		got, err := repo.GetByEmail(context.Background(), "John@Example.com")
+		if err != nil || got.ID != created.ID {
+			t.Fatalf("expected user %d, got %+v, %v", created.ID, got, err)
+		}
+		got.Name = "Modified Name"
+		if again, _ := repo.GetByEmail(context.Background(), "john@example.com"); again.Name != "John Doe" {
+			t.Errorf("expected a copy, got %q", again.Name)
+		}
+	})
+
+	t.Run("Follows updates and deletes", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		_, _ = repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "John Doe", Email: "jd@example.com"})
+
+		if _, err := repo.GetByEmail(context.Background(), "john@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound for the old email, got %v", err)
+		}
+		if got, err := repo.GetByEmail(context.Background(), "jd@example.com"); err != nil || got.ID != created.ID {
+			t.Errorf("expected user %d by the new email, got %+v, %v", created.ID, got, err)
+		}
+		_ = repo.Delete(context.Background(), created.ID, 0)
+		if _, err := repo.GetByEmail(context.Background(), "jd@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound after delete, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_Version(t *testing.T) {
+	t.Run("Writes bump the version", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		if created.Version != 1 {
+			t.Errorf("expected version 1, got %d", created.Version)
+		}
+		updated, _ := repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "Jane Doe", Email: "jane@example.com"})
+		if updated.Version != 2 {
+			t.Errorf("expected version 2, got %d", updated.Version)
+		}
+	})
+
+	t.Run("Stale versions are rejected", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		_, _ = repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "Jane Doe", Email: "jane@example.com", Version: 1})
+
+		_, err := repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "Jim", Email: "jim@example.com", Version: 1})
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict on update, got %v", err)
+		}
+		if err := repo.Delete(context.Background(), created.ID, 1); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict on delete, got %v", err)
+		}
+		if err := repo.Delete(context.Background(), created.ID, 2); err != nil {
+			t.Errorf("expected delete at the current version to succeed, got %v", err)
 		}
 	})
 }
@@ -275,9 +774,9 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 		for i := 0; i < numGoroutines; i++ {
 			go func(id int) {
 				defer wg.Done()
-				_, err := repo.Create(&domain.User{
+				_, err := repo.Create(context.Background(), &domain.User{
 					Name:  "User",
-					Email: "user@example.com",
+					Email: fmt.Sprintf("user%d@example.com", id),
 				})
 				if err != nil {
 					t.Errorf("unexpected error in goroutine %d: %v", id, err)
@@ -287,14 +786,14 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 		wg.Wait()
 
 		// Check that all users were created with unique IDs
-		users, _ := repo.List()
-		if len(users) != numGoroutines {
-			t.Errorf("expected %d users, got %d", numGoroutines, len(users))
+		page, _ := repo.List(context.Background(), domain.Filter{})
+		if len(page.Items) != numGoroutines {
+			t.Errorf("expected %d users, got %d", numGoroutines, len(page.Items))
 		}
 
 		// Check for duplicate IDs
 		ids := make(map[int64]bool)
-		for _, user := range users {
+		for _, user := range page.Items {
 			if ids[user.ID] {
 				t.Errorf("found duplicate ID: %d", user.ID)
 			}
@@ -307,7 +806,7 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 
 		// Create some initial users
 		for i := 0; i < 10; i++ {
-			repo.Create(&domain.User{Name: "User", Email: "user@example.com"})
+			repo.Create(context.Background(), &domain.User{Name: "User", Email: fmt.Sprintf("user%d@example.com", i)})
 		}
 
 		var wg sync.WaitGroup
@@ -323,13 +822,13 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 				switch id % 3 {
 				case 0:
 					// Read operation
-					repo.List()
+					repo.List(context.Background(), domain.Filter{})
 				case 1:
 					// Create operation
-					repo.Create(&domain.User{Name: "NewUser", Email: "new@example.com"})
+					repo.Create(context.Background(), &domain.User{Name: "NewUser", Email: fmt.Sprintf("new%d@example.com", id)})
 				case 2:
 					// Update operation
-					repo.Update(&domain.User{ID: int64(id%10 + 1), Name: "Updated", Email: "updated@example.com"})
+					repo.Update(context.Background(), &domain.User{ID: int64(id%10 + 1), Name: "Updated", Email: "updated@example.com"})
 				}
 			}(i)
 		}
@@ -338,3 +837,124 @@ func TestInMemoryUserRepository_Concurrency(t *testing.T) {
 		// If we get here without race conditions, the test passes
 	})
 }
+
+func TestInMemoryUserRepository_Stats(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	_, _ = repo.Create(context.Background(), &domain.User{Name: "Ann", Email: "ann@corp.com"})
+	_, _ = repo.Create(context.Background(), &domain.User{Name: "Bob", Email: "bob@Corp.com"})
+	_, _ = repo.Create(context.Background(), &domain.User{Name: "Cid", Email: "cid@example.com"})
+
+	buckets, err := repo.Stats(context.Background(), domain.StatsQuery{GroupBy: domain.GroupByEmailDomain})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []domain.StatsBucket{{Key: "corp.com", Count: 2}, {Key: "example.com", Count: 1}}
+	if len(buckets) != 2 || buckets[0] != want[0] || buckets[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, buckets)
+	}
+
+	if _, err := repo.Stats(context.Background(), domain.StatsQuery{GroupBy: domain.GroupByCreated}); err == nil {
+		t.Error("expected error for missing bucket")
+	}
+}
+
+func TestInMemoryUserRepository_Count(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	ann, _ := repo.Create(context.Background(), &domain.User{Name: "Ann", Email: "ann@corp.com"})
+	_, _ = repo.Create(context.Background(), &domain.User{Name: "Anna", Email: "anna@corp.com"})
+	_, _ = repo.Create(context.Background(), &domain.User{Name: "Bob", Email: "bob@example.com"})
+
+	t.Run("Counts the matching users", func(t *testing.T) {
+		n, err := repo.Count(context.Background(), domain.Filter{UserFilter: domain.UserFilter{NameContains: "ann"}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected 2 users, got %d", n)
+		}
+	})
+
+	t.Run("Paging is ignored", func(t *testing.T) {
+		n, err := repo.Count(context.Background(), domain.Filter{PageRequest: domain.PageRequest{Limit: 1, Offset: 2}, Cursor: "bogus"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 3 {
+			t.Errorf("expected 3 users, got %d", n)
+		}
+	})
+
+	t.Run("IDs restrict the count", func(t *testing.T) {
+		if n, _ := repo.Count(context.Background(), domain.Filter{IDs: []int64{ann.ID}}); n != 1 {
+			t.Errorf("expected 1 user, got %d", n)
+		}
+		if n, _ := repo.Count(context.Background(), domain.Filter{IDs: []int64{}}); n != 0 {
+			t.Errorf("expected no users, got %d", n)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		if _, err := repo.Count(context.Background(), domain.Filter{Status: "bogus"}); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryUserRepository_ExternalIDs(t *testing.T) {
+	create := func(repo *InMemoryUserRepository, email string, ids map[string]string) (*domain.User, error) {
+		return repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: email, ExternalIDs: ids})
+	}
+
+	t.Run("Lookup finds the linked user", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		_, _ = create(repo, "john@example.com", map[string]string{"google": "1"})
+		jane, _ := create(repo, "jane@example.com", map[string]string{"google": "2", "github": "1"})
+
+		got, err := repo.GetByExternalID(context.Background(), "github", "1")
+		if err != nil || got.ID != jane.ID {
+			t.Fatalf("expected user %d, got %v, %v", jane.ID, got, err)
+		}
+		if _, err := repo.GetByExternalID(context.Background(), "github", "2"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Identities are linked to one user", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		_, _ = create(repo, "john@example.com", map[string]string{"google": "1"})
+		if _, err := create(repo, "jane@example.com", map[string]string{"google": "1"}); !errors.Is(err, domain.ErrDuplicateExternalID) {
+			t.Errorf("expected ErrDuplicateExternalID on create, got %v", err)
+		}
+		jane, _ := create(repo, "jane@example.com", nil)
+		_, err := repo.Update(context.Background(), &domain.User{ID: jane.ID, Name: "Jane", Email: "jane@example.com", ExternalIDs: map[string]string{"google": "1"}})
+		if !errors.Is(err, domain.ErrDuplicateExternalID) {
+			t.Errorf("expected ErrDuplicateExternalID on update, got %v", err)
+		}
+	})
+
+	t.Run("Update and delete release old identities", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		john, _ := create(repo, "john@example.com", map[string]string{"google": "1"})
+		jane, _ := create(repo, "jane@example.com", map[string]string{"google": "2"})
+
+		_, _ = repo.Update(context.Background(), &domain.User{ID: john.ID, Name: "John", Email: "john@example.com", ExternalIDs: map[string]string{"google": "3"}})
+		_ = repo.Delete(context.Background(), jane.ID, 0)
+		for _, subject := range []string{"1", "2"} {
+			if _, err := repo.GetByExternalID(context.Background(), "google", subject); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("expected google %s to be unlinked, got %v", subject, err)
+			}
+		}
+		if _, err := create(repo, "new@example.com", map[string]string{"google": "1"}); err != nil {
+			t.Errorf("expected google 1 to be free, got %v", err)
+		}
+	})
+
+	t.Run("Nil external IDs are left unchanged", func(t *testing.T) {
+		repo := NewInMemoryUserRepository()
+		john, _ := create(repo, "john@example.com", map[string]string{"google": "1"})
+		updated, _ := repo.Update(context.Background(), &domain.User{ID: john.ID, Name: "John", Email: "john@example.com"})
+		if updated.ExternalIDs["google"] != "1" {
+			t.Errorf("expected the google ID to be kept, got %v", updated.ExternalIDs)
+		}
+	})
+}
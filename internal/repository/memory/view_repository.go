@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// InMemoryViewRepository is a threadsafe in-memory implementation of ViewRepository.
+type InMemoryViewRepository struct {
+	*Store[domain.View, int64]
+
+	mu        sync.Mutex
+	autoIncID int64
+}
+
+func NewInMemoryViewRepository() *InMemoryViewRepository {
+	return &InMemoryViewRepository{Store: NewStore[domain.View, int64](StoreOptions[domain.View]{
+		NotFound: domain.ErrViewNotFound,
+		Less:     func(a, b *domain.View) bool { return a.ID < b.ID },
+	})}
+}
+
+func (r *InMemoryViewRepository) Create(ctx context.Context, view *domain.View) (*domain.View, error) {
+	if view == nil {
+		return nil, fmt.Errorf("%w: nil view", domain.ErrInvalidInput)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *view
+	copy.ID = r.autoIncID + 1
+	copy.CreatedAt = time.Now().UTC()
+	if !domain.IsDryRun(ctx) {
+		r.autoIncID++
+	}
+	return r.Put(ctx, copy.ID, &copy), nil
+}
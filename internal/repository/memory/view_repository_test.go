@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestInMemoryViewRepository(t *testing.T) {
+	t.Run("Create, list and delete", func(t *testing.T) {
+		repo := NewInMemoryViewRepository()
+		a, err := repo.Create(context.Background(), &domain.View{Name: "corp", Filter: `email endsWith "@corp.com"`})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		b, _ := repo.Create(context.Background(), &domain.View{Name: "recent"})
+		if a.ID == 0 || b.ID <= a.ID || a.CreatedAt.IsZero() {
+			t.Errorf("expected increasing IDs and a creation time, got %+v %+v", a, b)
+		}
+
+		views, _ := repo.List(context.Background())
+		if len(views) != 2 || views[0].ID != a.ID {
+			t.Errorf("expected views in ID order, got %v", views)
+		}
+		if err := repo.Delete(context.Background(), a.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.GetByID(context.Background(), a.ID); err == nil {
+			t.Error("expected error for deleted view")
+		}
+		if err := repo.Delete(context.Background(), a.ID); err == nil {
+			t.Error("expected error deleting a missing view")
+		}
+	})
+
+	t.Run("Nil view", func(t *testing.T) {
+		if _, err := NewInMemoryViewRepository().Create(context.Background(), nil); err == nil {
+			t.Error("expected error for nil view")
+		}
+	})
+}
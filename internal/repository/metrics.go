@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// repoMetrics is published at /debug/vars as "user_repository".
+// Keys are "<op>.calls", "<op>.errors" and "<op>.duration_ns".
+var repoMetrics = expvar.NewMap("user_repository")
+
+// WithMetrics counts calls, errors and cumulative latency per repository method.
+func WithMetrics() Decorator {
+	return func(next domain.UserRepository) domain.UserRepository {
+		return &metricsRepository{next: next}
+	}
+}
+
+type metricsRepository struct {
+	next domain.UserRepository
+}
+
+func observe(op string, start time.Time, err error) {
+	repoMetrics.Add(op+".calls", 1)
+	repoMetrics.Add(op+".duration_ns", int64(time.Since(start)))
+	if err != nil {
+		repoMetrics.Add(op+".errors", 1)
+	}
+}
+
+func (m *metricsRepository) Create(ctx context.Context, user *domain.User) (u *domain.User, err error) {
+	defer func(start time.Time) { observe("create", start, err) }(time.Now())
+	return m.next.Create(ctx, user)
+}
+
+func (m *metricsRepository) GetByID(ctx context.Context, id int64) (u *domain.User, err error) {
+	defer func(start time.Time) { observe("get_by_id", start, err) }(time.Now())
+	return m.next.GetByID(ctx, id)
+}
+
+func (m *metricsRepository) GetByEmail(ctx context.Context, email string) (u *domain.User, err error) {
+	defer func(start time.Time) { observe("get_by_email", start, err) }(time.Now())
+	return m.next.GetByEmail(ctx, email)
+}
+
+func (m *metricsRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) (users []*domain.User, err error) {
+	defer func(start time.Time) { observe("search_by_name_prefix", start, err) }(time.Now())
+	return m.next.SearchByNamePrefix(ctx, prefix, limit)
+}
+
+func (m *metricsRepository) GetByExternalID(ctx context.Context, provider, subject string) (u *domain.User, err error) {
+	defer func(start time.Time) { observe("get_by_external_id", start, err) }(time.Now())
+	return m.next.GetByExternalID(ctx, provider, subject)
+}
+
+func (m *metricsRepository) List(ctx context.Context, filter domain.Filter) (page *domain.Page[domain.User], err error) {
+	defer func(start time.Time) { observe("list", start, err) }(time.Now())
+	return m.next.List(ctx, filter)
+}
+
+func (m *metricsRepository) Count(ctx context.Context, filter domain.Filter) (n int, err error) {
+	defer func(start time.Time) { observe("count", start, err) }(time.Now())
+	return m.next.Count(ctx, filter)
+}
+
+func (m *metricsRepository) Update(ctx context.Context, user *domain.User) (u *domain.User, err error) {
+	defer func(start time.Time) { observe("update", start, err) }(time.Now())
+	return m.next.Update(ctx, user)
+}
+
+func (m *metricsRepository) Delete(ctx context.Context, id int64, version int64) (err error) {
+	defer func(start time.Time) { observe("delete", start, err) }(time.Now())
+	return m.next.Delete(ctx, id, version)
+}
+
+func (m *metricsRepository) LastModified(ctx context.Context) (t time.Time, err error) {
+	defer func(start time.Time) { observe("last_modified", start, err) }(time.Now())
+	return m.next.LastModified(ctx)
+}
+
+func (m *metricsRepository) Stats(ctx context.Context, q domain.StatsQuery) (buckets []domain.StatsBucket, err error) {
+	defer func(start time.Time) { observe("stats", start, err) }(time.Now())
+	return m.next.Stats(ctx, q)
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+// implementations lists every UserRepository the properties must hold for.
+var implementations = map[string]func() domain.UserRepository{
+	"memory": func() domain.UserRepository { return memory.NewInMemoryUserRepository() },
+	"memory+decorators": func() domain.UserRepository {
+		return Wrap(memory.NewInMemoryUserRepository(),
+			WithMetrics(),
+			WithRetry(RetryPolicy{Attempts: 2}),
+			WithCache(NewMemoryCache(time.Minute)),
+		)
+	},
+}
+
+// emailSeq keeps generated emails unique, since repositories reject duplicates.
+var emailSeq atomic.Int64
+
+func uniqueEmail(s string) string {
+	return fmt.Sprintf("%d.%s", emailSeq.Add(1), s)
+}
+
+func checkProperty(t *testing.T, property any) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProperty_CreateThenGet(t *testing.T) {
+	for name, newRepo := range implementations {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo()
+			checkProperty(t, func(userName, email string) bool {
+				email = uniqueEmail(email)
+				created, err := repo.Create(context.Background(), &domain.User{Name: userName, Email: email})
+				if err != nil {
+					return false
+				}
+				got, err := repo.GetByID(context.Background(), created.ID)
+				return err == nil && reflect.DeepEqual(got, created) && got.Name == userName && got.Email == email
+			})
+		})
+	}
+}
+
+func TestProperty_UpdateKeepsCreatedAt(t *testing.T) {
+	for name, newRepo := range implementations {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepo()
+			checkProperty(t, func(before, after string) bool {
+				created, err := repo.Create(context.Background(), &domain.User{Name: before, Email: uniqueEmail(before)})
+				if err != nil {
+					return false
+				}
+				updated, err := repo.Update(context.Background(), &domain.User{ID: created.ID, Name: after, Email: uniqueEmail(after), CreatedAt: time.Unix(0, 0)})
+				if err != nil {
+					return false
+				}
+				got, _ := repo.GetByID(context.Background(), created.ID)
+				return updated.CreatedAt.Equal(created.CreatedAt) && got.CreatedAt.Equal(created.CreatedAt)
+			})
+		})
+	}
+}
+
+func TestProperty_ListLengthTracksCreatesAndDeletes(t *testing.T) {
+	for name, newRepo := range implementations {
+		t.Run(name, func(t *testing.T) {
+			// ops is a random program: true creates a user, false deletes the oldest live one.
+			checkProperty(t, func(ops []bool) bool {
+				repo := newRepo()
+				var live []int64
+				for _, create := range ops {
+					if create || len(live) == 0 {
+						u, err := repo.Create(context.Background(), &domain.User{Name: "n", Email: uniqueEmail("e")})
+						if err != nil {
+							return false
+						}
+						live = append(live, u.ID)
+						continue
+					}
+					if err := repo.Delete(context.Background(), live[0], 0); err != nil {
+						return false
+					}
+					live = live[1:]
+				}
+				page, err := repo.List(context.Background(), domain.Filter{})
+				return err == nil && len(page.Items) == len(live) && page.Total == len(live)
+			})
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cleanarch/internal/deadline"
+	"cleanarch/internal/domain"
+)
+
+// RetryPolicy configures WithRetry.
+type RetryPolicy struct {
+	// Attempts is the total number of tries, including the first one.
+	Attempts int
+	// Backoff is the delay before the first retry; it doubles on each retry.
+	Backoff time.Duration
+	// Retryable reports whether an error is worth retrying. Nil retries
+	// the errors Transient accepts.
+	Retryable func(error) bool
+}
+
+// Transient reports whether err may go away on retry: it wraps
+// domain.ErrUnavailable, or an error whose Temporary method reports true,
+// as net errors do. Everything else, including errors the backend didn't
+// classify, is final, and so are context errors.
+func Transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, domain.ErrUnavailable) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// WithRetry retries read operations according to p. Writes are passed
+// through untouched because Create is not idempotent.
+func WithRetry(p RetryPolicy) Decorator {
+	return func(next domain.UserRepository) domain.UserRepository {
+		return &retryRepository{UserRepository: next, policy: p}
+	}
+}
+
+type retryRepository struct {
+	domain.UserRepository
+	policy RetryPolicy
+}
+
+// do runs fn until it succeeds, the attempts are used up or ctx is done.
+// It gives up early when the backoff would outlast ctx's deadline.
+func (r *retryRepository) do(ctx context.Context, fn func() error) error {
+	backoff := r.policy.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= r.policy.Attempts {
+			return err
+		}
+		retryable := r.policy.Retryable
+		if retryable == nil {
+			retryable = Transient
+		}
+		if !retryable(err) {
+			return err
+		}
+		if !deadline.Covers(ctx, backoff) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (r *retryRepository) GetByID(ctx context.Context, id int64) (user *domain.User, err error) {
+	err = r.do(ctx, func() error {
+		user, err = r.UserRepository.GetByID(ctx, id)
+		return err
+	})
+	return user, err
+}
+
+func (r *retryRepository) GetByEmail(ctx context.Context, email string) (user *domain.User, err error) {
+	err = r.do(ctx, func() error {
+		user, err = r.UserRepository.GetByEmail(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+func (r *retryRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) (users []*domain.User, err error) {
+	err = r.do(ctx, func() error {
+		users, err = r.UserRepository.SearchByNamePrefix(ctx, prefix, limit)
+		return err
+	})
+	return users, err
+}
+
+func (r *retryRepository) GetByExternalID(ctx context.Context, provider, subject string) (user *domain.User, err error) {
+	err = r.do(ctx, func() error {
+		user, err = r.UserRepository.GetByExternalID(ctx, provider, subject)
+		return err
+	})
+	return user, err
+}
+
+func (r *retryRepository) List(ctx context.Context, filter domain.Filter) (page *domain.Page[domain.User], err error) {
+	err = r.do(ctx, func() error {
+		page, err = r.UserRepository.List(ctx, filter)
+		return err
+	})
+	return page, err
+}
+
+func (r *retryRepository) Count(ctx context.Context, filter domain.Filter) (n int, err error) {
+	err = r.do(ctx, func() error {
+		n, err = r.UserRepository.Count(ctx, filter)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryRepository) LastModified(ctx context.Context) (t time.Time, err error) {
+	err = r.do(ctx, func() error {
+		t, err = r.UserRepository.LastModified(ctx)
+		return err
+	})
+	return t, err
+}
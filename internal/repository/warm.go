@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"cleanarch/internal/domain"
+)
+
+// Warm reads the n most recently updated users through repo by ID so that a
+// cache decorator in repo holds them before traffic arrives. It returns how
+// many users were loaded.
+func Warm(ctx context.Context, repo domain.UserRepository, n int) (int, error) {
+	if n <= 0 {
+		return 0, nil
+	}
+	page, err := repo.List(ctx, domain.Filter{})
+	if err != nil {
+		return 0, err
+	}
+	users := page.Items
+	sort.Slice(users, func(i, j int) bool { return users[i].UpdatedAt.After(users[j].UpdatedAt) })
+	if len(users) > n {
+		users = users[:n]
+	}
+	for i, u := range users {
+		if _, err := repo.GetByID(ctx, u.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(users), nil
+}
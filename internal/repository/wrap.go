@@ -0,0 +1,18 @@
+// Package repository holds cross-cutting decorators for domain.UserRepository
+// implementations. Backends live in subpackages (see memory).
+package repository
+
+import "cleanarch/internal/domain"
+
+// Decorator adds behavior around a UserRepository.
+type Decorator func(domain.UserRepository) domain.UserRepository
+
+// Wrap applies decorators to base. The first decorator is the outermost,
+// so Wrap(base, WithMetrics(), WithCache(c)) measures cache hits too.
+func Wrap(base domain.UserRepository, decorators ...Decorator) domain.UserRepository {
+	repo := base
+	for i := len(decorators) - 1; i >= 0; i-- {
+		repo = decorators[i](repo)
+	}
+	return repo
+}
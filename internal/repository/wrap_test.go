@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/fieldcrypt"
+	"cleanarch/internal/repository/memory"
+)
+
+// countingRepository counts GetByID calls and fails the first failures of them.
+type countingRepository struct {
+	domain.UserRepository
+	gets     int
+	failures int
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	r.gets++
+	if r.gets <= r.failures {
+		return nil, fmt.Errorf("%w: connection reset", domain.ErrUnavailable)
+	}
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func TestWrap(t *testing.T) {
+	t.Run("No decorators returns base", func(t *testing.T) {
+		base := memory.NewInMemoryUserRepository()
+		if Wrap(base) != domain.UserRepository(base) {
+			t.Error("expected base repository to be returned unchanged")
+		}
+	})
+
+	t.Run("First decorator is outermost", func(t *testing.T) {
+		var order []string
+		named := func(name string) Decorator {
+			return func(next domain.UserRepository) domain.UserRepository {
+				order = append(order, name)
+				return next
+			}
+		}
+		Wrap(memory.NewInMemoryUserRepository(), named("outer"), named("inner"))
+		if len(order) != 2 || order[0] != "inner" || order[1] != "outer" {
+			t.Errorf("expected inner to wrap base first, got %v", order)
+		}
+	})
+}
+
+func TestWithCache(t *testing.T) {
+	t.Run("Get is served from cache", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository()}
+		repo := Wrap(base, WithCache(NewMemoryCache(time.Minute)))
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+
+		for i := 0; i < 3; i++ {
+			if _, err := repo.GetByID(context.Background(), created.ID); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if base.gets != 0 {
+			t.Errorf("expected no base reads, got %d", base.gets)
+		}
+	})
+
+	t.Run("Update refreshes cache", func(t *testing.T) {
+		repo := Wrap(memory.NewInMemoryUserRepository(), WithCache(NewMemoryCache(time.Minute)))
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		_, _ = repo.Update(context.Background(), &domain.User{ID: created.ID, Name: "Jane Doe", Email: "jane@example.com"})
+
+		user, _ := repo.GetByID(context.Background(), created.ID)
+		if user.Name != "Jane Doe" {
+			t.Errorf("expected name 'Jane Doe', got %s", user.Name)
+		}
+	})
+
+	t.Run("Delete evicts cache", func(t *testing.T) {
+		repo := Wrap(memory.NewInMemoryUserRepository(), WithCache(NewMemoryCache(time.Minute)))
+		created, _ := repo.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		_ = repo.Delete(context.Background(), created.ID, 0)
+
+		if _, err := repo.GetByID(context.Background(), created.ID); err == nil {
+			t.Error("expected error for deleted user")
+		}
+	})
+
+	t.Run("Expired entries miss", func(t *testing.T) {
+		c := NewMemoryCache(time.Millisecond)
+		c.Set(&domain.User{ID: 1})
+		time.Sleep(5 * time.Millisecond)
+		if _, ok := c.Get(1); ok {
+			t.Error("expected expired entry to miss")
+		}
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("Retries transient read errors", func(t *testing.T) {
+		inner := memory.NewInMemoryUserRepository()
+		created, _ := inner.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+		base := &countingRepository{UserRepository: inner, failures: 2}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 3}))
+
+		if _, err := repo.GetByID(context.Background(), created.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if base.gets != 3 {
+			t.Errorf("expected 3 attempts, got %d", base.gets)
+		}
+	})
+
+	t.Run("Gives up after attempts", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository(), failures: 5}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 2}))
+
+		if _, err := repo.GetByID(context.Background(), 1); err == nil {
+			t.Error("expected error after exhausting attempts")
+		}
+		if base.gets != 2 {
+			t.Errorf("expected 2 attempts, got %d", base.gets)
+		}
+	})
+
+	t.Run("Final errors are not retried by default", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository()}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 3}))
+
+		if _, err := repo.GetByID(context.Background(), 999); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("expected ErrUserNotFound, got %v", err)
+		}
+		if base.gets != 1 {
+			t.Errorf("expected 1 attempt, got %d", base.gets)
+		}
+	})
+
+	t.Run("Decrypt errors are not retried", func(t *testing.T) {
+		c, _ := fieldcrypt.New(bytes.Repeat([]byte{1}, fieldcrypt.KeySize))
+		fields, _ := domain.ParseSensitiveFields("email")
+		inner := memory.NewInMemoryUserRepository()
+		created, _ := inner.Create(context.Background(), &domain.User{Name: "John Doe", Email: "enc:v1:zz"})
+		base := &countingRepository{UserRepository: inner}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 3}), WithFieldEncryption(c, fields))
+
+		if _, err := repo.GetByID(context.Background(), created.ID); !errors.Is(err, fieldcrypt.ErrMalformed) {
+			t.Fatalf("expected ErrMalformed, got %v", err)
+		}
+		if base.gets != 1 {
+			t.Errorf("expected 1 attempt, got %d", base.gets)
+		}
+	})
+
+	t.Run("Non-retryable errors return immediately", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository(), failures: 5}
+		repo := Wrap(base, WithRetry(RetryPolicy{
+			Attempts:  3,
+			Retryable: func(error) bool { return false },
+		}))
+
+		_, _ = repo.GetByID(context.Background(), 1)
+		if base.gets != 1 {
+			t.Errorf("expected 1 attempt, got %d", base.gets)
+		}
+	})
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("get: %w", domain.ErrUnavailable), true},
+		{temporaryError{}, true},
+		{errors.New("connection reset"), false},
+		{fmt.Errorf("%w: %w", domain.ErrUnavailable, context.DeadlineExceeded), false},
+		{fmt.Errorf("get: %w", domain.ErrUserNotFound), false},
+		{domain.Invalid("email", "is required"), false},
+		{domain.ErrVersionConflict, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("expected Transient(%v) = %v, got %v", tt.err, tt.want, got)
+		}
+	}
+}
+
+// temporaryError is an unclassified error that reports itself temporary.
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "try again" }
+func (temporaryError) Temporary() bool { return true }
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("Counts calls and errors", func(t *testing.T) {
+		repo := Wrap(memory.NewInMemoryUserRepository(), WithMetrics())
+		calls := counter("get_by_id.calls")
+		errs := counter("get_by_id.errors")
+
+		_, _ = repo.GetByID(context.Background(), 999)
+
+		if got := counter("get_by_id.calls") - calls; got != 1 {
+			t.Errorf("expected 1 call recorded, got %d", got)
+		}
+		if got := counter("get_by_id.errors") - errs; got != 1 {
+			t.Errorf("expected 1 error recorded, got %d", got)
+		}
+	})
+}
+
+func counter(key string) int64 {
+	if v, ok := repoMetrics.Get(key).(interface{ Value() int64 }); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestWarm(t *testing.T) {
+	t.Run("Loads the most recently updated users into the cache", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository()}
+		var ids []int64
+		for _, name := range []string{"a", "b", "c"} {
+			u, _ := base.Create(context.Background(), &domain.User{Name: name, Email: name + "@example.com"})
+			ids = append(ids, u.ID)
+			time.Sleep(time.Millisecond)
+		}
+		_, _ = base.Update(context.Background(), &domain.User{ID: ids[0], Name: "a2", Email: "a@example.com"})
+
+		cache := NewMemoryCache(time.Minute)
+		n, err := Warm(context.Background(), Wrap(base, WithCache(cache)), 2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected 2 users warmed, got %d", n)
+		}
+		for _, id := range []int64{ids[0], ids[2]} {
+			if _, ok := cache.Get(id); !ok {
+				t.Errorf("expected user %d to be cached", id)
+			}
+		}
+		if _, ok := cache.Get(ids[1]); ok {
+			t.Error("expected the least recently updated user not to be cached")
+		}
+	})
+
+	t.Run("Zero does nothing", func(t *testing.T) {
+		if n, err := Warm(context.Background(), memory.NewInMemoryUserRepository(), 0); n != 0 || err != nil {
+			t.Errorf("expected no-op, got %d, %v", n, err)
+		}
+	})
+}
+
+func TestWithRetry_Context(t *testing.T) {
+	t.Run("Cancelled context stops retrying", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository(), failures: 10}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 5, Backoff: time.Hour}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := repo.GetByID(ctx, 1); err == nil {
+			t.Fatal("expected error")
+		}
+		if base.gets != 1 {
+			t.Errorf("expected a single attempt, got %d", base.gets)
+		}
+	})
+}
+
+func TestWithRetry_Deadline(t *testing.T) {
+	t.Run("Backoff past the deadline stops retrying", func(t *testing.T) {
+		base := &countingRepository{UserRepository: memory.NewInMemoryUserRepository(), failures: 10}
+		repo := Wrap(base, WithRetry(RetryPolicy{Attempts: 5, Backoff: time.Second}))
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := repo.GetByID(ctx, 1); err == nil {
+			t.Fatal("expected error")
+		}
+		if base.gets != 1 || time.Since(start) > 50*time.Millisecond {
+			t.Errorf("expected a single attempt without waiting, got %d in %s", base.gets, time.Since(start))
+		}
+	})
+}
+
+// deadlineRecorder records the time left on each call it sees.
+type deadlineRecorder struct {
+	domain.UserRepository
+	left []time.Duration
+}
+
+func (r *deadlineRecorder) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		r.left = append(r.left, 0)
+	} else {
+		r.left = append(r.left, time.Until(d))
+	}
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func TestWithDeadline(t *testing.T) {
+	t.Run("Calls get a share of the time left", func(t *testing.T) {
+		base := &deadlineRecorder{UserRepository: memory.NewInMemoryUserRepository()}
+		repo := Wrap(base, WithDeadline(0.5))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, _ = repo.GetByID(ctx, 1)
+		if left := base.left[0]; left > 500*time.Millisecond || left < 400*time.Millisecond {
+			t.Errorf("expected about 500ms, got %s", left)
+		}
+	})
+
+	t.Run("Calls without a deadline are unbounded", func(t *testing.T) {
+		base := &deadlineRecorder{UserRepository: memory.NewInMemoryUserRepository()}
+		_, _ = Wrap(base, WithDeadline(0.5)).GetByID(context.Background(), 1)
+		if base.left[0] != 0 {
+			t.Errorf("expected no deadline, got %s", base.left[0])
+		}
+	})
+}
+
+func TestWithFieldEncryption(t *testing.T) {
+	ctx := context.Background()
+	c, err := fieldcrypt.New(bytes.Repeat([]byte{1}, fieldcrypt.KeySize))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fields, _ := domain.ParseSensitiveFields("email,metadata.ssn")
+	base := memory.NewInMemoryUserRepository()
+	repo := Wrap(base, WithFieldEncryption(c, fields))
+	created, err := repo.Create(ctx, &domain.User{
+		Name:     "Ada",
+		Email:    "Ada@Example.com",
+		Metadata: map[string]any{"ssn": "078-05-1120", "plan": "pro"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("Stores sensitive fields encrypted", func(t *testing.T) {
+		stored, _ := base.GetByID(ctx, created.ID)
+		if !fieldcrypt.IsEncrypted(stored.Email) {
+			t.Errorf("expected encrypted email, got %q", stored.Email)
+		}
+		if s, _ := stored.Metadata["ssn"].(string); !fieldcrypt.IsEncrypted(s) {
+			t.Errorf("expected encrypted ssn, got %v", stored.Metadata["ssn"])
+		}
+		if stored.Metadata["plan"] != "pro" {
+			t.Errorf("expected plan stored in the clear, got %v", stored.Metadata["plan"])
+		}
+	})
+
+	t.Run("Reads decrypt", func(t *testing.T) {
+		got, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Email != "ada@example.com" || got.Metadata["ssn"] != "078-05-1120" {
+			t.Errorf("expected decrypted fields, got %q and %v", got.Email, got.Metadata["ssn"])
+		}
+	})
+
+	t.Run("Email lookups still match", func(t *testing.T) {
+		if got, err := repo.GetByEmail(ctx, "ADA@example.com"); err != nil || got.ID != created.ID {
+			t.Errorf("expected user %d, got %+v, %v", created.ID, got, err)
+		}
+		page, err := repo.List(ctx, domain.Filter{UserFilter: domain.UserFilter{EmailEq: "ada@example.com"}})
+		if err != nil || len(page.Items) != 1 || page.Items[0].Email != "ada@example.com" {
+			t.Errorf("expected one decrypted match, got %+v, %v", page, err)
+		}
+		if _, err := repo.Create(ctx, &domain.User{Name: "Ada", Email: "ada@EXAMPLE.com"}); !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("Queries on encrypted email are rejected", func(t *testing.T) {
+		expr, _ := domain.ParseExpr(`name = "Ada" OR email endsWith "@example.com"`)
+		for _, filter := range []domain.Filter{{Sort: domain.SortSpec{Field: domain.SortByEmail}}, {Expr: expr}} {
+			if _, err := repo.List(ctx, filter); !errors.Is(err, domain.ErrInvalidFilter) {
+				t.Errorf("expected ErrInvalidFilter, got %v", err)
+			}
+		}
+		if _, err := repo.Stats(ctx, domain.StatsQuery{GroupBy: domain.GroupByEmailDomain}); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
+
+func TestWithAuditEncryption(t *testing.T) {
+	ctx := context.Background()
+	c, err := fieldcrypt.New(bytes.Repeat([]byte{1}, fieldcrypt.KeySize))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	fields, _ := domain.ParseSensitiveFields("email,metadata.ssn")
+	base := memory.NewInMemoryAuditRepository()
+	log := WithAuditEncryption(c, fields)(base)
+	ada := &domain.User{ID: 1, Name: "Ada", Email: "ada@example.com", Metadata: map[string]any{"ssn": "078-05-1120"}}
+	if _, err := log.Append(ctx, &domain.AuditEntry{Action: domain.AuditCreate, EntityID: 1, After: ada}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("Stores snapshots encrypted", func(t *testing.T) {
+		page, _ := base.List(ctx, domain.AuditQuery{})
+		stored := page.Items[0].After
+		if s, _ := stored.Metadata["ssn"].(string); !fieldcrypt.IsEncrypted(stored.Email) || !fieldcrypt.IsEncrypted(s) {
+			t.Errorf("expected encrypted email and ssn, got %q and %v", stored.Email, stored.Metadata["ssn"])
+		}
+		if ada.Email != "ada@example.com" {
+			t.Error("expected the caller's user to be left unchanged")
+		}
+	})
+
+	t.Run("Reads decrypt", func(t *testing.T) {
+		page, err := log.List(ctx, domain.AuditQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := page.Items[0].After; got.Email != "ada@example.com" || got.Metadata["ssn"] != "078-05-1120" {
+			t.Errorf("expected decrypted fields, got %q and %v", got.Email, got.Metadata["ssn"])
+		}
+	})
+
+	t.Run("Redact sees plaintext and stores ciphertext", func(t *testing.T) {
+		err := log.Redact(ctx, 1, func(u *domain.User) {
+			if u.Email != "ada@example.com" {
+				t.Errorf("expected the decrypted email, got %q", u.Email)
+			}
+			u.Email = "redacted@example.com"
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		stored, _ := base.List(ctx, domain.AuditQuery{})
+		if !fieldcrypt.IsEncrypted(stored.Items[0].After.Email) {
+			t.Errorf("expected the redacted email stored encrypted, got %q", stored.Items[0].After.Email)
+		}
+		page, _ := log.List(ctx, domain.AuditQuery{})
+		if page.Items[0].After.Email != "redacted@example.com" {
+			t.Errorf("expected the redacted email, got %q", page.Items[0].After.Email)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+// Package slo tracks per-route service level objectives over a rolling
+// window and reports compliance and error-budget burn.
+package slo
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// trackers are the live trackers reported at /debug/vars as "slo", keyed by
+// objective name with compliance, burn_rate and budget_remaining values.
+var (
+	trackersMu sync.Mutex
+	trackers   []*Tracker
+)
+
+func init() {
+	expvar.Publish("slo", expvar.Func(func() any {
+		trackersMu.Lock()
+		defer trackersMu.Unlock()
+		out := make(map[string]map[string]float64)
+		for _, t := range trackers {
+			for _, st := range t.Report(time.Now()) {
+				out[st.Name] = map[string]float64{
+					"compliance":       st.Compliance,
+					"burn_rate":        st.BurnRate,
+					"budget_remaining": st.BudgetRemaining,
+				}
+			}
+		}
+		return out
+	}))
+}
+
+// Objective declares that Target of the requests matching Method and Route
+// succeed (status < 500) within Threshold.
+type Objective struct {
+	Name      string
+	Method    string // empty matches any method
+	Route     string // route pattern such as "/api/v1/users/{id}"; empty matches any route
+	Threshold time.Duration
+	Target    float64 // fraction, e.g. 0.999
+}
+
+// Parse reads objectives from a spec such as
+//
+//	reads=GET:200ms:99.9;get-user=GET /api/v1/users/{id}:100ms:99
+//
+// Each entry is name=MATCH:THRESHOLD:TARGET%, where MATCH is a method, a
+// method and route pattern, or "*" for every request.
+func Parse(spec string) ([]Objective, error) {
+	var objectives []Objective
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("slo %q: expected name=MATCH:THRESHOLD:TARGET", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("slo %q: duplicate name", name)
+		}
+		seen[name] = true
+
+		parts := strings.Split(rest, ":")
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("slo %q: expected MATCH:THRESHOLD:TARGET", name)
+		}
+		match := strings.TrimSpace(strings.Join(parts[:len(parts)-2], ":"))
+		threshold, err := time.ParseDuration(strings.TrimSpace(parts[len(parts)-2]))
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("slo %q: invalid threshold %q", name, parts[len(parts)-2])
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(parts[len(parts)-1]), "%"), 64)
+		if err != nil || pct <= 0 || pct >= 100 {
+			return nil, fmt.Errorf("slo %q: target must be a percentage between 0 and 100", name)
+		}
+
+		o := Objective{Name: name, Threshold: threshold, Target: pct / 100}
+		if match != "*" {
+			o.Method, o.Route, _ = strings.Cut(match, " ")
+			o.Route = strings.TrimSpace(o.Route)
+		}
+		objectives = append(objectives, o)
+	}
+	return objectives, nil
+}
+
+func (o Objective) matches(method, route string) bool {
+	return (o.Method == "" || o.Method == method) && (o.Route == "" || o.Route == route)
+}
+
+// slots is how many buckets the rolling window is divided into.
+const slots = 60
+
+type counts struct {
+	start       time.Time
+	good, total int64
+}
+
+type series struct {
+	Objective
+	buckets [slots]counts
+}
+
+// Tracker counts good and total requests per objective in a rolling window.
+type Tracker struct {
+	mu     sync.Mutex
+	window time.Duration
+	series []*series
+}
+
+// NewTracker tracks objectives over window, e.g. 24h.
+func NewTracker(objectives []Objective, window time.Duration) *Tracker {
+	t := &Tracker{window: window}
+	for _, o := range objectives {
+		t.series = append(t.series, &series{Objective: o})
+	}
+	trackersMu.Lock()
+	trackers = append(trackers, t)
+	trackersMu.Unlock()
+	return t
+}
+
+// Observe records a finished request for every objective it matches.
+func (t *Tracker) Observe(method, route string, status int, d time.Duration, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	width := t.window / slots
+	start := now.Truncate(width)
+	i := int(start.UnixNano()/int64(width)) % slots
+	for _, s := range t.series {
+		if !s.matches(method, route) {
+			continue
+		}
+		b := &s.buckets[i]
+		if !b.start.Equal(start) {
+			*b = counts{start: start}
+		}
+		b.total++
+		if status < http.StatusInternalServerError && d <= s.Threshold {
+			b.good++
+		}
+	}
+}
+
+// Status is an objective's compliance over the window. BurnRate is how fast
+// the error budget is being spent relative to the target (1 spends exactly
+// the budget over the window) and BudgetRemaining the unspent fraction.
+type Status struct {
+	Name            string  `json:"name"`
+	Method          string  `json:"method,omitempty"`
+	Route           string  `json:"route,omitempty"`
+	Threshold       string  `json:"threshold"`
+	Target          float64 `json:"target"`
+	Good            int64   `json:"good"`
+	Total           int64   `json:"total"`
+	Compliance      float64 `json:"compliance"`
+	BurnRate        float64 `json:"burn_rate"`
+	BudgetRemaining float64 `json:"budget_remaining"`
+}
+
+// Report computes every objective's status as of now.
+func (t *Tracker) Report(now time.Time) []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	oldest := now.Add(-t.window)
+	report := make([]Status, 0, len(t.series))
+	for _, s := range t.series {
+		st := Status{
+			Name:       s.Name,
+			Method:     s.Method,
+			Route:      s.Route,
+			Threshold:  s.Threshold.String(),
+			Target:     s.Target,
+			Compliance: 1,
+		}
+		for _, b := range s.buckets {
+			if b.start.After(oldest) {
+				st.Good += b.good
+				st.Total += b.total
+			}
+		}
+		if st.Total > 0 {
+			st.Compliance = float64(st.Good) / float64(st.Total)
+		}
+		st.BurnRate = (1 - st.Compliance) / (1 - s.Target)
+		st.BudgetRemaining = 1 - st.BurnRate
+		report = append(report, st)
+	}
+	return report
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Middleware observes every request served by next. It must wrap the
+// router directly so the matched pattern is visible once next returns.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		route := r.Pattern
+		if _, path, ok := strings.Cut(route, " "); ok {
+			route = path
+		}
+		t.Observe(r.Method, route, rec.status, time.Since(start), time.Now())
+	})
+}
+
+// Handler serves the current report as JSON.
+func (t *Tracker) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(t.Report(time.Now()))
+	})
+}
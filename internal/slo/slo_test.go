@@ -0,0 +1,96 @@
+package slo
+
+import (
+	"expvar"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Parses methods, routes and wildcards", func(t *testing.T) {
+		objs, err := Parse("reads=GET:200ms:99.9; get-user=GET /api/v1/users/{id}:100ms:99% ;all=*:1s:95")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(objs) != 3 {
+			t.Fatalf("expected 3 objectives, got %d", len(objs))
+		}
+		if o := objs[0]; o.Method != "GET" || o.Route != "" || o.Threshold != 200*time.Millisecond || math.Abs(o.Target-0.999) > 1e-9 {
+			t.Errorf("unexpected objective %+v", o)
+		}
+		if o := objs[1]; o.Method != "GET" || o.Route != "/api/v1/users/{id}" || o.Target != 0.99 {
+			t.Errorf("unexpected objective %+v", o)
+		}
+		if o := objs[2]; o.Method != "" || o.Route != "" {
+			t.Errorf("expected wildcard objective, got %+v", o)
+		}
+	})
+
+	t.Run("Rejects malformed specs", func(t *testing.T) {
+		for _, spec := range []string{"GET:1s:99", "a=GET:1s", "a=GET:soon:99", "a=GET:1s:100", "a=GET:1s:99;a=POST:1s:99"} {
+			if _, err := Parse(spec); err == nil {
+				t.Errorf("expected error for %q", spec)
+			}
+		}
+	})
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Compliance and burn rate", func(t *testing.T) {
+		tr := NewTracker([]Objective{{Name: "tracker_reads", Method: "GET", Threshold: 100 * time.Millisecond, Target: 0.9}}, time.Hour)
+		for i := 0; i < 8; i++ {
+			tr.Observe("GET", "/users", 200, time.Millisecond, now)
+		}
+		tr.Observe("GET", "/users", 200, time.Second, now)
+		tr.Observe("GET", "/users", 503, time.Millisecond, now)
+		tr.Observe("POST", "/users", 500, time.Millisecond, now)
+
+		st := tr.Report(now)[0]
+		if st.Good != 8 || st.Total != 10 {
+			t.Fatalf("expected 8/10 good, got %d/%d", st.Good, st.Total)
+		}
+		if math.Abs(st.BurnRate-2) > 1e-9 || math.Abs(st.BudgetRemaining+1) > 1e-9 {
+			t.Errorf("expected burn rate 2 and budget -1, got %v and %v", st.BurnRate, st.BudgetRemaining)
+		}
+	})
+
+	t.Run("Observations age out of the window", func(t *testing.T) {
+		tr := NewTracker([]Objective{{Name: "tracker_window", Threshold: time.Second, Target: 0.99}}, time.Hour)
+		tr.Observe("GET", "/users", 500, 0, now)
+
+		if st := tr.Report(now.Add(2 * time.Hour))[0]; st.Total != 0 || st.Compliance != 1 {
+			t.Errorf("expected empty window, got %+v", st)
+		}
+	})
+
+	t.Run("Middleware observes the matched route", func(t *testing.T) {
+		tr := NewTracker([]Objective{{Name: "tracker_route", Route: "/users/{id}", Threshold: time.Second, Target: 0.99}}, time.Hour)
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		tr.Middleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7", nil))
+		tr.Middleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+		st := tr.Report(time.Now())[0]
+		if st.Total != 1 || st.Good != 0 {
+			t.Errorf("expected one bad request, got %d/%d", st.Good, st.Total)
+		}
+	})
+}
+
+func TestExpvar(t *testing.T) {
+	tr := NewTracker([]Objective{{Name: "expvar_reads", Threshold: time.Second, Target: 0.5}}, time.Hour)
+	tr.Observe("GET", "/users", 500, 0, time.Now())
+
+	v := expvar.Get("slo").(expvar.Func).Value().(map[string]map[string]float64)
+	if got := v["expvar_reads"]["burn_rate"]; got != 2 {
+		t.Errorf("expected burn rate 2, got %v", got)
+	}
+}
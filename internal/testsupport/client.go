@@ -0,0 +1,166 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"cleanarch/internal/jsonpath"
+)
+
+// Client sends JSON requests to a base URL and fails the test on transport errors.
+type Client struct {
+	t      testing.TB
+	base   string
+	header http.Header
+}
+
+func NewClient(t testing.TB, baseURL string) *Client {
+	return &Client{t: t, base: baseURL, header: http.Header{}}
+}
+
+// WithHeader returns a copy of the client that sends the header on every request.
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := &Client{t: c.t, base: c.base, header: c.header.Clone()}
+	clone.header.Set(key, value)
+	return clone
+}
+
+func (c *Client) Get(path string) *Response            { return c.Do(http.MethodGet, path, nil) }
+func (c *Client) Post(path string, body any) *Response { return c.Do(http.MethodPost, path, body) }
+func (c *Client) Put(path string, body any) *Response  { return c.Do(http.MethodPut, path, body) }
+func (c *Client) Delete(path string) *Response         { return c.Do(http.MethodDelete, path, nil) }
+
+// Do sends a request; a non-nil body is encoded as JSON unless it is already a string.
+func (c *Client) Do(method, path string, body any) *Response {
+	c.t.Helper()
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = bytes.NewBufferString(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			c.t.Fatalf("encode request body: %v", err)
+		}
+		r = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.base+path, r)
+	if err != nil {
+		c.t.Fatalf("build request: %v", err)
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if r != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("read response body: %v", err)
+	}
+	return &Response{t: c.t, desc: method + " " + path, Status: resp.StatusCode, Header: resp.Header, Body: raw}
+}
+
+// Response is a buffered response with chainable assertions. Assertions
+// report failures with t.Errorf and keep going, so one run shows every mismatch.
+type Response struct {
+	t      testing.TB
+	desc   string
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	if r.Status != code {
+		r.t.Errorf("%s: expected status %d, got %d: %s", r.desc, code, r.Status, bytes.TrimSpace(r.Body))
+	}
+	return r
+}
+
+func (r *Response) ExpectHeader(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != value {
+		r.t.Errorf("%s: expected header %s %q, got %q", r.desc, key, value, got)
+	}
+	return r
+}
+
+// ExpectJSON asserts the value at a dotted path. Numbers compare as float64,
+// so ints in want are converted.
+func (r *Response) ExpectJSON(path string, want any) *Response {
+	r.t.Helper()
+	got, ok := jsonpath.Lookup(r.doc(), path)
+	if !ok {
+		r.t.Errorf("%s: field %q missing in %s", r.desc, path, bytes.TrimSpace(r.Body))
+		return r
+	}
+	if !reflect.DeepEqual(got, normalize(want)) {
+		r.t.Errorf("%s: field %q: expected %v, got %v", r.desc, path, want, got)
+	}
+	return r
+}
+
+// ExpectLen asserts the length of the array at path ("" for the top level).
+func (r *Response) ExpectLen(path string, n int) *Response {
+	r.t.Helper()
+	got, ok := jsonpath.Lookup(r.doc(), path)
+	arr, isArr := got.([]any)
+	if !ok || !isArr {
+		r.t.Errorf("%s: expected an array at %q", r.desc, path)
+		return r
+	}
+	if len(arr) != n {
+		r.t.Errorf("%s: expected %d items at %q, got %d", r.desc, n, path, len(arr))
+	}
+	return r
+}
+
+// Decode unmarshals the body into v, failing the test on invalid JSON.
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: decode body: %v", r.desc, err)
+	}
+	return r
+}
+
+// Field returns the value at a dotted path, failing the test when it is missing.
+func (r *Response) Field(path string) any {
+	r.t.Helper()
+	v, ok := jsonpath.Lookup(r.doc(), path)
+	if !ok {
+		r.t.Fatalf("%s: field %q missing", r.desc, path)
+	}
+	return v
+}
+
+func (r *Response) doc() any {
+	r.t.Helper()
+	var doc any
+	if err := json.Unmarshal(r.Body, &doc); err != nil {
+		r.t.Fatalf("%s: expected JSON body: %v", r.desc, err)
+	}
+	return doc
+}
+
+func normalize(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return v
+}
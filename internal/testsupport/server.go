@@ -0,0 +1,54 @@
+// Package testsupport boots the full service behind httptest and offers a
+// small fluent client for asserting on JSON responses, so integration tests
+// don't each re-implement server setup and response decoding.
+package testsupport
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"cleanarch/internal/app"
+	"cleanarch/internal/config"
+	"cleanarch/internal/usecase/fake"
+)
+
+// Backend selects what the booted server stores users in.
+type Backend string
+
+const (
+	// BackendMemory uses the in-memory repository.
+	BackendMemory Backend = "memory"
+	// BackendMock serves deterministic fake data (see usecase/fake).
+	BackendMock Backend = "mock"
+)
+
+// Server is a running test instance of the full service.
+type Server struct {
+	*httptest.Server
+	App *app.Server
+}
+
+// NewServer boots the service with the given backend and closes it when the
+// test ends. Request logging is silenced for the duration of the test.
+func NewServer(t testing.TB, backend Backend) *Server {
+	t.Helper()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	opts := app.ServerOptions{}
+	if backend == BackendMock {
+		opts.Mock = &fake.Options{}
+	}
+	a := app.NewServer(config.Default(), opts)
+	ts := httptest.NewServer(a.HTTP.Handler)
+	t.Cleanup(ts.Close)
+	return &Server{Server: ts, App: a}
+}
+
+// Client returns a client bound to the server and test.
+func (s *Server) Client(t testing.TB) *Client {
+	return NewClient(t, s.URL)
+}
@@ -0,0 +1,114 @@
+package testsupport
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("Memory backend round trip", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+
+		created := c.Post("/api/v1/users", map[string]string{"name": "John Doe", "email": "john@example.com"}).
+			ExpectStatus(http.StatusCreated).
+			ExpectJSON("name", "John Doe")
+		id := created.Field("id")
+
+		c.Get(fmt.Sprintf("/api/v1/users/%v", id)).
+			ExpectStatus(http.StatusOK).
+			ExpectJSON("email", "john@example.com")
+		c.Get("/api/v1/users").
+			ExpectStatus(http.StatusOK).
+			ExpectLen("items", 1).
+			ExpectJSON("items.0.id", id).
+			ExpectJSON("total", 1)
+	})
+
+	t.Run("Saved view results", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+		c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@corp.com"}).ExpectStatus(http.StatusCreated)
+		c.Post("/api/v1/users", map[string]string{"name": "Bob", "email": "bob@example.com"}).ExpectStatus(http.StatusCreated)
+
+		view := c.Post("/api/v1/views", map[string]string{"name": "corp", "filter": `email endsWith "@corp.com"`}).
+			ExpectStatus(http.StatusCreated)
+		c.Get(fmt.Sprintf("/api/v1/views/%v/results", view.Field("id"))).
+			ExpectStatus(http.StatusOK).
+			ExpectLen("items", 1).
+			ExpectJSON("items.0.name", "Ann")
+	})
+
+	t.Run("Concurrent editors", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+		created := c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@example.com"}).ExpectStatus(http.StatusCreated)
+		path := fmt.Sprintf("/api/v1/users/%v", created.Field("id"))
+		etag := c.Get(path).ExpectHeader("ETag", `"1"`).Header.Get("ETag")
+
+		c.WithHeader("If-Match", etag).Put(path, map[string]string{"name": "Ann B", "email": "ann@example.com"}).
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("ETag", `"2"`)
+		c.WithHeader("If-Match", etag).Put(path, map[string]string{"name": "Ann C", "email": "ann@example.com"}).
+			ExpectStatus(http.StatusPreconditionFailed)
+		c.Get(path).ExpectJSON("name", "Ann B")
+	})
+
+	t.Run("Organization members", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+		ann := c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@corp.com"}).ExpectStatus(http.StatusCreated).Field("id")
+		bob := c.Post("/api/v1/users", map[string]string{"name": "Bob", "email": "bob@corp.com"}).ExpectStatus(http.StatusCreated).Field("id")
+		members := fmt.Sprintf("/api/v1/orgs/%v/members", c.Post("/api/v1/orgs", map[string]string{"name": "Corp"}).ExpectStatus(http.StatusCreated).Field("id"))
+
+		c.Post(members, map[string]any{"user_id": ann}).ExpectStatus(http.StatusNoContent)
+		c.Post(members, map[string]any{"user_id": bob}).ExpectStatus(http.StatusNoContent)
+		c.Post(members, map[string]any{"user_id": bob}).ExpectStatus(http.StatusConflict)
+		c.Get(members).ExpectLen("", 2).ExpectJSON("0.name", "Ann")
+
+		c.Delete(fmt.Sprintf("/api/v1/users/%v", ann)).ExpectStatus(http.StatusConflict).ExpectJSON("references.0.kind", "organization")
+		c.Delete(fmt.Sprintf("/api/v1/users/%v?cascade=true", ann)).ExpectStatus(http.StatusNoContent)
+		c.Get(members).ExpectLen("", 1).ExpectJSON("0.name", "Bob")
+		c.Delete(fmt.Sprintf("%s/%v", members, bob)).ExpectStatus(http.StatusNoContent)
+		c.Get(members).ExpectLen("", 0)
+	})
+
+	t.Run("Profiles go with their user", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+		user := fmt.Sprintf("/api/v1/users/%v", c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@example.com"}).ExpectStatus(http.StatusCreated).Field("id"))
+
+		c.Put(user+"/profile", map[string]string{"bio": "Hi"}).ExpectStatus(http.StatusOK)
+		c.Get(user+"/profile").ExpectStatus(http.StatusOK).ExpectJSON("bio", "Hi")
+		c.Delete(user).ExpectStatus(http.StatusNoContent)
+		c.Get(user + "/profile").ExpectStatus(http.StatusNotFound)
+	})
+
+	t.Run("Anonymized users keep their record", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t)
+		user := fmt.Sprintf("/api/v1/users/%v", c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@example.com"}).ExpectStatus(http.StatusCreated).Field("id"))
+		c.Put(user+"/profile", map[string]string{"bio": "Hi"}).ExpectStatus(http.StatusOK)
+
+		c.Post(user+"/anonymize", nil).ExpectStatus(http.StatusOK).ExpectJSON("name", "Anonymized user")
+		c.Get(user).ExpectStatus(http.StatusOK).ExpectJSON("name", "Anonymized user")
+		c.Get(user + "/profile").ExpectStatus(http.StatusNotFound)
+	})
+
+	t.Run("Changes are audited", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t).WithHeader("X-User-ID", "42")
+		user := fmt.Sprintf("/api/v1/users/%v", c.Post("/api/v1/users", map[string]string{"name": "Ann", "email": "ann@example.com"}).ExpectStatus(http.StatusCreated).Field("id"))
+		c.Delete(user).ExpectStatus(http.StatusNoContent)
+
+		c.Get("/api/v1/audit").ExpectStatus(http.StatusOK).ExpectLen("items", 2).
+			ExpectJSON("items.0.action", "delete").ExpectJSON("items.0.before.name", "Ann").
+			ExpectJSON("items.1.action", "create").ExpectJSON("items.1.actor", 42)
+	})
+
+	t.Run("Mock backend serves canned users", func(t *testing.T) {
+		c := NewServer(t, BackendMock).Client(t)
+
+		c.Get("/api/v1/users/1").ExpectStatus(http.StatusOK).ExpectJSON("id", 1)
+		c.Get("/api/v1/users?limit=3").ExpectLen("items", 3).ExpectJSON("limit", 3)
+	})
+
+	t.Run("Headers are sent on every request", func(t *testing.T) {
+		c := NewServer(t, BackendMemory).Client(t).WithHeader("If-Modified-Since", "Fri, 01 Jan 2100 00:00:00 GMT")
+		c.Get("/api/v1/users").ExpectStatus(http.StatusNotModified)
+	})
+}
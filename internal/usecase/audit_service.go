@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+
+	"cleanarch/internal/domain"
+)
+
+// AuditUsecase is the audit log boundary consumed by delivery adapters.
+type AuditUsecase interface {
+	// ListAudit returns audit entries newest first.
+	ListAudit(ctx context.Context, q domain.AuditQuery) (*domain.Page[domain.AuditEntry], error)
+}
+
+var _ AuditUsecase = (*AuditService)(nil)
+
+// AuditService reads the audit log that UserService writes with
+// WithAuditLog.
+type AuditService struct {
+	log domain.AuditRepository
+}
+
+func NewAuditService(log domain.AuditRepository) *AuditService {
+	return &AuditService{log: log}
+}
+
+func (s *AuditService) ListAudit(ctx context.Context, q domain.AuditQuery) (*domain.Page[domain.AuditEntry], error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return s.log.List(ctx, q)
+}
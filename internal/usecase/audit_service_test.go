@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+func TestUserService_AuditLog(t *testing.T) {
+	t.Run("Records each stored mutation with its principal", func(t *testing.T) {
+		log := memory.NewInMemoryAuditRepository()
+		service := NewUserService(NewMockUserRepository(), WithAuditLog(log))
+		by := domain.Principal{ActorID: 7, SubjectID: 9}
+		ctx := domain.WithPrincipal(context.Background(), by)
+
+		created, _ := service.CreateUser(ctx, "John Doe", "john@example.com", "")
+		id := created.ID
+		_, _ = service.UpdateUser(ctx, id, "Jane Doe", "jane@example.com", "", 0)
+		_, _ = service.SuspendUser(ctx, id)
+		_ = service.DeleteUser(context.Background(), id, 0, false)
+
+		page, err := NewAuditService(log).ListAudit(context.Background(), domain.AuditQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := []domain.AuditAction{domain.AuditDelete, domain.AuditUpdate, domain.AuditUpdate, domain.AuditCreate}
+		if len(page.Items) != len(want) {
+			t.Fatalf("expected %d entries, got %d", len(want), len(page.Items))
+		}
+		for i, e := range page.Items {
+			if e.Action != want[i] || e.EntityID != id {
+				t.Errorf("expected %s of user %d, got %+v", want[i], id, e)
+			}
+		}
+
+		deleted, suspended, updated, create := page.Items[0], page.Items[1], page.Items[2], page.Items[3]
+		if create.Principal != by || create.Before != nil || create.After.Name != "John Doe" {
+			t.Errorf("unexpected create entry %+v", create)
+		}
+		if updated.Before.Name != "John Doe" || updated.After.Name != "Jane Doe" {
+			t.Errorf("expected the rename in the update entry, got %+v and %+v", updated.Before, updated.After)
+		}
+		if suspended.Before.Status != domain.StatusActive || suspended.After.Status != domain.StatusSuspended {
+			t.Errorf("expected the transition in the update entry, got %+v and %+v", suspended.Before, suspended.After)
+		}
+		if deleted.Principal != (domain.Principal{}) || deleted.Before.Name != "Jane Doe" || deleted.After != nil {
+			t.Errorf("unexpected delete entry %+v", deleted)
+		}
+	})
+
+	t.Run("Failed mutations record nothing", func(t *testing.T) {
+		log := memory.NewInMemoryAuditRepository()
+		service := NewUserService(NewMockUserRepository(), WithAuditLog(log))
+		_, _ = service.CreateUser(context.Background(), "", "john@example.com", "")
+		_ = service.DeleteUser(context.Background(), 999, 0, false)
+
+		page, _ := log.List(context.Background(), domain.AuditQuery{})
+		if page.Total != 0 {
+			t.Errorf("expected no entries, got %v", page.Items)
+		}
+	})
+}
+
+func TestAuditService(t *testing.T) {
+	t.Run("Malformed cursor", func(t *testing.T) {
+		_, err := NewAuditService(memory.NewInMemoryAuditRepository()).ListAudit(context.Background(), domain.AuditQuery{Cursor: "x"})
+		if !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
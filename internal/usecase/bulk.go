@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+const (
+	// BulkBatchSize is how many users a bulk operation lists and applies at a time.
+	BulkBatchSize = 100
+	// MaxBulkUsers caps how many users one bulk operation may touch.
+	MaxBulkUsers = 10000
+	// DefaultDeletePause is the default pause between bulk delete batches.
+	DefaultDeletePause = 100 * time.Millisecond
+	// ConfirmationTTL is how long a bulk delete dry run's token stays valid.
+	ConfirmationTTL = 5 * time.Minute
+	// pausedPoll is how often a paused operation checks whether it may go on.
+	pausedPoll = 50 * time.Millisecond
+)
+
+var (
+	// ErrOperationNotFound is returned for an unknown or forgotten operation ID.
+	ErrOperationNotFound = errors.New("operation not found")
+	// ErrConfirmationRequired is returned (wrapped) when a bulk delete lacks
+	// a valid token from a matching dry run.
+	ErrConfirmationRequired = errors.New("confirmation required")
+)
+
+// BulkUsecase is the bulk-operation boundary consumed by delivery adapters.
+type BulkUsecase interface {
+	BulkUpdate(ctx context.Context, req BulkUpdate) (*BulkResult, error)
+	BulkDelete(ctx context.Context, req BulkDelete) (*BulkResult, error)
+	Operation(ctx context.Context, id int64) (*Operation, error)
+}
+
+var _ BulkUsecase = (*BulkService)(nil)
+
+// UserPatch lists the fields a bulk update sets; nil fields are left alone.
+// Email is not patchable because emails are unique.
+type UserPatch struct {
+	Name   *string            `json:"name,omitempty"`
+	Status *domain.UserStatus `json:"status,omitempty"`
+}
+
+func (p UserPatch) validate() error {
+	if p.Name == nil && p.Status == nil {
+		return domain.Invalid("patch", "must set name or status")
+	}
+	if p.Name != nil && strings.TrimSpace(*p.Name) == "" {
+		return domain.Invalid("patch.name", "must not be empty")
+	}
+	if p.Status != nil && *p.Status != domain.StatusActive && *p.Status != domain.StatusSuspended {
+		return domain.Invalid("patch.status", fmt.Sprintf("must be one of %s, %s", domain.StatusActive, domain.StatusSuspended))
+	}
+	return nil
+}
+
+// BulkUpdate applies Patch to every user matching Filter. Filter must
+// constrain the listing; paging, Cursor and Sort are ignored.
+type BulkUpdate struct {
+	Filter domain.Filter
+	Patch  UserPatch
+	// DryRun, or a dry-run context, counts the matching users without
+	// changing them.
+	DryRun bool
+}
+
+// BulkDelete deletes every user matching Filter. A delete needs two calls:
+// a dry run, which returns a Confirmation, then the same filter and Cascade
+// again with Confirm set to its token.
+type BulkDelete struct {
+	Filter domain.Filter
+	// Cascade also removes the records that refer to each user, as
+	// UserUsecase.DeleteUser does; without it, a referenced user fails to
+	// delete.
+	Cascade bool
+	DryRun  bool
+	Confirm string
+}
+
+// Confirmation authorizes one bulk delete of the previewed users.
+type Confirmation struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BulkResult is returned when a bulk request is accepted. Operation is nil
+// for dry runs; Confirmation is set for bulk delete dry runs.
+type BulkResult struct {
+	Matched      int           `json:"matched"`
+	Operation    *Operation    `json:"operation,omitempty"`
+	Confirmation *Confirmation `json:"confirmation,omitempty"`
+}
+
+// AuditEntry records one user removed by a bulk operation. It names the
+// user by ID only, so audit sinks don't copy personal data into logs.
+type AuditEntry struct {
+	Operation int64
+	UserID    int64
+	At        time.Time
+}
+
+// pendingDelete is what a confirmation token was issued for. users is a
+// hash of the matched IDs, so the token covers exactly the previewed users.
+type pendingDelete struct {
+	filter    string
+	cascade   bool
+	matched   int
+	users     string
+	expiresAt time.Time
+}
+
+// BulkService runs bulk changes in the background through the user use
+// case, so hooks, validation rules and status transitions still apply to
+// each user.
+type BulkService struct {
+	users       UserUsecase
+	ops         *Operations
+	audit       func(context.Context, AuditEntry)
+	deletePause time.Duration
+	// paused, when set, holds operations between batches while it returns true.
+	paused func() bool
+
+	pendingMu sync.Mutex
+	pending   map[string]pendingDelete
+
+	// ctx is canceled by Stop to abandon running operations.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// BulkOption configures a BulkService.
+type BulkOption func(*BulkService)
+
+// WithAudit receives an entry for every user a bulk delete removes.
+func WithAudit(fn func(context.Context, AuditEntry)) BulkOption {
+	return func(s *BulkService) { s.audit = fn }
+}
+
+// WithDeletePause sets the pause between bulk delete batches, which bounds
+// the rate deletes reach the repository. Zero disables the pause.
+func WithDeletePause(d time.Duration) BulkOption {
+	return func(s *BulkService) { s.deletePause = d }
+}
+
+// WithPauseWhile holds running operations before their next batch while
+// paused returns true; the server pauses them in read-only mode.
+func WithPauseWhile(paused func() bool) BulkOption {
+	return func(s *BulkService) { s.paused = paused }
+}
+
+func NewBulkService(users UserUsecase, opts ...BulkOption) *BulkService {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &BulkService{
+		users:       users,
+		ops:         NewOperations(),
+		audit:       func(context.Context, AuditEntry) {},
+		deletePause: DefaultDeletePause,
+		pending:     make(map[string]pendingDelete),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Stop cancels running operations and waits for them to record their state.
+func (s *BulkService) Stop(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Operation returns the progress of a bulk operation.
+func (s *BulkService) Operation(ctx context.Context, id int64) (*Operation, error) {
+	op, ok := s.ops.Get(id)
+	if !ok {
+		return nil, ErrOperationNotFound
+	}
+	return &op, nil
+}
+
+func (s *BulkService) BulkUpdate(ctx context.Context, req BulkUpdate) (*BulkResult, error) {
+	if err := req.Patch.validate(); err != nil {
+		return nil, err
+	}
+	users, err := s.match(ctx, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun || domain.IsDryRun(ctx) {
+		return &BulkResult{Matched: len(users)}, nil
+	}
+	op := s.run(ctx, "bulk_update", users, 0, func(ctx context.Context, _ int64, u *domain.User) error {
+		return s.patch(ctx, u, req.Patch)
+	})
+	return &BulkResult{Matched: len(users), Operation: op}, nil
+}
+
+func (s *BulkService) BulkDelete(ctx context.Context, req BulkDelete) (*BulkResult, error) {
+	users, err := s.match(ctx, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	filter := filterKey(req.Filter)
+	if req.DryRun || domain.IsDryRun(ctx) {
+		c, err := s.confirmation(filter, req.Cascade, users)
+		if err != nil {
+			return nil, err
+		}
+		return &BulkResult{Matched: len(users), Confirmation: c}, nil
+	}
+	if err := s.confirm(req.Confirm, filter, req.Cascade, users); err != nil {
+		return nil, err
+	}
+	op := s.run(ctx, "bulk_delete", users, s.deletePause, func(ctx context.Context, opID int64, u *domain.User) error {
+		if err := s.users.DeleteUser(ctx, u.ID, 0, req.Cascade); err != nil {
+			return err
+		}
+		s.audit(ctx, AuditEntry{Operation: opID, UserID: u.ID, At: time.Now().UTC()})
+		return nil
+	})
+	return &BulkResult{Matched: len(users), Operation: op}, nil
+}
+
+// confirmation issues a single-use token for deleting the matched users.
+func (s *BulkService) confirmation(filter string, cascade bool, users []*domain.User) (*Confirmation, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	c := &Confirmation{Token: hex.EncodeToString(b), ExpiresAt: time.Now().Add(ConfirmationTTL).UTC()}
+
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	now := time.Now()
+	for token, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, token)
+		}
+	}
+	s.pending[c.Token] = pendingDelete{filter: filter, cascade: cascade, matched: len(users), users: usersKey(users), expiresAt: c.ExpiresAt}
+	return c, nil
+}
+
+// confirm consumes token, checking it was issued for the same filter and
+// that the filter still matches the users the dry run saw.
+func (s *BulkService) confirm(token, filter string, cascade bool, users []*domain.User) error {
+	if token == "" {
+		return fmt.Errorf("%w: run a dry run first and pass its token", ErrConfirmationRequired)
+	}
+	s.pendingMu.Lock()
+	p, ok := s.pending[token]
+	delete(s.pending, token)
+	s.pendingMu.Unlock()
+
+	switch {
+	case !ok || time.Now().After(p.expiresAt):
+		return fmt.Errorf("%w: unknown or expired token", ErrConfirmationRequired)
+	case p.filter != filter:
+		return fmt.Errorf("%w: token was issued for a different filter", ErrConfirmationRequired)
+	case p.cascade != cascade:
+		return fmt.Errorf("%w: token was issued with cascade %t", ErrConfirmationRequired, p.cascade)
+	case p.matched != len(users):
+		return fmt.Errorf("%w: filter now matches %d users, the dry run matched %d", ErrConfirmationRequired, len(users), p.matched)
+	case p.users != usersKey(users):
+		return fmt.Errorf("%w: filter now matches other users than the dry run", ErrConfirmationRequired)
+	}
+	return nil
+}
+
+// patch applies p to u. A status the user already has is left alone rather
+// than reported as an invalid transition. The rename is made at the version
+// u was matched at, so a user edited since fails with a version conflict.
+func (s *BulkService) patch(ctx context.Context, u *domain.User, p UserPatch) error {
+	if p.Name != nil {
+		if _, err := s.users.RenameUser(ctx, u.ID, *p.Name, u.Version); err != nil {
+			return err
+		}
+	}
+	if p.Status == nil || *p.Status == u.Status {
+		return nil
+	}
+	var err error
+	switch *p.Status {
+	case domain.StatusSuspended:
+		_, err = s.users.SuspendUser(ctx, u.ID)
+	case domain.StatusActive:
+		_, err = s.users.ActivateUser(ctx, u.ID)
+	}
+	return err
+}
+
+// match lists every user the filter selects, in ID order.
+func (s *BulkService) match(ctx context.Context, filter domain.Filter) ([]*domain.User, error) {
+	if !constrained(filter) {
+		return nil, fmt.Errorf("%w: a filter is required", domain.ErrInvalidInput)
+	}
+	filter.Sort = domain.SortSpec{Field: domain.SortByID}
+	filter.PageRequest = domain.PageRequest{Limit: BulkBatchSize}
+	filter.Cursor = ""
+	var matched []*domain.User
+	for {
+		page, err := s.users.ListUsers(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, page.Items...)
+		if len(matched) > MaxBulkUsers {
+			return nil, fmt.Errorf("%w: filter matches more than %d users", domain.ErrInvalidInput, MaxBulkUsers)
+		}
+		if page.NextCursor == "" {
+			return matched, nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// filterKey identifies the users a filter selects, for matching a bulk
+// delete against its dry run.
+func filterKey(f domain.Filter) string {
+	expr := ""
+	if f.Expr != nil {
+		expr = f.Expr.String()
+	}
+	return fmt.Sprintf("%q %q %s %s %q %d %q %q", f.NameContains, f.EmailEq,
+		f.CreatedAfter.Format(time.RFC3339Nano), f.CreatedBefore.Format(time.RFC3339Nano), f.Tag, f.OrgID, f.Status, expr)
+}
+
+// usersKey hashes the IDs of users, which match returns in ID order.
+func usersKey(users []*domain.User) string {
+	h := sha256.New()
+	for _, u := range users {
+		_ = binary.Write(h, binary.BigEndian, u.ID)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// constrained reports whether the filter selects on anything.
+func constrained(f domain.Filter) bool {
+	return f.UserFilter != (domain.UserFilter{}) || f.Status != "" || f.Expr != nil
+}
+
+// run starts an operation applying fn to users in batches of BulkBatchSize,
+// waiting pause between batches, and longer while the service is paused.
+// It outlives the request but keeps its values, and stops between batches
+// once the service is stopped.
+func (s *BulkService) run(ctx context.Context, kind string, users []*domain.User, pause time.Duration, fn func(ctx context.Context, opID int64, u *domain.User) error) *Operation {
+	id := s.ops.Start(kind, len(users))
+	op, _ := s.ops.Get(id)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.ctx, cancel)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		defer stop()
+		var firstErr error
+		failed := 0
+		for start := 0; start < len(users); start += BulkBatchSize {
+			if start > 0 && pause > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(pause):
+				}
+			}
+			for s.paused != nil && s.paused() && ctx.Err() == nil {
+				select {
+				case <-ctx.Done():
+				case <-time.After(pausedPoll):
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				s.ops.Finish(id, OperationCanceled, err)
+				return
+			}
+			batch := users[start:min(start+BulkBatchSize, len(users))]
+			batchFailed := 0
+			for _, u := range batch {
+				if err := fn(ctx, id, u); err != nil {
+					batchFailed++
+					if firstErr == nil {
+						firstErr = fmt.Errorf("user %d: %w", u.ID, err)
+					}
+				}
+			}
+			failed += batchFailed
+			s.ops.Progress(id, len(batch), batchFailed)
+		}
+		if failed > 0 {
+			s.ops.Finish(id, OperationFailed, fmt.Errorf("%d of %d users failed, first: %w", failed, len(users), firstErr))
+			return
+		}
+		s.ops.Finish(id, OperationSucceeded, nil)
+	}()
+	return &op
+}
@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+// newBulkFixture returns a bulk service over n users, every third one at corp.com.
+func newBulkFixture(t *testing.T, n int) (*BulkService, *UserService) {
+	t.Helper()
+	users := NewUserService(memory.NewInMemoryUserRepository())
+	for i := 1; i <= n; i++ {
+		host := "example.com"
+		if i%3 == 0 {
+			host = "corp.com"
+		}
+		if _, err := users.CreateUser(context.Background(), fmt.Sprintf("User %d", i), fmt.Sprintf("u%d@%s", i, host), ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	bulk := NewBulkService(users)
+	t.Cleanup(func() { _ = bulk.Stop(context.Background()) })
+	return bulk, users
+}
+
+func corpFilter(t *testing.T) domain.Filter {
+	t.Helper()
+	expr, err := domain.ParseExpr(`email endsWith "@corp.com"`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return domain.Filter{Expr: expr}
+}
+
+// waitOperation polls until the operation leaves the running state.
+func waitOperation(t *testing.T, s *BulkService, id int64) *Operation {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		op, err := s.Operation(context.Background(), id)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if op.State != OperationRunning {
+			return op
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("operation %d still running", id)
+	return nil
+}
+
+func TestBulkService_BulkUpdate(t *testing.T) {
+	suspended := domain.StatusSuspended
+
+	t.Run("Dry run counts without changing users", func(t *testing.T) {
+		bulk, users := newBulkFixture(t, 250)
+		result, err := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t), Patch: UserPatch{Status: &suspended}, DryRun: true})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Matched != 83 || result.Operation != nil {
+			t.Errorf("expected 83 matches and no operation, got %+v", result)
+		}
+		list, _ := users.ListUsers(context.Background(), domain.Filter{Status: domain.StatusSuspended})
+		if len(list.Items) != 0 {
+			t.Errorf("expected no suspended users, got %d", len(list.Items))
+		}
+	})
+
+	t.Run("Suspends every matching user across batches", func(t *testing.T) {
+		bulk, users := newBulkFixture(t, 250)
+		result, err := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t), Patch: UserPatch{Status: &suspended}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		op := waitOperation(t, bulk, result.Operation.ID)
+		if op.State != OperationSucceeded || op.Processed != 83 || op.Failed != 0 {
+			t.Errorf("expected 83 users processed successfully, got %+v", op)
+		}
+		list, _ := users.ListUsers(context.Background(), domain.Filter{Status: domain.StatusSuspended})
+		if len(list.Items) != 83 {
+			t.Errorf("expected 83 suspended users, got %d", len(list.Items))
+		}
+	})
+
+	t.Run("Users already in the target status are not failures", func(t *testing.T) {
+		bulk, users := newBulkFixture(t, 6)
+		_, _ = users.SuspendUser(context.Background(), 3)
+		result, _ := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t), Patch: UserPatch{Status: &suspended}})
+		if op := waitOperation(t, bulk, result.Operation.ID); op.State != OperationSucceeded {
+			t.Errorf("expected success, got %+v", op)
+		}
+	})
+
+	t.Run("Per-user failures fail the operation", func(t *testing.T) {
+		hooks := NewHooks()
+		hooks.Register(PreUpdate, func(ctx context.Context, u *domain.User) error {
+			if u.ID == 6 {
+				return fmt.Errorf("%w: protected", domain.ErrInvalidInput)
+			}
+			return nil
+		})
+		users := NewUserService(memory.NewInMemoryUserRepository(), WithHooks(hooks))
+		for i := 1; i <= 6; i++ {
+			_, _ = users.CreateUser(context.Background(), "User", fmt.Sprintf("u%d@corp.com", i), "")
+		}
+		bulk := NewBulkService(users)
+		defer bulk.Stop(context.Background())
+
+		result, _ := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t), Patch: UserPatch{Status: &suspended}})
+		op := waitOperation(t, bulk, result.Operation.ID)
+		if op.State != OperationFailed || op.Processed != 6 || op.Failed != 1 || op.Error == "" {
+			t.Errorf("expected 1 of 6 failed, got %+v", op)
+		}
+	})
+
+	t.Run("Rejects a missing filter or empty patch", func(t *testing.T) {
+		bulk, _ := newBulkFixture(t, 3)
+		name := "Renamed"
+		if _, err := bulk.BulkUpdate(context.Background(), BulkUpdate{Patch: UserPatch{Name: &name}}); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for missing filter, got %v", err)
+		}
+		if _, err := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t)}); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for empty patch, got %v", err)
+		}
+		deleted := domain.StatusDeleted
+		if _, err := bulk.BulkUpdate(context.Background(), BulkUpdate{Filter: corpFilter(t), Patch: UserPatch{Status: &deleted}}); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for deleted status, got %v", err)
+		}
+	})
+
+	t.Run("Unknown operation", func(t *testing.T) {
+		bulk, _ := newBulkFixture(t, 0)
+		if _, err := bulk.Operation(context.Background(), 42); !errors.Is(err, ErrOperationNotFound) {
+			t.Errorf("expected ErrOperationNotFound, got %v", err)
+		}
+	})
+}
+
+func TestOperations(t *testing.T) {
+	t.Run("Evicts the oldest finished operations", func(t *testing.T) {
+		ops := NewOperations()
+		running := ops.Start("test", 1)
+		for i := 0; i < MaxOperations+5; i++ {
+			ops.Finish(ops.Start("test", 1), OperationSucceeded, nil)
+		}
+		if _, ok := ops.Get(running); !ok {
+			t.Error("expected the running operation to be kept")
+		}
+		if _, ok := ops.Get(running + 1); ok {
+			t.Error("expected the oldest finished operation to be evicted")
+		}
+	})
+}
+
+func TestBulkService_BulkDelete(t *testing.T) {
+	t.Run("Requires a dry run token", func(t *testing.T) {
+		bulk, _ := newBulkFixture(t, 6)
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t)}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired, got %v", err)
+		}
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: "made-up"}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired for an unknown token, got %v", err)
+		}
+	})
+
+	t.Run("Deletes the previewed users and audits each", func(t *testing.T) {
+		var mu sync.Mutex
+		var audited []int64
+		users := NewUserService(memory.NewInMemoryUserRepository())
+		for i := 1; i <= 250; i++ {
+			_, _ = users.CreateUser(context.Background(), "User", fmt.Sprintf("u%d@corp.com", i), "")
+		}
+		_, _ = users.CreateUser(context.Background(), "Keep", "keep@example.com", "")
+		bulk := NewBulkService(users, WithDeletePause(time.Millisecond), WithAudit(func(ctx context.Context, e AuditEntry) {
+			mu.Lock()
+			audited = append(audited, e.UserID)
+			mu.Unlock()
+		}))
+		defer bulk.Stop(context.Background())
+
+		preview, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if preview.Matched != 250 || preview.Confirmation == nil || preview.Operation != nil {
+			t.Fatalf("expected 250 matches and a confirmation, got %+v", preview)
+		}
+		result, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		op := waitOperation(t, bulk, result.Operation.ID)
+		if op.State != OperationSucceeded || op.Processed != 250 {
+			t.Errorf("expected 250 users deleted, got %+v", op)
+		}
+		mu.Lock()
+		if len(audited) != 250 {
+			t.Errorf("expected 250 audit entries, got %d", len(audited))
+		}
+		mu.Unlock()
+		left, _ := users.ListUsers(context.Background(), domain.Filter{})
+		if len(left.Items) != 1 || left.Items[0].Name != "Keep" {
+			t.Errorf("expected only Keep to remain, got %v", left.Items)
+		}
+	})
+
+	t.Run("Tokens are single use and bound to the filter", func(t *testing.T) {
+		bulk, _ := newBulkFixture(t, 6)
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+
+		other := domain.Filter{Status: domain.StatusActive}
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: other, Confirm: preview.Confirmation.Token}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired for another filter, got %v", err)
+		}
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected the token to be spent, got %v", err)
+		}
+	})
+
+	t.Run("Referenced users need cascade", func(t *testing.T) {
+		refs := NewReferences()
+		users := NewUserService(memory.NewInMemoryUserRepository(), WithReferences(refs))
+		orgs := NewOrganizationService(memory.NewInMemoryOrganizationRepository(), users)
+		refs.Register(orgs)
+		org, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		for i := 1; i <= 3; i++ {
+			u, _ := users.CreateUser(context.Background(), "User", fmt.Sprintf("u%d@corp.com", i), "")
+			if i == 3 {
+				_ = orgs.AddMember(context.Background(), org.ID, u.ID)
+			}
+		}
+		bulk := NewBulkService(users)
+		defer bulk.Stop(context.Background())
+		deleteAll := func(cascade bool) *Operation {
+			t.Helper()
+			preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Cascade: cascade, DryRun: true})
+			result, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Cascade: cascade, Confirm: preview.Confirmation.Token})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			return waitOperation(t, bulk, result.Operation.ID)
+		}
+
+		if op := deleteAll(false); op.State != OperationFailed || op.Failed != 1 {
+			t.Errorf("expected the member to fail without cascade, got %+v", op)
+		}
+		if op := deleteAll(true); op.State != OperationSucceeded || op.Processed != 1 {
+			t.Errorf("expected the member deleted with cascade, got %+v", op)
+		}
+	})
+
+	t.Run("Tokens are bound to cascade", func(t *testing.T) {
+		bulk, _ := newBulkFixture(t, 6)
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Cascade: true, Confirm: preview.Confirmation.Token}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired, got %v", err)
+		}
+	})
+
+	t.Run("Rejects the token when the matches changed", func(t *testing.T) {
+		bulk, users := newBulkFixture(t, 6)
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		_, _ = users.CreateUser(context.Background(), "Late", "late@corp.com", "")
+
+		if _, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token}); !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired, got %v", err)
+		}
+	})
+
+	t.Run("Rejects the token when other users match", func(t *testing.T) {
+		bulk, users := newBulkFixture(t, 6)
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		_ = users.DeleteUser(context.Background(), 3, 0, false)
+		_, _ = users.CreateUser(context.Background(), "Late", "late@corp.com", "")
+
+		_, err := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token})
+		if !errors.Is(err, ErrConfirmationRequired) {
+			t.Errorf("expected ErrConfirmationRequired for the same count of other users, got %v", err)
+		}
+	})
+
+	t.Run("Waits between batches while paused", func(t *testing.T) {
+		users := NewUserService(memory.NewInMemoryUserRepository())
+		for i := 1; i <= 2*BulkBatchSize; i++ {
+			_, _ = users.CreateUser(context.Background(), "User", fmt.Sprintf("u%d@corp.com", i), "")
+		}
+		var paused atomic.Bool
+		bulk := NewBulkService(users, WithDeletePause(0), WithPauseWhile(paused.Load))
+		defer bulk.Stop(context.Background())
+		paused.Store(true)
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		result, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token})
+
+		time.Sleep(3 * pausedPoll)
+		if op, _ := bulk.Operation(context.Background(), result.Operation.ID); op.State != OperationRunning || op.Processed != 0 {
+			t.Fatalf("expected no users deleted while paused, got %+v", op)
+		}
+		paused.Store(false)
+		if op := waitOperation(t, bulk, result.Operation.ID); op.State != OperationSucceeded || op.Processed != 2*BulkBatchSize {
+			t.Errorf("expected every user deleted once resumed, got %+v", op)
+		}
+	})
+
+	t.Run("Stop cancels between batches", func(t *testing.T) {
+		users := NewUserService(memory.NewInMemoryUserRepository())
+		for i := 1; i <= 2*BulkBatchSize; i++ {
+			_, _ = users.CreateUser(context.Background(), "User", fmt.Sprintf("u%d@corp.com", i), "")
+		}
+		bulk := NewBulkService(users, WithDeletePause(time.Hour))
+		preview, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), DryRun: true})
+		result, _ := bulk.BulkDelete(context.Background(), BulkDelete{Filter: corpFilter(t), Confirm: preview.Confirmation.Token})
+		for op, _ := bulk.Operation(context.Background(), result.Operation.ID); op.Processed < BulkBatchSize; {
+			time.Sleep(time.Millisecond)
+			op, _ = bulk.Operation(context.Background(), result.Operation.ID)
+		}
+
+		if err := bulk.Stop(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		op, _ := bulk.Operation(context.Background(), result.Operation.ID)
+		if op.State != OperationCanceled || op.Processed != BulkBatchSize {
+			t.Errorf("expected cancellation after the first batch, got %+v", op)
+		}
+	})
+}
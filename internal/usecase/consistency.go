@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+// UserRecord is a record that refers to a user: Kind names the aggregate,
+// ID the record within it and UserID the user it refers to.
+type UserRecord struct {
+	Kind   string `json:"kind"`
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+}
+
+// OrphanSource is an aggregate whose records can outlive the user they
+// refer to, for example if a delete's cleanup failed.
+type OrphanSource interface {
+	// UserRecords lists every record in the source that refers to a user.
+	UserRecords(ctx context.Context) ([]UserRecord, error)
+	// RemoveUserRecord removes one record found by UserRecords.
+	RemoveUserRecord(ctx context.Context, rec UserRecord) error
+}
+
+// Orphan is a record whose user no longer exists.
+type Orphan struct {
+	UserRecord
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ConsistencyReport is the outcome of one consistency check.
+type ConsistencyReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Repair     bool      `json:"repair"`
+	// Checked counts the records examined.
+	Checked int      `json:"checked"`
+	Orphans []Orphan `json:"orphans"`
+	// Errors lists sources that couldn't be read; their records weren't checked.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Repaired counts the orphans that were removed.
+func (r *ConsistencyReport) Repaired() int {
+	n := 0
+	for _, o := range r.Orphans {
+		if o.Repaired {
+			n++
+		}
+	}
+	return n
+}
+
+// ConsistencyUsecase runs consistency checks and reports the latest.
+type ConsistencyUsecase interface {
+	Run(ctx context.Context) (*ConsistencyReport, error)
+	LastReport() *ConsistencyReport
+}
+
+var _ ConsistencyUsecase = (*ConsistencyChecker)(nil)
+
+// ConsistencyChecker finds records that refer to deleted users across the
+// registered sources, and removes them when repair is on.
+type ConsistencyChecker struct {
+	users  UserUsecase
+	repair bool
+	// paused, when set, holds repairs off while it returns true.
+	paused func() bool
+
+	mu      sync.Mutex
+	sources []OrphanSource
+	last    *ConsistencyReport
+}
+
+// NewConsistencyChecker checks records against users. With repair off it
+// only reports orphans.
+func NewConsistencyChecker(users UserUsecase, repair bool) *ConsistencyChecker {
+	return &ConsistencyChecker{users: users, repair: repair}
+}
+
+// Register adds a source to check.
+func (c *ConsistencyChecker) Register(source OrphanSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources = append(c.sources, source)
+}
+
+// PauseRepairWhile makes Run only report orphans, as with repair off, while
+// paused returns true; the server pauses repairs in read-only mode.
+func (c *ConsistencyChecker) PauseRepairWhile(paused func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+}
+
+// LastReport returns the most recent report, or nil before the first run.
+func (c *ConsistencyChecker) LastReport() *ConsistencyReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// Run checks every source once. A source that can't be read is recorded in
+// the report rather than failing the run; Run only fails if ctx ends.
+func (c *ConsistencyChecker) Run(ctx context.Context) (*ConsistencyReport, error) {
+	c.mu.Lock()
+	sources := c.sources
+	repair := c.repair && (c.paused == nil || !c.paused())
+	c.mu.Unlock()
+
+	report := &ConsistencyReport{StartedAt: time.Now().UTC(), Repair: repair, Orphans: []Orphan{}}
+	exists := make(map[int64]bool)
+	for _, source := range sources {
+		records, err := source.UserRecords(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		for _, rec := range records {
+			report.Checked++
+			ok, seen := exists[rec.UserID]
+			if !seen {
+				_, err := c.users.GetUser(ctx, rec.UserID)
+				switch {
+				case err == nil:
+					ok = true
+				case errors.Is(err, domain.ErrUserNotFound):
+					ok = false
+				case ctx.Err() != nil:
+					return nil, ctx.Err()
+				default:
+					report.Errors = append(report.Errors, fmt.Sprintf("looking up user %d: %v", rec.UserID, err))
+					continue
+				}
+				exists[rec.UserID] = ok
+			}
+			if ok {
+				continue
+			}
+			orphan := Orphan{UserRecord: rec}
+			if repair {
+				if err := source.RemoveUserRecord(ctx, rec); err != nil {
+					orphan.Error = err.Error()
+				} else {
+					orphan.Repaired = true
+				}
+			}
+			report.Orphans = append(report.Orphans, orphan)
+		}
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	c.mu.Lock()
+	c.last = report
+	c.mu.Unlock()
+	return report, nil
+}
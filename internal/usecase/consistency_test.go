@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+// failingSource is an OrphanSource that can't be read.
+type failingSource struct{}
+
+func (failingSource) UserRecords(ctx context.Context) ([]UserRecord, error) {
+	return nil, errors.New("unavailable")
+}
+
+func (failingSource) RemoveUserRecord(ctx context.Context, rec UserRecord) error {
+	return nil
+}
+
+func TestConsistencyChecker(t *testing.T) {
+	// newFixture leaves an orphaned membership and profile behind by
+	// deleting a user without the cleanup the server subscribes.
+	newFixture := func(t *testing.T, repair bool) (*ConsistencyChecker, *OrganizationService, *ProfileService, *domain.User) {
+		t.Helper()
+		users := NewUserService(NewMockUserRepository())
+		orgs := NewOrganizationService(memory.NewInMemoryOrganizationRepository(), users)
+		profiles := NewProfileService(memory.NewInMemoryProfileRepository(), users)
+		org, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		ann, _ := users.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		bob, _ := users.CreateUser(context.Background(), "Bob", "bob@corp.com", "")
+		for _, u := range []*domain.User{ann, bob} {
+			_ = orgs.AddMember(context.Background(), org.ID, u.ID)
+			_, _ = profiles.PutProfile(context.Background(), domain.Profile{UserID: u.ID})
+		}
+		_ = users.DeleteUser(context.Background(), ann.ID, 0, false)
+
+		checker := NewConsistencyChecker(users, repair)
+		checker.Register(orgs)
+		checker.Register(profiles)
+		return checker, orgs, profiles, ann
+	}
+
+	t.Run("Report only", func(t *testing.T) {
+		checker, orgs, _, ann := newFixture(t, false)
+		if checker.LastReport() != nil {
+			t.Error("expected no report before the first run")
+		}
+
+		report, err := checker.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if report.Checked != 4 || len(report.Orphans) != 2 || report.Repaired() != 0 {
+			t.Fatalf("expected 4 records checked and 2 unrepaired orphans, got %+v", report)
+		}
+		for _, o := range report.Orphans {
+			if o.UserID != ann.ID {
+				t.Errorf("expected orphans of user %d, got %+v", ann.ID, o)
+			}
+		}
+		if records, _ := orgs.UserRecords(context.Background()); len(records) != 2 {
+			t.Errorf("expected the orphaned membership to remain, got %v", records)
+		}
+		if checker.LastReport() != report {
+			t.Error("expected the run to be the last report")
+		}
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		checker, orgs, profiles, _ := newFixture(t, true)
+
+		report, _ := checker.Run(context.Background())
+		if len(report.Orphans) != 2 || report.Repaired() != 2 {
+			t.Fatalf("expected 2 repaired orphans, got %+v", report)
+		}
+		if records, _ := orgs.UserRecords(context.Background()); len(records) != 1 {
+			t.Errorf("expected one membership left, got %v", records)
+		}
+		if records, _ := profiles.UserRecords(context.Background()); len(records) != 1 {
+			t.Errorf("expected one profile left, got %v", records)
+		}
+		if report, _ := checker.Run(context.Background()); len(report.Orphans) != 0 {
+			t.Errorf("expected no orphans after repair, got %v", report.Orphans)
+		}
+	})
+
+	t.Run("Paused repair only reports", func(t *testing.T) {
+		checker, orgs, _, _ := newFixture(t, true)
+		paused := true
+		checker.PauseRepairWhile(func() bool { return paused })
+
+		report, _ := checker.Run(context.Background())
+		if report.Repair || len(report.Orphans) != 2 || report.Repaired() != 0 {
+			t.Fatalf("expected 2 unrepaired orphans, got %+v", report)
+		}
+		if records, _ := orgs.UserRecords(context.Background()); len(records) != 2 {
+			t.Errorf("expected the orphaned membership to remain, got %v", records)
+		}
+
+		paused = false
+		if report, _ := checker.Run(context.Background()); report.Repaired() != 2 {
+			t.Errorf("expected repairs to resume, got %+v", report)
+		}
+	})
+
+	t.Run("Unreadable sources are reported", func(t *testing.T) {
+		checker, _, _, _ := newFixture(t, false)
+		checker.Register(failingSource{})
+
+		report, err := checker.Run(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(report.Errors) != 1 || len(report.Orphans) != 2 {
+			t.Errorf("expected one error alongside the orphans, got %+v", report)
+		}
+	})
+}
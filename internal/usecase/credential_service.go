@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// CredentialUsecase is the password boundary consumed by delivery adapters.
+type CredentialUsecase interface {
+	// SetPassword creates or replaces the user's password. It returns
+	// ErrUserNotFound if the user doesn't exist.
+	SetPassword(ctx context.Context, userID int64, password string) error
+	// VerifyPassword returns ErrInvalidCredentials if the password doesn't
+	// match or the user has none.
+	VerifyPassword(ctx context.Context, userID int64, password string) error
+	DeletePassword(ctx context.Context, userID int64) error
+}
+
+// PasswordHasher hashes passwords for storage. Hashes must record their own
+// parameters so Verify works after the hasher's cost changes.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether a hash is weaker than Hash makes now.
+	NeedsRehash(hash string) bool
+}
+
+var (
+	_ CredentialUsecase = (*CredentialService)(nil)
+	_ OrphanSource      = (*CredentialService)(nil)
+)
+
+// RecordCredential is the record kind for credentials; the record ID is the
+// user's.
+const RecordCredential = "credential"
+
+// CredentialService manages user passwords. Only hashes are stored.
+type CredentialService struct {
+	credentials domain.CredentialRepository
+	users       UserUsecase
+	hasher      PasswordHasher
+	// dummyHash is verified against for users without a password, so a
+	// failed verify takes as long whether or not the user has one.
+	dummyHash func() (string, error)
+}
+
+func NewCredentialService(credentials domain.CredentialRepository, users UserUsecase, hasher PasswordHasher) *CredentialService {
+	return &CredentialService{
+		credentials: credentials,
+		users:       users,
+		hasher:      hasher,
+		dummyHash:   sync.OnceValues(func() (string, error) { return hasher.Hash("dummy password") }),
+	}
+}
+
+func (s *CredentialService) SetPassword(ctx context.Context, userID int64, password string) error {
+	if err := domain.ValidatePassword(password); err != nil {
+		return err
+	}
+	if _, err := s.users.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	_, err = s.credentials.Put(ctx, &domain.Credential{UserID: userID, Hash: hash})
+	return err
+}
+
+// VerifyPassword also upgrades a matching hash made at an older cost. The
+// upgrade is best effort: if it fails, the next successful verify retries.
+func (s *CredentialService) VerifyPassword(ctx context.Context, userID int64, password string) error {
+	cred, err := s.credentials.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrCredentialNotFound) {
+		if dummy, err := s.dummyHash(); err == nil {
+			_, _ = s.hasher.Verify(dummy, password)
+		}
+		return domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+	ok, err := s.hasher.Verify(cred.Hash, password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrInvalidCredentials
+	}
+	if s.hasher.NeedsRehash(cred.Hash) {
+		if hash, err := s.hasher.Hash(password); err == nil {
+			cred.Hash = hash
+			_, _ = s.credentials.Put(ctx, cred)
+		}
+	}
+	return nil
+}
+
+func (s *CredentialService) DeletePassword(ctx context.Context, userID int64) error {
+	return s.credentials.Delete(ctx, userID)
+}
+
+// UserRecords lists every credential.
+func (s *CredentialService) UserRecords(ctx context.Context) ([]UserRecord, error) {
+	creds, err := s.credentials.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]UserRecord, len(creds))
+	for i, c := range creds {
+		records[i] = UserRecord{Kind: RecordCredential, ID: c.UserID, UserID: c.UserID}
+	}
+	return records, nil
+}
+
+// RemoveUserRecord deletes a credential.
+func (s *CredentialService) RemoveUserRecord(ctx context.Context, rec UserRecord) error {
+	if err := s.credentials.Delete(ctx, rec.ID); err != nil && !errors.Is(err, domain.ErrCredentialNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/password"
+	"cleanarch/internal/repository/memory"
+)
+
+func TestCredentialService(t *testing.T) {
+	newFixture := func(t *testing.T) (*CredentialService, *memory.InMemoryCredentialRepository, *domain.User) {
+		t.Helper()
+		users := NewUserService(NewMockUserRepository())
+		user, err := users.CreateUser(context.Background(), "Ann", "ann@example.com", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		repo := memory.NewInMemoryCredentialRepository()
+		return NewCredentialService(repo, users, password.PBKDF2{Iterations: 1000}), repo, user
+	}
+
+	t.Run("Set then verify", func(t *testing.T) {
+		creds, repo, user := newFixture(t)
+		if err := creds.SetPassword(context.Background(), user.ID, "correct horse"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if strings.Contains(stored.Hash, "correct horse") {
+			t.Errorf("expected only a hash to be stored, got %q", stored.Hash)
+		}
+		if err := creds.VerifyPassword(context.Background(), user.ID, "correct horse"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if err := creds.VerifyPassword(context.Background(), user.ID, "battery staple"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("Verify without a password", func(t *testing.T) {
+		_, repo, user := newFixture(t)
+		hasher := &countingHasher{PasswordHasher: password.PBKDF2{Iterations: 1000}}
+		creds := NewCredentialService(repo, nil, hasher)
+		if err := creds.VerifyPassword(context.Background(), user.ID, "correct horse"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+		if hasher.verifies != 1 {
+			t.Errorf("expected a verify against a dummy hash, got %d verifies", hasher.verifies)
+		}
+	})
+
+	t.Run("Set rejects unknown users and short passwords", func(t *testing.T) {
+		creds, _, user := newFixture(t)
+		if err := creds.SetPassword(context.Background(), 99, "correct horse"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+		if err := creds.SetPassword(context.Background(), user.ID, "short"); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("Verify upgrades weaker hashes", func(t *testing.T) {
+		creds, repo, user := newFixture(t)
+		weak, _ := password.PBKDF2{Iterations: 10}.Hash("correct horse")
+		_, _ = repo.Put(context.Background(), &domain.Credential{UserID: user.ID, Hash: weak})
+
+		if err := creds.VerifyPassword(context.Background(), user.ID, "correct horse"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		stored, _ := repo.GetByID(context.Background(), user.ID)
+		if !strings.HasPrefix(stored.Hash, "$pbkdf2-sha256$i=1000$") {
+			t.Errorf("expected the hash to be upgraded, got %q", stored.Hash)
+		}
+	})
+}
+
+// countingHasher counts Verify calls.
+type countingHasher struct {
+	PasswordHasher
+	verifies int
+}
+
+func (h *countingHasher) Verify(hash, password string) (bool, error) {
+	h.verifies++
+	return h.PasswordHasher.Verify(hash, password)
+}
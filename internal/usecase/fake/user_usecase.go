@@ -0,0 +1,401 @@
+// Package fake provides a deterministic usecase.UserUsecase for mock server
+// mode, so frontends can develop against realistic responses without state.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/usecase"
+)
+
+var _ usecase.UserUsecase = (*UserUsecase)(nil)
+
+// ErrInjected is returned for calls selected by Options.ErrorRate.
+var ErrInjected = errors.New("injected failure")
+
+// Options configures the fake.
+type Options struct {
+	// Users is the number of canned users; defaults to 25.
+	Users int
+	// Latency is added to every call.
+	Latency time.Duration
+	// ErrorRate is the fraction of calls (0..1) that fail with ErrInjected.
+	ErrorRate float64
+	// Seed makes generated data and injected errors reproducible.
+	Seed int64
+}
+
+var (
+	firstNames = []string{"Ada", "Grace", "Alan", "Barbara", "Edsger", "Margaret", "Ken", "Radia", "Dennis", "Frances"}
+	lastNames  = []string{"Lovelace", "Hopper", "Turing", "Liskov", "Dijkstra", "Hamilton", "Thompson", "Perlman", "Ritchie", "Allen"}
+)
+
+// epoch anchors generated timestamps so responses don't change between runs.
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// UserUsecase serves canned users. Writes are validated and echoed back but
+// never change the dataset.
+type UserUsecase struct {
+	opts  Options
+	users []*domain.User
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func New(opts Options) *UserUsecase {
+	if opts.Users <= 0 {
+		opts.Users = 25
+	}
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	users := make([]*domain.User, opts.Users)
+	for i := range users {
+		first := firstNames[rnd.Intn(len(firstNames))]
+		last := lastNames[rnd.Intn(len(lastNames))]
+		created := epoch.Add(time.Duration(i) * 24 * time.Hour)
+		users[i] = &domain.User{
+			ID:        int64(i + 1),
+			Name:      first + " " + last,
+			Email:     fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+			Status:    domain.StatusActive,
+			Role:      domain.RoleMember,
+			Version:   1,
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+	}
+	return &UserUsecase{opts: opts, users: users, rnd: rnd}
+}
+
+// call applies the configured latency and error injection.
+func (f *UserUsecase) call(ctx context.Context) error {
+	if f.opts.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.opts.Latency):
+		}
+	}
+	if f.opts.ErrorRate <= 0 {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rnd.Float64() < f.opts.ErrorRate {
+		return ErrInjected
+	}
+	return nil
+}
+
+func (f *UserUsecase) find(id int64) (*domain.User, error) {
+	if id < 1 || id > int64(len(f.users)) {
+		return nil, domain.ErrUserNotFound
+	}
+	copy := *f.users[id-1]
+	return &copy, nil
+}
+
+func validate(name, email string, role domain.Role) error {
+	u := &domain.User{Name: strings.TrimSpace(name), Email: strings.TrimSpace(email), Role: role}
+	return u.Validate()
+}
+
+func (f *UserUsecase) CreateUser(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	if err := validate(name, email, role); err != nil {
+		return nil, err
+	}
+	if role == "" {
+		role = domain.RoleMember
+	}
+	now := epoch.Add(time.Duration(len(f.users)) * 24 * time.Hour)
+	return &domain.User{
+		ID:        int64(len(f.users) + 1),
+		Name:      strings.TrimSpace(name),
+		Email:     strings.TrimSpace(email),
+		Status:    domain.StatusActive,
+		Role:      role,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (f *UserUsecase) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	return f.find(id)
+}
+
+func (f *UserUsecase) GetUserByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	provider, subject = domain.NormalizeTag(provider), strings.TrimSpace(subject)
+	for _, u := range f.users {
+		if subject != "" && u.ExternalIDs[provider] == subject {
+			return f.find(u.ID)
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (f *UserUsecase) ListUsers(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	sortBy := filter.EffectiveSort()
+	var after *domain.User
+	if filter.Cursor != "" {
+		c, err := filter.DecodeCursor()
+		if err != nil {
+			return nil, err
+		}
+		after = c.Position()
+	}
+	total := 0
+	var users []*domain.User
+	for _, u := range f.users {
+		if !filter.Match(u) {
+			continue
+		}
+		total++
+		if after != nil && sortBy.Compare(u, after) <= 0 {
+			continue
+		}
+		users = append(users, u)
+	}
+	sort.SliceStable(users, func(i, j int) bool { return sortBy.Compare(users[i], users[j]) < 0 })
+	users = users[min(filter.Offset, len(users)):]
+	more := filter.Limit > 0 && len(users) > filter.Limit
+	if more {
+		users = users[:filter.Limit]
+	}
+	page := &domain.Page[domain.User]{Items: make([]*domain.User, len(users)), Total: total}
+	for i, u := range users {
+		copy := *u
+		page.Items[i] = &copy
+	}
+	if more {
+		page.NextCursor = domain.EncodeCursor(page.Items[len(page.Items)-1], sortBy)
+	}
+	return page, nil
+}
+
+func (f *UserUsecase) CountUsers(ctx context.Context, filter domain.Filter) (int, error) {
+	if err := f.call(ctx); err != nil {
+		return 0, err
+	}
+	filter.PageRequest, filter.Cursor = domain.PageRequest{}, ""
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, u := range f.users {
+		if filter.Match(u) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *UserUsecase) SearchUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || limit < 1 || limit > domain.MaxListLimit {
+		return nil, domain.ErrInvalidFilter
+	}
+	var result []*domain.User
+	for _, u := range f.users {
+		if strings.HasPrefix(strings.ToLower(u.Name), prefix) {
+			copy := *u
+			result = append(result, &copy)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result[:min(limit, len(result))], nil
+}
+
+func (f *UserUsecase) LastModified(ctx context.Context) (time.Time, error) {
+	if err := f.call(ctx); err != nil {
+		return time.Time{}, err
+	}
+	return f.users[len(f.users)-1].UpdatedAt, nil
+}
+
+func (f *UserUsecase) UserStats(ctx context.Context, q domain.StatsQuery) (*domain.Stats, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	q = q.Normalize()
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, u := range f.users {
+		counts[domain.GroupKey(u, q)]++
+	}
+	buckets := make([]domain.StatsBucket, 0, len(counts))
+	for key, n := range counts {
+		buckets = append(buckets, domain.StatsBucket{Key: key, Count: n})
+	}
+	return domain.NewStats(q, buckets), nil
+}
+
+func (f *UserUsecase) UpdateUser(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	if err := validate(name, email, role); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != u.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	u.Name = strings.TrimSpace(name)
+	u.Email = strings.TrimSpace(email)
+	if role != "" {
+		u.Role = role
+	}
+	u.Version++
+	return u, nil
+}
+
+func (f *UserUsecase) RenameUser(ctx context.Context, id int64, name string, version int64) (*domain.User, error) {
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return f.UpdateUser(ctx, id, name, u.Email, "", version)
+}
+
+func (f *UserUsecase) DeleteUser(ctx context.Context, id int64, version int64, cascade bool) error {
+	if err := f.call(ctx); err != nil {
+		return err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != u.Version {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func (f *UserUsecase) SuspendUser(ctx context.Context, id int64) (*domain.User, error) {
+	return f.transition(ctx, id, domain.StatusSuspended)
+}
+
+func (f *UserUsecase) ActivateUser(ctx context.Context, id int64) (*domain.User, error) {
+	return f.transition(ctx, id, domain.StatusActive)
+}
+
+func (f *UserUsecase) AnonymizeUser(ctx context.Context, id int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Anonymized() {
+		u.Anonymize()
+		u.Version++
+	}
+	return u, nil
+}
+
+func (f *UserUsecase) SetUserTags(ctx context.Context, id int64, tags []string, version int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != u.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	u.Tags = domain.NormalizeTags(tags)
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	u.Version++
+	return u, nil
+}
+
+func (f *UserUsecase) PatchUserMetadata(ctx context.Context, id int64, patch map[string]any, version int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != u.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	u.Metadata = domain.MergeMetadata(u.Metadata, patch)
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	u.Version++
+	return u, nil
+}
+
+func (f *UserUsecase) SetUserExternalIDs(ctx context.Context, id int64, ids map[string]string, version int64) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != u.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	u.ExternalIDs = domain.NormalizeExternalIDs(ids)
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	u.Version++
+	return u, nil
+}
+
+func (f *UserUsecase) transition(ctx context.Context, id int64, to domain.UserStatus) (*domain.User, error) {
+	if err := f.call(ctx); err != nil {
+		return nil, err
+	}
+	u, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.Transition(to); err != nil {
+		return nil, err
+	}
+	u.Version++
+	return u, nil
+}
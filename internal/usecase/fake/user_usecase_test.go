@@ -0,0 +1,133 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"cleanarch/internal/domain"
+)
+
+func TestUserUsecase(t *testing.T) {
+	t.Run("Same seed yields same data", func(t *testing.T) {
+		a, _ := New(Options{Seed: 7}).ListUsers(context.Background(), domain.Filter{})
+		b, _ := New(Options{Seed: 7}).ListUsers(context.Background(), domain.Filter{})
+		if len(a.Items) != 25 {
+			t.Fatalf("expected 25 users, got %d", len(a.Items))
+		}
+		for i := range a.Items {
+			if !reflect.DeepEqual(a.Items[i], b.Items[i]) {
+				t.Errorf("expected identical users at %d, got %+v and %+v", i, a.Items[i], b.Items[i])
+			}
+		}
+	})
+
+	t.Run("Get canned and missing users", func(t *testing.T) {
+		f := New(Options{Users: 3})
+		if u, err := f.GetUser(context.Background(), 2); err != nil || u.ID != 2 {
+			t.Errorf("expected user 2, got %+v, %v", u, err)
+		}
+		if _, err := f.GetUser(context.Background(), 4); err == nil {
+			t.Error("expected error for non-existent user")
+		}
+	})
+
+	t.Run("Writes do not change the dataset", func(t *testing.T) {
+		f := New(Options{Users: 3})
+		before, _ := f.GetUser(context.Background(), 1)
+		if _, err := f.UpdateUser(context.Background(), 1, "Changed", "changed@example.com", "", 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := f.CreateUser(context.Background(), "New", "new@example.com", ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		after, _ := f.GetUser(context.Background(), 1)
+		if !reflect.DeepEqual(before, after) {
+			t.Errorf("expected user unchanged, got %+v", after)
+		}
+		users, _ := f.ListUsers(context.Background(), domain.Filter{})
+		if len(users.Items) != 3 {
+			t.Errorf("expected 3 users, got %d", len(users.Items))
+		}
+	})
+
+	t.Run("Listings honor the sort order", func(t *testing.T) {
+		page, err := New(Options{}).ListUsers(context.Background(), domain.Filter{Sort: domain.ParseSortSpec("-created_at")})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for i := 1; i < len(page.Items); i++ {
+			if page.Items[i].CreatedAt.After(page.Items[i-1].CreatedAt) {
+				t.Fatalf("expected newest first, got %v before %v", page.Items[i-1].CreatedAt, page.Items[i].CreatedAt)
+			}
+		}
+	})
+
+	t.Run("Cursors page through the listing", func(t *testing.T) {
+		f := New(Options{})
+		filter := domain.Filter{Sort: domain.ParseSortSpec("name"), PageRequest: domain.PageRequest{Limit: 10}}
+		all, _ := f.ListUsers(context.Background(), domain.Filter{Sort: filter.Sort})
+
+		var seen []*domain.User
+		for pages := 0; pages < 2; pages++ {
+			page, err := f.ListUsers(context.Background(), filter)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if page.NextCursor == "" || page.Total != len(all.Items) {
+				t.Fatalf("expected a next cursor and the full total, got %q and %d", page.NextCursor, page.Total)
+			}
+			seen = append(seen, page.Items...)
+			filter.Cursor = page.NextCursor
+		}
+		for i, u := range seen {
+			if u.ID != all.Items[i].ID {
+				t.Fatalf("expected user %d at %d, got %d", all.Items[i].ID, i, u.ID)
+			}
+		}
+		last, _ := f.ListUsers(context.Background(), filter)
+		if len(last.Items) != 5 || last.NextCursor != "" {
+			t.Errorf("expected a last page of 5 without a cursor, got %d and %q", len(last.Items), last.NextCursor)
+		}
+	})
+
+	t.Run("Listings apply the filter", func(t *testing.T) {
+		page, err := New(Options{}).ListUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{NameContains: "ada"}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) == 0 || page.Total != len(page.Items) {
+			t.Fatalf("expected matching users counted in total, got %d of %d", len(page.Items), page.Total)
+		}
+		for _, u := range page.Items {
+			if !strings.Contains(strings.ToLower(u.Name), "ada") {
+				t.Errorf("expected name containing ada, got %q", u.Name)
+			}
+		}
+	})
+
+	t.Run("Validation still applies", func(t *testing.T) {
+		if _, err := New(Options{}).CreateUser(context.Background(), "", "x@example.com", ""); err == nil {
+			t.Error("expected error for empty name")
+		}
+	})
+
+	t.Run("Error injection", func(t *testing.T) {
+		f := New(Options{ErrorRate: 1})
+		if _, err := f.GetUser(context.Background(), 1); !errors.Is(err, ErrInjected) {
+			t.Errorf("expected ErrInjected, got %v", err)
+		}
+	})
+
+	t.Run("Latency injection", func(t *testing.T) {
+		f := New(Options{Latency: 20 * time.Millisecond})
+		start := time.Now()
+		_, _ = f.GetUser(context.Background(), 1)
+		if time.Since(start) < 20*time.Millisecond {
+			t.Error("expected call to be delayed")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// Stage names the point in a use case at which a hook runs.
+type Stage string
+
+const (
+	// PreCreate hooks run after built-in validation and before the user is
+	// stored. They may adjust the user or reject it by returning an error.
+	PreCreate Stage = "pre_create"
+	// PreUpdate hooks run after built-in validation and before the update is stored.
+	PreUpdate Stage = "pre_update"
+	// PreDelete hooks receive a user carrying only the ID being deleted.
+	PreDelete Stage = "pre_delete"
+	// PostCreate hooks run after the user was stored. An error is returned
+	// to the caller but does not undo the write. Post hooks don't run in a
+	// dry run (see domain.WithDryRun), since nothing was stored.
+	PostCreate Stage = "post_create"
+	// PostUpdate hooks run after the update was stored.
+	PostUpdate Stage = "post_update"
+	// PostDelete hooks run after the user was deleted.
+	PostDelete Stage = "post_delete"
+)
+
+func (s Stage) post() bool {
+	return s == PostCreate || s == PostUpdate || s == PostDelete
+}
+
+// Hook is a custom business rule or side effect attached to a Stage. Hooks
+// that reject a user should wrap domain.ErrInvalidInput so callers can tell
+// the rejection apart from a failure.
+type Hook func(ctx context.Context, user *domain.User) error
+
+// Hooks holds hooks registered by embedding applications. It is safe for
+// concurrent use; the zero value is ready to use.
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks map[Stage][]Hook
+}
+
+// NewHooks returns an empty hook registry.
+func NewHooks() *Hooks {
+	return &Hooks{}
+}
+
+// Register appends h to the hooks run at stage.
+func (h *Hooks) Register(stage Stage, hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.hooks == nil {
+		h.hooks = make(map[Stage][]Hook)
+	}
+	h.hooks[stage] = append(h.hooks[stage], hook)
+}
+
+// Run executes the hooks for stage in registration order and stops at the
+// first error, which is returned unchanged. A nil registry runs nothing.
+func (h *Hooks) Run(ctx context.Context, stage Stage, user *domain.User) error {
+	if h == nil || domain.IsDryRun(ctx) && stage.post() {
+		return nil
+	}
+	h.mu.RLock()
+	hooks := h.hooks[stage]
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
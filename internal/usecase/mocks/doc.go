@@ -0,0 +1,4 @@
+// Package mocks provides test doubles for the usecase package.
+package mocks
+
+//go:generate moq -out user_usecase.go -pkg mocks .. UserUsecase
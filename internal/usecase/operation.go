@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// OperationState is the progress of a long-running operation.
+type OperationState string
+
+const (
+	OperationRunning   OperationState = "running"
+	OperationSucceeded OperationState = "succeeded"
+	OperationFailed    OperationState = "failed"
+	// OperationCanceled means the operation was stopped before it finished,
+	// e.g. by server shutdown.
+	OperationCanceled OperationState = "canceled"
+)
+
+// MaxOperations caps how many operations are retained; the oldest finished
+// ones are forgotten first.
+const MaxOperations = 100
+
+// Operation reports the progress of a bulk job. Total is fixed when the job
+// starts; Processed counts users handled so far, including Failed ones.
+type Operation struct {
+	ID         int64          `json:"id"`
+	Kind       string         `json:"kind"`
+	State      OperationState `json:"state"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	Failed     int            `json:"failed"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Operations tracks operations in memory. It is safe for concurrent use.
+type Operations struct {
+	mu     sync.Mutex
+	nextID int64
+	ops    map[int64]*Operation
+	order  []int64
+}
+
+func NewOperations() *Operations {
+	return &Operations{ops: make(map[int64]*Operation)}
+}
+
+// Start records a new running operation and returns its ID.
+func (o *Operations) Start(kind string, total int) int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.nextID++
+	o.ops[o.nextID] = &Operation{
+		ID:        o.nextID,
+		Kind:      kind,
+		State:     OperationRunning,
+		Total:     total,
+		CreatedAt: time.Now().UTC(),
+	}
+	o.order = append(o.order, o.nextID)
+	o.evict()
+	return o.nextID
+}
+
+// evict drops the oldest finished operations beyond MaxOperations. Running
+// operations are never dropped.
+func (o *Operations) evict() {
+	for i := 0; len(o.ops) > MaxOperations && i < len(o.order); {
+		id := o.order[i]
+		if o.ops[id].State == OperationRunning {
+			i++
+			continue
+		}
+		delete(o.ops, id)
+		o.order = append(o.order[:i], o.order[i+1:]...)
+	}
+}
+
+// Get returns a snapshot of the operation.
+func (o *Operations) Get(id int64) (Operation, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op, ok := o.ops[id]
+	if !ok {
+		return Operation{}, false
+	}
+	return *op, true
+}
+
+// Progress adds to an operation's counters.
+func (o *Operations) Progress(id int64, processed, failed int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if op, ok := o.ops[id]; ok {
+		op.Processed += processed
+		op.Failed += failed
+	}
+}
+
+// Finish marks the operation as done in the given state.
+func (o *Operations) Finish(id int64, state OperationState, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op, ok := o.ops[id]
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	op.State = state
+	op.FinishedAt = &now
+	if err != nil {
+		op.Error = err.Error()
+	}
+}
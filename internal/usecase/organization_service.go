@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cleanarch/internal/domain"
+)
+
+// OrganizationUsecase is the organization boundary consumed by delivery adapters.
+type OrganizationUsecase interface {
+	CreateOrganization(ctx context.Context, name string) (*domain.Organization, error)
+	GetOrganization(ctx context.Context, id int64) (*domain.Organization, error)
+	ListOrganizations(ctx context.Context) ([]*domain.Organization, error)
+	DeleteOrganization(ctx context.Context, id int64) error
+
+	// AddMember returns ErrUserNotFound if the user doesn't exist.
+	AddMember(ctx context.Context, orgID, userID int64) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	// Members returns the organization's members in ID order.
+	Members(ctx context.Context, orgID int64) ([]*domain.User, error)
+}
+
+var (
+	_ OrganizationUsecase = (*OrganizationService)(nil)
+	_ ReferenceSource     = (*OrganizationService)(nil)
+	_ OrphanSource        = (*OrganizationService)(nil)
+)
+
+// ReferenceOrganization is the reference kind for organization memberships;
+// the reference ID is the organization's.
+const ReferenceOrganization = "organization"
+
+// OrganizationService manages organizations and their memberships. Users
+// are read through the user use case so their hooks and rules still apply.
+type OrganizationService struct {
+	orgs  domain.OrganizationRepository
+	users UserUsecase
+}
+
+func NewOrganizationService(orgs domain.OrganizationRepository, users UserUsecase) *OrganizationService {
+	return &OrganizationService{orgs: orgs, users: users}
+}
+
+func (s *OrganizationService) CreateOrganization(ctx context.Context, name string) (*domain.Organization, error) {
+	org := &domain.Organization{Name: strings.TrimSpace(name)}
+	if err := domain.Validate(org); err != nil {
+		return nil, err
+	}
+	return s.orgs.Create(ctx, org)
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, id int64) (*domain.Organization, error) {
+	return s.orgs.GetByID(ctx, id)
+}
+
+func (s *OrganizationService) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	return s.orgs.List(ctx)
+}
+
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id int64) error {
+	return s.orgs.Delete(ctx, id)
+}
+
+func (s *OrganizationService) AddMember(ctx context.Context, orgID, userID int64) error {
+	if _, err := s.orgs.GetByID(ctx, orgID); err != nil {
+		return err
+	}
+	if _, err := s.users.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.orgs.AddMember(ctx, orgID, userID)
+}
+
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	return s.orgs.RemoveMember(ctx, orgID, userID)
+}
+
+// Members skips members whose user no longer exists, which can happen if a
+// user is deleted while nothing calls RemoveUser.
+func (s *OrganizationService) Members(ctx context.Context, orgID int64) ([]*domain.User, error) {
+	ids, err := s.orgs.Members(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		u, err := s.users.GetUser(ctx, id)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// RemoveUser drops a user from every organization. The server calls it when
+// a user is deleted.
+func (s *OrganizationService) RemoveUser(ctx context.Context, userID int64) error {
+	return s.orgs.RemoveUser(ctx, userID)
+}
+
+// UserReferences lists the organizations the user is a member of.
+func (s *OrganizationService) UserReferences(ctx context.Context, userID int64) ([]domain.Reference, error) {
+	ids, err := s.orgs.Memberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]domain.Reference, len(ids))
+	for i, id := range ids {
+		refs[i] = domain.Reference{Kind: ReferenceOrganization, ID: id}
+	}
+	return refs, nil
+}
+
+// RemoveUserReferences drops the user from its organizations; restoring
+// adds it back to those that still exist.
+func (s *OrganizationService) RemoveUserReferences(ctx context.Context, userID int64) (func(context.Context) error, error) {
+	ids, err := s.orgs.Memberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orgs.RemoveUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		for _, id := range ids {
+			err := s.orgs.AddMember(ctx, id, userID)
+			if err != nil && !errors.Is(err, domain.ErrOrgNotFound) && !errors.Is(err, domain.ErrAlreadyMember) {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// UserRecords lists every membership, read from the repository directly so
+// members whose user is gone are included.
+func (s *OrganizationService) UserRecords(ctx context.Context) ([]UserRecord, error) {
+	orgs, err := s.orgs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []UserRecord
+	for _, org := range orgs {
+		ids, err := s.orgs.Members(ctx, org.ID)
+		if errors.Is(err, domain.ErrOrgNotFound) {
+			continue // deleted since the listing
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			records = append(records, UserRecord{Kind: ReferenceOrganization, ID: org.ID, UserID: id})
+		}
+	}
+	return records, nil
+}
+
+// RemoveUserRecord removes a membership.
+func (s *OrganizationService) RemoveUserRecord(ctx context.Context, rec UserRecord) error {
+	err := s.orgs.RemoveMember(ctx, rec.ID, rec.UserID)
+	if errors.Is(err, domain.ErrOrgNotFound) || errors.Is(err, domain.ErrMemberNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+func TestOrganizationService(t *testing.T) {
+	newFixture := func(t *testing.T) (*OrganizationService, *UserService) {
+		t.Helper()
+		users := NewUserService(NewMockUserRepository())
+		return NewOrganizationService(memory.NewInMemoryOrganizationRepository(), users), users
+	}
+
+	t.Run("Create requires a name", func(t *testing.T) {
+		orgs, _ := newFixture(t)
+		if _, err := orgs.CreateOrganization(context.Background(), "  "); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		org, err := orgs.CreateOrganization(context.Background(), " Corp ")
+		if err != nil || org.Name != "Corp" {
+			t.Errorf("expected Corp, got %+v, %v", org, err)
+		}
+	})
+
+	t.Run("Members are listed as users", func(t *testing.T) {
+		orgs, users := newFixture(t)
+		org, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		ann, _ := users.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		bob, _ := users.CreateUser(context.Background(), "Bob", "bob@corp.com", "")
+		_ = orgs.AddMember(context.Background(), org.ID, bob.ID)
+		_ = orgs.AddMember(context.Background(), org.ID, ann.ID)
+
+		members, err := orgs.Members(context.Background(), org.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(members) != 2 || members[0].Name != "Ann" || members[1].Name != "Bob" {
+			t.Errorf("expected Ann and Bob, got %v", members)
+		}
+	})
+
+	t.Run("Adding an unknown user or to an unknown organization", func(t *testing.T) {
+		orgs, users := newFixture(t)
+		org, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		ann, _ := users.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		if err := orgs.AddMember(context.Background(), org.ID, 99); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+		if err := orgs.AddMember(context.Background(), 99, ann.ID); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected ErrOrgNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Deleted users are skipped", func(t *testing.T) {
+		orgs, users := newFixture(t)
+		org, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		ann, _ := users.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		_ = orgs.AddMember(context.Background(), org.ID, ann.ID)
+		_ = users.DeleteUser(context.Background(), ann.ID, 0, false)
+
+		if members, _ := orgs.Members(context.Background(), org.ID); len(members) != 0 {
+			t.Errorf("expected no members, got %v", members)
+		}
+	})
+
+	t.Run("Memberships are user references", func(t *testing.T) {
+		orgs, users := newFixture(t)
+		corp, _ := orgs.CreateOrganization(context.Background(), "Corp")
+		labs, _ := orgs.CreateOrganization(context.Background(), "Labs")
+		ann, _ := users.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		_ = orgs.AddMember(context.Background(), corp.ID, ann.ID)
+		_ = orgs.AddMember(context.Background(), labs.ID, ann.ID)
+
+		refs, err := orgs.UserReferences(context.Background(), ann.ID)
+		if err != nil || len(refs) != 2 || refs[0] != (domain.Reference{Kind: ReferenceOrganization, ID: corp.ID}) {
+			t.Fatalf("expected references to corp and labs, got %v, %v", refs, err)
+		}
+
+		restore, err := orgs.RemoveUserReferences(context.Background(), ann.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if refs, _ := orgs.UserReferences(context.Background(), ann.ID); len(refs) != 0 {
+			t.Errorf("expected no references, got %v", refs)
+		}
+		_ = orgs.DeleteOrganization(context.Background(), labs.ID)
+		if err := restore(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if refs, _ := orgs.UserReferences(context.Background(), ann.ID); len(refs) != 1 || refs[0].ID != corp.ID {
+			t.Errorf("expected the corp membership restored, got %v", refs)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cleanarch/internal/domain"
+)
+
+// ProfileUsecase is the user profile boundary consumed by delivery adapters.
+type ProfileUsecase interface {
+	GetProfile(ctx context.Context, userID int64) (*domain.Profile, error)
+	// PutProfile creates or replaces the user's profile. It returns
+	// ErrUserNotFound if the user doesn't exist.
+	PutProfile(ctx context.Context, profile domain.Profile) (*domain.Profile, error)
+	DeleteProfile(ctx context.Context, userID int64) error
+}
+
+var (
+	_ ProfileUsecase = (*ProfileService)(nil)
+	_ OrphanSource   = (*ProfileService)(nil)
+)
+
+// RecordProfile is the record kind for profiles; the record ID is the
+// user's.
+const RecordProfile = "profile"
+
+// ProfileService manages user profiles.
+type ProfileService struct {
+	profiles domain.ProfileRepository
+	users    UserUsecase
+}
+
+func NewProfileService(profiles domain.ProfileRepository, users UserUsecase) *ProfileService {
+	return &ProfileService{profiles: profiles, users: users}
+}
+
+func (s *ProfileService) GetProfile(ctx context.Context, userID int64) (*domain.Profile, error) {
+	return s.profiles.GetByID(ctx, userID)
+}
+
+func (s *ProfileService) PutProfile(ctx context.Context, profile domain.Profile) (*domain.Profile, error) {
+	profile.Bio = strings.TrimSpace(profile.Bio)
+	profile.Phone = strings.TrimSpace(profile.Phone)
+	if profile.Addresses == nil {
+		profile.Addresses = []domain.Address{}
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.users.GetUser(ctx, profile.UserID); err != nil {
+		return nil, err
+	}
+	return s.profiles.Put(ctx, &profile)
+}
+
+func (s *ProfileService) DeleteProfile(ctx context.Context, userID int64) error {
+	return s.profiles.Delete(ctx, userID)
+}
+
+// UserRecords lists every profile.
+func (s *ProfileService) UserRecords(ctx context.Context) ([]UserRecord, error) {
+	profiles, err := s.profiles.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]UserRecord, len(profiles))
+	for i, p := range profiles {
+		records[i] = UserRecord{Kind: RecordProfile, ID: p.UserID, UserID: p.UserID}
+	}
+	return records, nil
+}
+
+// RemoveUserRecord deletes a profile.
+func (s *ProfileService) RemoveUserRecord(ctx context.Context, rec UserRecord) error {
+	if err := s.profiles.Delete(ctx, rec.ID); err != nil && !errors.Is(err, domain.ErrProfileNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
+)
+
+func TestProfileService(t *testing.T) {
+	newFixture := func(t *testing.T) (*ProfileService, *domain.User) {
+		t.Helper()
+		users := NewUserService(NewMockUserRepository())
+		user, err := users.CreateUser(context.Background(), "Ann", "ann@example.com", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return NewProfileService(memory.NewInMemoryProfileRepository(), users), user
+	}
+
+	t.Run("Put trims fields and returns the stored profile", func(t *testing.T) {
+		profiles, user := newFixture(t)
+		got, err := profiles.PutProfile(context.Background(), domain.Profile{UserID: user.ID, Bio: " Hi ", Phone: " +1 555 0100 "})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Bio != "Hi" || got.Phone != "+1 555 0100" || got.Addresses == nil {
+			t.Errorf("unexpected profile %+v", got)
+		}
+	})
+
+	t.Run("Put rejects unknown users and invalid fields", func(t *testing.T) {
+		profiles, user := newFixture(t)
+		if _, err := profiles.PutProfile(context.Background(), domain.Profile{UserID: 99}); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+		if _, err := profiles.PutProfile(context.Background(), domain.Profile{UserID: user.ID, Phone: "call me"}); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// ReferenceSource is an aggregate that can refer to users, such as
+// organizations through their memberships.
+type ReferenceSource interface {
+	// UserReferences lists the source's records that refer to the user.
+	UserReferences(ctx context.Context, userID int64) ([]domain.Reference, error)
+	// RemoveUserReferences removes them and returns a function that puts
+	// them back, used to undo a cascading delete that fails part way.
+	RemoveUserReferences(ctx context.Context, userID int64) (restore func(context.Context) error, err error)
+}
+
+// References is the registry of sources consulted before a user is
+// deleted. It is safe for concurrent use; the zero value is ready to use.
+type References struct {
+	mu      sync.RWMutex
+	sources []ReferenceSource
+}
+
+// NewReferences returns an empty registry.
+func NewReferences() *References {
+	return &References{}
+}
+
+// Register adds a source to the registry.
+func (r *References) Register(source ReferenceSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, source)
+}
+
+func (r *References) list() []ReferenceSource {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sources
+}
+
+// Find returns every reference to the user across the registered sources.
+// A nil registry finds nothing.
+func (r *References) Find(ctx context.Context, userID int64) ([]domain.Reference, error) {
+	var refs []domain.Reference
+	for _, source := range r.list() {
+		found, err := source.UserReferences(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, found...)
+	}
+	return refs, nil
+}
+
+// Remove removes every reference to the user. If a source fails, the
+// sources already cleared are restored before the error is returned, so
+// the user is either fully detached or left as it was. On success it
+// returns a function that restores everything, for when the delete the
+// removal was for fails.
+func (r *References) Remove(ctx context.Context, userID int64) (restore func(context.Context) error, err error) {
+	var restores []func(context.Context) error
+	restore = func(ctx context.Context) error {
+		var errs []error
+		for i := len(restores) - 1; i >= 0; i-- {
+			errs = append(errs, restores[i](ctx))
+		}
+		return errors.Join(errs...)
+	}
+	for _, source := range r.list() {
+		undo, err := source.RemoveUserReferences(ctx, userID)
+		if err != nil {
+			return nil, errors.Join(err, restore(ctx))
+		}
+		restores = append(restores, undo)
+	}
+	return restore, nil
+}
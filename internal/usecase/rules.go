@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"cleanarch/internal/domain"
+)
+
+// MaxRules caps how many validation rules operators may install.
+const MaxRules = 100
+
+// ErrRuleViolation is returned (wrapped) when a user fails a validation rule.
+var ErrRuleViolation = errors.New("validation rule failed")
+
+// ErrInvalidRule is returned (wrapped) when a rule can't be installed.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Rule is an operator-defined validation rule. Expr is a domain expression
+// every created or updated user must satisfy.
+type Rule struct {
+	Name    string `json:"name"`
+	Expr    string `json:"expr"`
+	Message string `json:"message,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+	expr domain.Expr
+}
+
+// RuleSet holds validation rules that can be changed at runtime. Rules are
+// parsed once when installed and evaluated in name order.
+type RuleSet struct {
+	mu    sync.RWMutex
+	rules map[string]compiledRule
+}
+
+func NewRuleSet() *RuleSet {
+	return &RuleSet{rules: make(map[string]compiledRule)}
+}
+
+// Put installs r, replacing any rule with the same name.
+func (s *RuleSet) Put(r Rule) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	expr, err := domain.ParseExpr(r.Expr)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.rules[r.Name]; !exists && len(s.rules) >= MaxRules {
+		return fmt.Errorf("%w: at most %d rules", ErrInvalidRule, MaxRules)
+	}
+	s.rules[r.Name] = compiledRule{Rule: r, expr: expr}
+	return nil
+}
+
+// Remove deletes the named rule and reports whether it existed.
+func (s *RuleSet) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.rules[name]
+	delete(s.rules, name)
+	return ok
+}
+
+// List returns the installed rules sorted by name.
+func (s *RuleSet) List() []Rule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rules := make([]Rule, 0, len(s.rules))
+	for _, r := range s.rules {
+		rules = append(rules, r.Rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules
+}
+
+// Check returns an ErrRuleViolation for the first rule u does not satisfy.
+func (s *RuleSet) Check(ctx context.Context, u *domain.User) error {
+	for _, r := range s.List() {
+		s.mu.RLock()
+		c, ok := s.rules[r.Name]
+		s.mu.RUnlock()
+		if !ok || c.expr.Match(u) {
+			continue
+		}
+		msg := c.Message
+		if msg == "" {
+			msg = c.Name
+		}
+		return fmt.Errorf("%w: %s", ErrRuleViolation, msg)
+	}
+	return nil
+}
+
+// Attach makes hooks validate created and updated users against the set.
+func (s *RuleSet) Attach(hooks *Hooks) {
+	hooks.Register(PreCreate, s.Check)
+	hooks.Register(PreUpdate, s.Check)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+func TestRuleSet(t *testing.T) {
+	t.Run("Rules reject users through hooks", func(t *testing.T) {
+		rules := NewRuleSet()
+		if err := rules.Put(Rule{Name: "corp-only", Expr: `email endsWith "@corp.com"`, Message: "only corp emails"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		hooks := NewHooks()
+		rules.Attach(hooks)
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+
+		_, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		if !errors.Is(err, ErrRuleViolation) || err.Error() != "validation rule failed: only corp emails" {
+			t.Fatalf("expected rule violation, got %v", err)
+		}
+		created, err := service.CreateUser(context.Background(), "John Doe", "john@corp.com", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := service.UpdateUser(context.Background(), created.ID, "John Doe", "john@example.com", "", 0); !errors.Is(err, ErrRuleViolation) {
+			t.Errorf("expected rule violation on update, got %v", err)
+		}
+
+		rules.Remove("corp-only")
+		if _, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", ""); err != nil {
+			t.Errorf("expected no error after removing rule, got %v", err)
+		}
+	})
+
+	t.Run("Invalid rules are refused", func(t *testing.T) {
+		rules := NewRuleSet()
+		for _, r := range []Rule{{Name: "", Expr: `id > 0`}, {Name: "bad", Expr: `id >`}} {
+			if err := rules.Put(r); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule for %+v, got %v", r, err)
+			}
+		}
+		if err := rules.Put(Rule{Name: "bad", Expr: `id >`}); !errors.Is(err, domain.ErrInvalidExpression) {
+			t.Errorf("expected wrapped ErrInvalidExpression, got %v", err)
+		}
+		if len(rules.List()) != 0 {
+			t.Errorf("expected no rules, got %v", rules.List())
+		}
+	})
+
+	t.Run("Rule count is capped", func(t *testing.T) {
+		rules := NewRuleSet()
+		for i := 0; i < MaxRules; i++ {
+			if err := rules.Put(Rule{Name: string(rune('a'+i%26)) + string(rune('a'+i/26)), Expr: `id > 0`}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if err := rules.Put(Rule{Name: "one-too-many", Expr: `id > 0`}); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("expected ErrInvalidRule, got %v", err)
+		}
+		if err := rules.Put(Rule{Name: "aa", Expr: `id > 1`}); err != nil {
+			t.Errorf("expected replacing a rule to succeed, got %v", err)
+		}
+	})
+}
@@ -1,47 +1,540 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"cleanarch/internal/domain"
 )
 
+// UserUsecase is the application boundary consumed by delivery adapters.
+type UserUsecase interface {
+	// CreateUser makes a member unless role is given.
+	CreateUser(ctx context.Context, name, email string, role domain.Role) (*domain.User, error)
+	GetUser(ctx context.Context, id int64) (*domain.User, error)
+	// GetUserByExternalID returns the user linked to subject at provider,
+	// both normalized as by domain.NormalizeExternalIDs.
+	GetUserByExternalID(ctx context.Context, provider, subject string) (*domain.User, error)
+	ListUsers(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error)
+	// CountUsers returns how many users filter selects, ignoring its
+	// paging, cursor and sort.
+	CountUsers(ctx context.Context, filter domain.Filter) (int, error)
+	// SearchUsers returns up to limit users whose name starts with prefix,
+	// ignoring case, in name order. The limit must be between 1 and
+	// domain.MaxListLimit.
+	SearchUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error)
+	LastModified(ctx context.Context) (time.Time, error)
+	UserStats(ctx context.Context, q domain.StatsQuery) (*domain.Stats, error)
+	// UpdateUser and DeleteUser apply only if the user is still at version;
+	// zero skips the check.
+	// An empty role leaves the user's role unchanged.
+	UpdateUser(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error)
+	// RenameUser changes only the user's name, with version as for
+	// UpdateUser.
+	RenameUser(ctx context.Context, id int64, name string, version int64) (*domain.User, error)
+	// DeleteUser returns a *domain.ReferenceError if other records still
+	// refer to the user, unless cascade is set, in which case it removes them.
+	DeleteUser(ctx context.Context, id int64, version int64, cascade bool) error
+	SuspendUser(ctx context.Context, id int64) (*domain.User, error)
+	ActivateUser(ctx context.Context, id int64) (*domain.User, error)
+	// AnonymizeUser replaces the user's personal data, keeping the record.
+	AnonymizeUser(ctx context.Context, id int64) (*domain.User, error)
+	// SetUserTags replaces the user's tags, normalized by
+	// domain.NormalizeTags, if the user is still at version; zero skips the check.
+	SetUserTags(ctx context.Context, id int64, tags []string, version int64) (*domain.User, error)
+	// PatchUserMetadata applies an RFC 7386 merge patch to the user's
+	// metadata (see domain.MergeMetadata), with version as for SetUserTags.
+	PatchUserMetadata(ctx context.Context, id int64, patch map[string]any, version int64) (*domain.User, error)
+	// SetUserExternalIDs replaces the user's external IDs, normalized by
+	// domain.NormalizeExternalIDs, with version as for SetUserTags. It
+	// returns domain.ErrDuplicateExternalID if one is linked to another user.
+	SetUserExternalIDs(ctx context.Context, id int64, ids map[string]string, version int64) (*domain.User, error)
+}
+
+var _ UserUsecase = (*UserService)(nil)
+
 // UserService implements application-specific use cases around the User aggregate.
 type UserService struct {
-	repo domain.UserRepository
+	repo   domain.UserRepository
+	hooks  *Hooks
+	events domain.EventBus
+	refs   *References
+	audit  domain.AuditRepository
+	orgs   domain.OrganizationRepository
+	// guardRoles restricts role assignment to admins.
+	guardRoles bool
+
+	statsMu    sync.Mutex
+	statsCache map[domain.StatsQuery]statsEntry
+}
+
+// statsEntry is a cached aggregation, valid while the repository's
+// LastModified still equals asOf.
+type statsEntry struct {
+	asOf  time.Time
+	stats *domain.Stats
+}
+
+// Option configures a UserService.
+type Option func(*UserService)
+
+// WithHooks runs the registry's hooks around create, update and delete.
+func WithHooks(hooks *Hooks) Option {
+	return func(s *UserService) { s.hooks = hooks }
+}
+
+// WithEvents publishes an event to bus after every stored mutation.
+func WithEvents(bus domain.EventBus) Option {
+	return func(s *UserService) { s.events = bus }
+}
+
+// WithReferences consults refs before deleting a user.
+func WithReferences(refs *References) Option {
+	return func(s *UserService) { s.refs = refs }
+}
+
+// WithOrganizations lets ListUsers and CountUsers select an organization's
+// members.
+func WithOrganizations(orgs domain.OrganizationRepository) Option {
+	return func(s *UserService) { s.orgs = orgs }
+}
+
+// WithAuditLog appends an entry to log for every stored create, update and
+// delete. Updates and deletes read the user first to record its previous
+// state.
+func WithAuditLog(log domain.AuditRepository) Option {
+	return func(s *UserService) { s.audit = log }
+}
+
+// WithRoleAuthorization lets only admins assign roles: creating a user with
+// a role other than member, or changing a user's role, fails with
+// domain.ErrForbidden unless the request's principal acts as an active
+// admin. Service accounts acting on their own behalf may assign roles too,
+// which is how the first admin is made.
+func WithRoleAuthorization() Option {
+	return func(s *UserService) { s.guardRoles = true }
+}
+
+func NewUserService(repo domain.UserRepository, opts ...Option) *UserService {
+	s := &UserService{repo: repo, statsCache: make(map[domain.StatsQuery]statsEntry)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *UserService) CreateUser(ctx context.Context, name, email string, role domain.Role) (*domain.User, error) {
+	if role == "" {
+		role = domain.RoleMember
+	}
+	user := &domain.User{Name: strings.TrimSpace(name), Email: strings.TrimSpace(email), Status: domain.StatusActive, Role: role}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	if role != domain.RoleMember {
+		if err := s.authorizeRole(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkEmail(ctx, 0, user.Email); err != nil {
+		return nil, err
+	}
+	if err := s.hooks.Run(ctx, PreCreate, user); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.UserCreated, created)
+	if err := s.record(ctx, domain.AuditCreate, created.ID, nil, created); err != nil {
+		return created, err
+	}
+	return created, s.hooks.Run(ctx, PostCreate, created)
+}
+
+func (s *UserService) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *UserService) GetUserByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	provider, subject, err := externalIDQuery(provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByExternalID(ctx, provider, subject)
+}
+
+// externalIDQuery normalizes a provider and subject for lookup.
+func externalIDQuery(provider, subject string) (string, string, error) {
+	provider, subject = domain.NormalizeTag(provider), strings.TrimSpace(subject)
+	if provider == "" {
+		return "", "", domain.Invalid("provider", "is required")
+	}
+	if subject == "" {
+		return "", "", domain.Invalid("subject", "is required")
+	}
+	return provider, subject, nil
+}
+
+// ListUsers returns the page of users filter selects. An OrgID is resolved
+// into the organization's member IDs, which replace filter.IDs.
+func (s *UserService) ListUsers(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter, err := s.resolveOrg(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// CountUsers counts the users filter selects, resolving an OrgID as
+// ListUsers does.
+func (s *UserService) CountUsers(ctx context.Context, filter domain.Filter) (int, error) {
+	filter.PageRequest, filter.Cursor = domain.PageRequest{}, ""
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+	filter, err := s.resolveOrg(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Count(ctx, filter)
+}
+
+// authorizeRole returns domain.ErrForbidden, when roles are guarded, unless
+// the principal is a service account acting for itself or its subject is
+// an active admin.
+func (s *UserService) authorizeRole(ctx context.Context) error {
+	if !s.guardRoles {
+		return nil
+	}
+	p := domain.PrincipalFrom(ctx)
+	if p.Service != "" && !p.Impersonating() {
+		return nil
+	}
+	if p.SubjectID > 0 {
+		u, err := s.repo.GetByID(ctx, p.SubjectID)
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+		case err != nil:
+			return err
+		case u.Role == domain.RoleAdmin && u.Status == domain.StatusActive:
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: only admins may assign roles", domain.ErrForbidden)
+}
+
+// resolveOrg replaces filter.IDs with the members of filter.OrgID, if set.
+func (s *UserService) resolveOrg(ctx context.Context, filter domain.Filter) (domain.Filter, error) {
+	if filter.OrgID == 0 {
+		return filter, nil
+	}
+	if s.orgs == nil {
+		return filter, fmt.Errorf("%w: filtering by organization is not supported", domain.ErrInvalidFilter)
+	}
+	ids, err := s.orgs.Members(ctx, filter.OrgID)
+	if err != nil {
+		return filter, err
+	}
+	filter.IDs = append([]int64{}, ids...)
+	return filter, nil
+}
+
+func (s *UserService) SearchUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: search prefix must not be empty", domain.ErrInvalidFilter)
+	}
+	if limit < 1 || limit > domain.MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidFilter, domain.MaxListLimit)
+	}
+	return s.repo.SearchByNamePrefix(ctx, prefix, limit)
 }
 
-func NewUserService(repo domain.UserRepository) *UserService {
-	return &UserService{repo: repo}
+// LastModified returns the time the user collection last changed.
+func (s *UserService) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
 }
 
-func (s *UserService) CreateUser(name, email string) (*domain.User, error) {
-	name = strings.TrimSpace(name)
-	email = strings.TrimSpace(email)
-	if name == "" || email == "" {
-		return nil, errors.New("name and email are required")
+// UserStats aggregates users as described by q. Results are cached until the
+// user collection changes; the returned value is shared and must not be modified.
+func (s *UserService) UserStats(ctx context.Context, q domain.StatsQuery) (*domain.Stats, error) {
+	q = q.Normalize()
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	asOf, err := s.repo.LastModified(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.statsMu.Lock()
+	entry, ok := s.statsCache[q]
+	s.statsMu.Unlock()
+	if ok && entry.asOf.Equal(asOf) {
+		return entry.stats, nil
+	}
+
+	buckets, err := s.repo.Stats(ctx, q)
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.Create(&domain.User{Name: name, Email: email})
+	stats := domain.NewStats(q, buckets)
+	s.statsMu.Lock()
+	s.statsCache[q] = statsEntry{asOf: asOf, stats: stats}
+	s.statsMu.Unlock()
+	return stats, nil
 }
 
-func (s *UserService) GetUser(id int64) (*domain.User, error) {
-	return s.repo.GetByID(id)
+func (s *UserService) UpdateUser(ctx context.Context, id int64, name, email string, role domain.Role, version int64) (*domain.User, error) {
+	user := &domain.User{ID: id, Name: strings.TrimSpace(name), Email: strings.TrimSpace(email), Role: role, Version: version}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	if role != "" && s.guardRoles {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.Role != role {
+			if err := s.authorizeRole(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.checkEmail(ctx, id, user.Email); err != nil {
+		return nil, err
+	}
+	if err := s.hooks.Run(ctx, PreUpdate, user); err != nil {
+		return nil, err
+	}
+	before, err := s.auditBefore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.UserUpdated, updated)
+	if err := s.record(ctx, domain.AuditUpdate, id, before, updated); err != nil {
+		return updated, err
+	}
+	return updated, s.hooks.Run(ctx, PostUpdate, updated)
 }
 
-func (s *UserService) ListUsers() ([]*domain.User, error) {
-	return s.repo.List()
+// RenameUser updates the name and keeps the stored email and role. It
+// writes at the version it read, so an email changed in between fails the
+// rename with domain.ErrVersionConflict instead of being written back.
+func (s *UserService) RenameUser(ctx context.Context, id int64, name string, version int64) (*domain.User, error) {
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	return s.UpdateUser(ctx, id, name, current.Email, "", current.Version)
 }
 
-func (s *UserService) UpdateUser(id int64, name, email string) (*domain.User, error) {
-	name = strings.TrimSpace(name)
-	email = strings.TrimSpace(email)
-	if name == "" || email == "" {
-		return nil, errors.New("name and email are required")
+// DeleteUser removes the user. A cascading delete removes the references
+// first and puts them back if the delete itself fails. References added
+// between the check and the delete are not seen; the cleanup subscribed to
+// UserDeleted catches those.
+func (s *UserService) DeleteUser(ctx context.Context, id int64, version int64, cascade bool) error {
+	if err := s.hooks.Run(ctx, PreDelete, &domain.User{ID: id}); err != nil {
+		return err
+	}
+	var restore func(context.Context) error
+	if cascade {
+		var err error
+		if restore, err = s.refs.Remove(ctx, id); err != nil {
+			return err
+		}
+	} else {
+		refs, err := s.refs.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			return &domain.ReferenceError{References: refs}
+		}
+	}
+	before, err := s.auditBefore(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		if restore != nil {
+			if rerr := restore(ctx); rerr != nil {
+				return errors.Join(err, rerr)
+			}
+		}
+		return err
 	}
-	return s.repo.Update(&domain.User{ID: id, Name: name, Email: email})
+	s.publish(ctx, domain.UserDeleted, &domain.User{ID: id, Version: version})
+	if err := s.record(ctx, domain.AuditDelete, id, before, nil); err != nil {
+		return err
+	}
+	return s.hooks.Run(ctx, PostDelete, &domain.User{ID: id})
+}
+
+// SuspendUser moves an active user to suspended.
+func (s *UserService) SuspendUser(ctx context.Context, id int64) (*domain.User, error) {
+	return s.modify(ctx, id, 0, func(u *domain.User) error { return u.Transition(domain.StatusSuspended) })
 }
 
-func (s *UserService) DeleteUser(id int64) error {
-	return s.repo.Delete(id)
+// ActivateUser moves a suspended user back to active.
+func (s *UserService) ActivateUser(ctx context.Context, id int64) (*domain.User, error) {
+	return s.modify(ctx, id, 0, func(u *domain.User) error { return u.Transition(domain.StatusActive) })
+}
+
+func (s *UserService) SetUserTags(ctx context.Context, id int64, tags []string, version int64) (*domain.User, error) {
+	return s.modify(ctx, id, version, func(u *domain.User) error {
+		u.Tags = domain.NormalizeTags(tags)
+		return u.Validate()
+	})
+}
+
+// AnonymizeUser replaces the user's name and email with placeholders and
+// publishes UserAnonymized, whose subscribers remove the user's other
+// personal data. The user keeps its ID, status, role and references.
+// Earlier audit entries are kept, but their snapshots are anonymized too,
+// so the log can't give back what was erased. Hooks and validation rules
+// don't run: a business rule must not be able to block an erasure
+// request. Anonymizing an anonymized user returns it unchanged.
+func (s *UserService) AnonymizeUser(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Anonymized() {
+		return user, nil
+	}
+	user.Anonymize()
+	updated, err := s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.UserAnonymized, updated)
+	if s.audit != nil {
+		if err := s.audit.Redact(ctx, id, (*domain.User).Anonymize); err != nil {
+			return nil, err
+		}
+	}
+	return updated, s.record(ctx, domain.AuditAnonymize, id, nil, updated)
+}
+
+func (s *UserService) PatchUserMetadata(ctx context.Context, id int64, patch map[string]any, version int64) (*domain.User, error) {
+	return s.modify(ctx, id, version, func(u *domain.User) error {
+		u.Metadata = domain.MergeMetadata(u.Metadata, patch)
+		return u.Validate()
+	})
+}
+
+func (s *UserService) SetUserExternalIDs(ctx context.Context, id int64, ids map[string]string, version int64) (*domain.User, error) {
+	return s.modify(ctx, id, version, func(u *domain.User) error {
+		u.ExternalIDs = domain.NormalizeExternalIDs(ids)
+		return u.Validate()
+	})
+}
+
+// modify applies change to the stored user as an update, so update hooks
+// and validation rules see it. A non-zero version must match the stored one.
+func (s *UserService) modify(ctx context.Context, id, version int64, change func(*domain.User) error) (*domain.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != user.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	before := *user
+	if err := change(user); err != nil {
+		return nil, err
+	}
+	if user.Email != before.Email {
+		if err := s.checkEmail(ctx, id, user.Email); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.hooks.Run(ctx, PreUpdate, user); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.UserUpdated, updated)
+	if err := s.record(ctx, domain.AuditUpdate, id, &before, updated); err != nil {
+		return updated, err
+	}
+	return updated, s.hooks.Run(ctx, PostUpdate, updated)
+}
+
+// checkEmail returns ErrDuplicateEmail if a user other than id has email,
+// so the conflict is reported before any hooks run. The repository checks
+// again as it writes, which catches a concurrent write taking the email.
+func (s *UserService) checkEmail(ctx context.Context, id int64, email string) error {
+	owner, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner.ID != id {
+		return domain.ErrDuplicateEmail
+	}
+	return nil
+}
+
+// auditBefore reads the user's current state for the audit log, if one is
+// configured. A missing user is left for the write to report.
+func (s *UserService) auditBefore(ctx context.Context, id int64) (*domain.User, error) {
+	if s.audit == nil {
+		return nil, nil
+	}
+	user, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	before := *user // the repository may share it with the write
+	return &before, nil
+}
+
+// record appends an audit entry for a stored mutation, if an audit log is
+// configured. The principal is taken from ctx.
+func (s *UserService) record(ctx context.Context, action domain.AuditAction, id int64, before, after *domain.User) error {
+	if s.audit == nil {
+		return nil
+	}
+	_, err := s.audit.Append(ctx, &domain.AuditEntry{
+		Principal: domain.PrincipalFrom(ctx),
+		Action:    action,
+		EntityID:  id,
+		Before:    before,
+		After:     after,
+		At:        time.Now().UTC(),
+	})
+	return err
+}
+
+// publish sends an event for a stored mutation, if an event bus is
+// configured. Dry runs store nothing, so they publish nothing.
+func (s *UserService) publish(ctx context.Context, t domain.EventType, user *domain.User) {
+	if s.events == nil || domain.IsDryRun(ctx) {
+		return
+	}
+	s.events.Publish(ctx, domain.Event{Type: t, User: *user, At: time.Now().UTC(), By: domain.PrincipalFrom(ctx)})
 }
@@ -1,18 +1,27 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"testing/quick"
 	"time"
+	"unicode/utf8"
 
 	"cleanarch/internal/domain"
+	"cleanarch/internal/repository/memory"
 )
 
 // MockUserRepository implements domain.UserRepository for testing
 type MockUserRepository struct {
-	users  map[int64]*domain.User
-	nextID int64
-	fail   bool // for testing error scenarios
+	users        map[int64]*domain.User
+	nextID       int64
+	lastModified time.Time
+	statsCalls   int
+	fail         bool // for testing error scenarios
 }
 
 func NewMockUserRepository() *MockUserRepository {
@@ -26,7 +35,7 @@ func (m *MockUserRepository) SetFail(fail bool) {
 	m.fail = fail
 }
 
-func (m *MockUserRepository) Create(user *domain.User) (*domain.User, error) {
+func (m *MockUserRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if m.fail {
 		return nil, errors.New("repository error")
 	}
@@ -35,26 +44,74 @@ func (m *MockUserRepository) Create(user *domain.User) (*domain.User, error) {
 		ID:        m.nextID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Status:    user.Status,
+		Role:      user.Role,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 	m.users[m.nextID] = created
 	m.nextID++
+	m.lastModified = now
 	return created, nil
 }
 
-func (m *MockUserRepository) GetByID(id int64) (*domain.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	if m.fail {
 		return nil, errors.New("repository error")
 	}
 	user, ok := m.users[id]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	return user, nil
 }
 
-func (m *MockUserRepository) List() ([]*domain.User, error) {
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if m.fail {
+		return nil, errors.New("repository error")
+	}
+	for _, user := range m.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (m *MockUserRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	if m.fail {
+		return nil, errors.New("repository error")
+	}
+	var result []*domain.User
+	for _, user := range m.users {
+		if strings.HasPrefix(strings.ToLower(user.Name), strings.ToLower(prefix)) {
+			result = append(result, user)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := strings.ToLower(result[i].Name), strings.ToLower(result[j].Name)
+		return a < b || a == b && result[i].ID < result[j].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockUserRepository) GetByExternalID(ctx context.Context, provider, subject string) (*domain.User, error) {
+	if m.fail {
+		return nil, errors.New("repository error")
+	}
+	for _, user := range m.users {
+		if got, ok := user.ExternalIDs[provider]; ok && got == subject {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
 	if m.fail {
 		return nil, errors.New("repository error")
 	}
@@ -62,40 +119,95 @@ func (m *MockUserRepository) List() ([]*domain.User, error) {
 	for _, user := range m.users {
 		result = append(result, user)
 	}
-	return result, nil
+	return &domain.Page[domain.User]{Items: result, Total: len(result)}, nil
+}
+
+func (m *MockUserRepository) Count(ctx context.Context, filter domain.Filter) (int, error) {
+	if m.fail {
+		return 0, errors.New("repository error")
+	}
+	n := 0
+	for _, user := range m.users {
+		if filter.Match(user) {
+			n++
+		}
+	}
+	return n, nil
 }
 
-func (m *MockUserRepository) Update(user *domain.User) (*domain.User, error) {
+func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if m.fail {
 		return nil, errors.New("repository error")
 	}
 	existing, ok := m.users[user.ID]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
+	}
+	if user.Version != 0 && user.Version != existing.Version {
+		return nil, domain.ErrVersionConflict
 	}
 	existing.Name = user.Name
 	existing.Email = user.Email
+	if user.Status != "" {
+		existing.Status = user.Status
+	}
+	if user.Role != "" {
+		existing.Role = user.Role
+	}
+	if user.ExternalIDs != nil {
+		existing.ExternalIDs = user.ExternalIDs
+	}
 	existing.UpdatedAt = time.Now().UTC()
+	existing.Version++
+	m.lastModified = existing.UpdatedAt
 	return existing, nil
 }
 
-func (m *MockUserRepository) Delete(id int64) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id int64, version int64) error {
 	if m.fail {
 		return errors.New("repository error")
 	}
-	if _, ok := m.users[id]; !ok {
-		return errors.New("user not found")
+	existing, ok := m.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if version != 0 && version != existing.Version {
+		return domain.ErrVersionConflict
 	}
 	delete(m.users, id)
+	m.lastModified = time.Now().UTC()
 	return nil
 }
 
+func (m *MockUserRepository) LastModified(ctx context.Context) (time.Time, error) {
+	if m.fail {
+		return time.Time{}, errors.New("repository error")
+	}
+	return m.lastModified, nil
+}
+
+func (m *MockUserRepository) Stats(ctx context.Context, q domain.StatsQuery) ([]domain.StatsBucket, error) {
+	if m.fail {
+		return nil, errors.New("repository error")
+	}
+	m.statsCalls++
+	counts := make(map[string]int)
+	for _, u := range m.users {
+		counts[domain.GroupKey(u, q)]++
+	}
+	var buckets []domain.StatsBucket
+	for key, n := range counts {
+		buckets = append(buckets, domain.StatsBucket{Key: key, Count: n})
+	}
+	return buckets, nil
+}
+
 func TestUserService_CreateUser(t *testing.T) {
 	t.Run("Create user with valid data", func(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		user, err := service.CreateUser("John Doe", "john@example.com")
+		user, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -114,13 +226,12 @@ func TestUserService_CreateUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.CreateUser("", "john@example.com")
+		_, err := service.CreateUser(context.Background(), "", "john@example.com", "")
 		if err == nil {
 			t.Error("expected error for empty name")
 		}
-		expectedMsg := "name and email are required"
-		if err.Error() != expectedMsg {
-			t.Errorf("expected error '%s', got '%s'", expectedMsg, err.Error())
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
 	})
 
@@ -128,7 +239,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.CreateUser("John Doe", "")
+		_, err := service.CreateUser(context.Background(), "John Doe", "", "")
 		if err == nil {
 			t.Error("expected error for empty email")
 		}
@@ -138,7 +249,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.CreateUser("   ", "   ")
+		_, err := service.CreateUser(context.Background(), "   ", "   ", "")
 		if err == nil {
 			t.Error("expected error for whitespace-only name and email")
 		}
@@ -149,7 +260,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		repo.SetFail(true)
 		service := NewUserService(repo)
 
-		_, err := service.CreateUser("John Doe", "john@example.com")
+		_, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
 		if err == nil {
 			t.Error("expected error from repository")
 		}
@@ -162,10 +273,10 @@ func TestUserService_GetUser(t *testing.T) {
 		service := NewUserService(repo)
 
 		// First create a user
-		created, _ := service.CreateUser("John Doe", "john@example.com")
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
 
 		// Then get it
-		user, err := service.GetUser(created.ID)
+		user, err := service.GetUser(context.Background(), created.ID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -178,7 +289,7 @@ func TestUserService_GetUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.GetUser(999)
+		_, err := service.GetUser(context.Background(), 999)
 		if err == nil {
 			t.Error("expected error for non-existent user")
 		}
@@ -186,20 +297,52 @@ func TestUserService_GetUser(t *testing.T) {
 }
 
 func TestUserService_ListUsers(t *testing.T) {
+	t.Run("Organization members", func(t *testing.T) {
+		orgs := memory.NewInMemoryOrganizationRepository()
+		service := NewUserService(memory.NewInMemoryUserRepository(), WithOrganizations(orgs))
+		john, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
+		org, _ := orgs.Create(context.Background(), &domain.Organization{Name: "Acme"})
+		_ = orgs.AddMember(context.Background(), org.ID, john.ID)
+
+		page, err := service.ListUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: org.ID}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page.Total != 1 || page.Items[0].ID != john.ID {
+			t.Errorf("expected only John, got %v", page.Items)
+		}
+
+		_ = orgs.RemoveMember(context.Background(), org.ID, john.ID)
+		if page, _ := service.ListUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: org.ID}}); page.Total != 0 {
+			t.Errorf("expected no members, got %v", page.Items)
+		}
+		if _, err := service.ListUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: 999}}); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected ErrOrgNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Organization filter needs organizations", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		if _, err := service.ListUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: 1}}); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
 	t.Run("List users", func(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
 		// Create some users
-		_, _ = service.CreateUser("John Doe", "john@example.com")
-		_, _ = service.CreateUser("Jane Doe", "jane@example.com")
+		_, _ = service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
 
-		users, err := service.ListUsers()
+		users, err := service.ListUsers(context.Background(), domain.Filter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(users) != 2 {
-			t.Errorf("expected 2 users, got %d", len(users))
+		if len(users.Items) != 2 {
+			t.Errorf("expected 2 users, got %d", len(users.Items))
 		}
 	})
 
@@ -207,12 +350,22 @@ func TestUserService_ListUsers(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		users, err := service.ListUsers()
+		users, err := service.ListUsers(context.Background(), domain.Filter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(users) != 0 {
-			t.Errorf("expected 0 users, got %d", len(users))
+		if len(users.Items) != 0 {
+			t.Errorf("expected 0 users, got %d", len(users.Items))
+		}
+	})
+
+	t.Run("List with invalid filter", func(t *testing.T) {
+		repo := NewMockUserRepository()
+		service := NewUserService(repo)
+
+		_, err := service.ListUsers(context.Background(), domain.Filter{Sort: domain.SortSpec{Field: "password"}})
+		if !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
 		}
 	})
 }
@@ -223,10 +376,10 @@ func TestUserService_UpdateUser(t *testing.T) {
 		service := NewUserService(repo)
 
 		// First create a user
-		created, _ := service.CreateUser("John Doe", "john@example.com")
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
 
 		// Then update it
-		updated, err := service.UpdateUser(created.ID, "Jane Doe", "jane@example.com")
+		updated, err := service.UpdateUser(context.Background(), created.ID, "Jane Doe", "jane@example.com", "", 0)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -242,7 +395,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.UpdateUser(1, "", "john@example.com")
+		_, err := service.UpdateUser(context.Background(), 1, "", "john@example.com", "", 0)
 		if err == nil {
 			t.Error("expected error for empty name")
 		}
@@ -252,29 +405,65 @@ func TestUserService_UpdateUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		_, err := service.UpdateUser(1, "John Doe", "")
+		_, err := service.UpdateUser(context.Background(), 1, "John Doe", "", "", 0)
 		if err == nil {
 			t.Error("expected error for empty email")
 		}
 	})
 }
 
+func TestUserService_RenameUser(t *testing.T) {
+	service := NewUserService(memory.NewInMemoryUserRepository())
+	created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", domain.RoleViewer)
+
+	renamed, err := service.RenameUser(context.Background(), created.ID, "Johnny", created.Version)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if renamed.Name != "Johnny" || renamed.Email != "john@example.com" || renamed.Role != domain.RoleViewer {
+		t.Errorf("expected only the name to change, got %+v", renamed)
+	}
+
+	_, _ = service.UpdateUser(context.Background(), created.ID, "Johnny", "new@example.com", "", 0)
+	if _, err := service.RenameUser(context.Background(), created.ID, "John", renamed.Version); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for a stale version, got %v", err)
+	}
+	if u, _ := service.GetUser(context.Background(), created.ID); u.Email != "new@example.com" {
+		t.Errorf("expected the newer email to stay, got %q", u.Email)
+	}
+}
+
+func TestUserService_Version(t *testing.T) {
+	service := NewUserService(NewMockUserRepository())
+	created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+	id, version := created.ID, created.Version
+	if _, err := service.UpdateUser(context.Background(), id, "Jane Doe", "jane@example.com", "", version); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := service.UpdateUser(context.Background(), id, "Jim", "jim@example.com", "", version); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if err := service.DeleteUser(context.Background(), id, version, false); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
 func TestUserService_DeleteUser(t *testing.T) {
 	t.Run("Delete existing user", func(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
 		// First create a user
-		created, _ := service.CreateUser("John Doe", "john@example.com")
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
 
 		// Then delete it
-		err := service.DeleteUser(created.ID)
+		err := service.DeleteUser(context.Background(), created.ID, 0, false)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Verify it's deleted
-		_, err = service.GetUser(created.ID)
+		_, err = service.GetUser(context.Background(), created.ID)
 		if err == nil {
 			t.Error("expected error for deleted user")
 		}
@@ -284,9 +473,788 @@ func TestUserService_DeleteUser(t *testing.T) {
 		repo := NewMockUserRepository()
 		service := NewUserService(repo)
 
-		err := service.DeleteUser(999)
+		err := service.DeleteUser(context.Background(), 999, 0, false)
 		if err == nil {
 			t.Error("expected error for non-existent user")
 		}
 	})
 }
+
+// stubReferences is a ReferenceSource holding references in memory.
+type stubReferences struct {
+	refs      []domain.Reference
+	removeErr error
+}
+
+func (s *stubReferences) UserReferences(ctx context.Context, userID int64) ([]domain.Reference, error) {
+	return s.refs, nil
+}
+
+func (s *stubReferences) RemoveUserReferences(ctx context.Context, userID int64) (func(context.Context) error, error) {
+	if s.removeErr != nil {
+		return nil, s.removeErr
+	}
+	removed := s.refs
+	s.refs = nil
+	return func(ctx context.Context) error {
+		s.refs = removed
+		return nil
+	}, nil
+}
+
+func TestUserService_DeleteReferences(t *testing.T) {
+	newFixture := func() (*UserService, *MockUserRepository, *References) {
+		repo := NewMockUserRepository()
+		refs := NewReferences()
+		return NewUserService(repo, WithReferences(refs)), repo, refs
+	}
+
+	t.Run("References block a delete", func(t *testing.T) {
+		service, _, refs := newFixture()
+		refs.Register(&stubReferences{refs: []domain.Reference{{Kind: "organization", ID: 7}}})
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		err := service.DeleteUser(context.Background(), created.ID, 0, false)
+		var refErr *domain.ReferenceError
+		if !errors.As(err, &refErr) || !errors.Is(err, domain.ErrUserReferenced) {
+			t.Fatalf("expected a ReferenceError, got %v", err)
+		}
+		if len(refErr.References) != 1 || refErr.References[0].ID != 7 {
+			t.Errorf("expected the organization reference, got %v", refErr.References)
+		}
+		if _, err := service.GetUser(context.Background(), created.ID); err != nil {
+			t.Errorf("expected the user to remain, got %v", err)
+		}
+	})
+
+	t.Run("Cascade removes references", func(t *testing.T) {
+		service, _, refs := newFixture()
+		source := &stubReferences{refs: []domain.Reference{{Kind: "organization", ID: 7}}}
+		refs.Register(source)
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		if err := service.DeleteUser(context.Background(), created.ID, 0, true); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(source.refs) != 0 {
+			t.Errorf("expected references removed, got %v", source.refs)
+		}
+	})
+
+	t.Run("Failed delete restores references", func(t *testing.T) {
+		service, repo, refs := newFixture()
+		source := &stubReferences{refs: []domain.Reference{{Kind: "organization", ID: 7}}}
+		refs.Register(source)
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		repo.fail = true
+
+		if err := service.DeleteUser(context.Background(), created.ID, 0, true); err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(source.refs) != 1 {
+			t.Errorf("expected references restored, got %v", source.refs)
+		}
+	})
+
+	t.Run("Failed removal restores earlier sources", func(t *testing.T) {
+		service, _, refs := newFixture()
+		first := &stubReferences{refs: []domain.Reference{{Kind: "organization", ID: 7}}}
+		refs.Register(first)
+		refs.Register(&stubReferences{removeErr: errors.New("unavailable")})
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		if err := service.DeleteUser(context.Background(), created.ID, 0, true); err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(first.refs) != 1 {
+			t.Errorf("expected references restored, got %v", first.refs)
+		}
+		if _, err := service.GetUser(context.Background(), created.ID); err != nil {
+			t.Errorf("expected the user to remain, got %v", err)
+		}
+	})
+}
+
+func TestUserService_LastModified(t *testing.T) {
+	t.Run("Last modified follows mutations", func(t *testing.T) {
+		repo := NewMockUserRepository()
+		service := NewUserService(repo)
+
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		lastModified, err := service.LastModified(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !lastModified.Equal(created.UpdatedAt) {
+			t.Errorf("expected last modified %v, got %v", created.UpdatedAt, lastModified)
+		}
+	})
+
+	t.Run("Repository error handling", func(t *testing.T) {
+		repo := NewMockUserRepository()
+		repo.SetFail(true)
+		service := NewUserService(repo)
+
+		_, err := service.LastModified(context.Background())
+		if err == nil {
+			t.Error("expected error from repository")
+		}
+	})
+}
+
+func TestUserService_ValidationProperties(t *testing.T) {
+	t.Run("Create succeeds iff trimmed name and email are non-empty", func(t *testing.T) {
+		property := func(name string, n uint16, noEmail bool) bool {
+			service := NewUserService(NewMockUserRepository())
+			email := ""
+			if !noEmail {
+				email = fmt.Sprintf(" user%d@example.com ", n)
+			}
+			user, err := service.CreateUser(context.Background(), name, email, "")
+			valid := strings.TrimSpace(name) != "" && utf8.RuneCountInString(strings.TrimSpace(name)) <= 200 && !noEmail
+			if !valid {
+				return errors.Is(err, domain.ErrInvalidInput)
+			}
+			return err == nil && user.Name == strings.TrimSpace(name) && user.Email == strings.TrimSpace(email)
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Whitespace padding never changes the stored values", func(t *testing.T) {
+		property := func(name string, n uint16, pad uint8) bool {
+			if strings.TrimSpace(name) == "" {
+				return true
+			}
+			// Separate repositories, since the two users share an email.
+			service := NewUserService(NewMockUserRepository())
+			other := NewUserService(NewMockUserRepository())
+			email := fmt.Sprintf("user%d@example.com", n)
+			padding := strings.Repeat(" ", int(pad%5))
+			plain, err1 := service.CreateUser(context.Background(), name, email, "")
+			padded, err2 := other.CreateUser(context.Background(), padding+name+padding, "\t"+email+padding, "")
+			return err1 == nil && err2 == nil && plain.Name == padded.Name && plain.Email == padded.Email
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Malformed emails are rejected", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		for _, email := range []string{"bob", "bob@", "@example.com", "Bob <bob@example.com>"} {
+			_, err := service.CreateUser(context.Background(), "Bob", email, "")
+			var invalid *domain.ValidationError
+			if !errors.As(err, &invalid) || invalid.Fields[0].Field != "email" {
+				t.Errorf("expected an email validation error for %q, got %v", email, err)
+			}
+		}
+	})
+}
+
+func TestUserService_SearchUsers(t *testing.T) {
+	t.Run("Prefix is trimmed", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		_, _ = service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
+
+		users, err := service.SearchUsers(context.Background(), "  jo ", 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(users) != 1 || users[0].Name != "John Doe" {
+			t.Errorf("expected John Doe, got %v", users)
+		}
+	})
+
+	t.Run("Invalid searches", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		for _, tc := range []struct {
+			prefix string
+			limit  int
+		}{{" ", 10}, {"jo", 0}, {"jo", domain.MaxListLimit + 1}} {
+			if _, err := service.SearchUsers(context.Background(), tc.prefix, tc.limit); !errors.Is(err, domain.ErrInvalidFilter) {
+				t.Errorf("%q, %d: expected ErrInvalidFilter, got %v", tc.prefix, tc.limit, err)
+			}
+		}
+	})
+}
+
+func TestUserService_DuplicateEmail(t *testing.T) {
+	t.Run("Create is rejected before hooks run", func(t *testing.T) {
+		var calls int
+		hooks := NewHooks()
+		hooks.Register(PreCreate, func(ctx context.Context, u *domain.User) error {
+			calls++
+			return nil
+		})
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+		_, _ = service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		_, err := service.CreateUser(context.Background(), "Johnny", "John@Example.com", "")
+		if !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected hooks to run for the first create only, got %d calls", calls)
+		}
+	})
+
+	t.Run("Update may keep its own email but not take another's", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		_, _ = service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		jane, _ := service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
+
+		if _, err := service.UpdateUser(context.Background(), jane.ID, "Jane", "jane@example.com", "", 0); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if _, err := service.UpdateUser(context.Background(), jane.ID, "Jane", "john@example.com", "", 0); !errors.Is(err, domain.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+}
+
+func TestUserService_Hooks(t *testing.T) {
+	t.Run("Pre-create hooks run in order and short-circuit", func(t *testing.T) {
+		var calls []string
+		hooks := NewHooks()
+		hooks.Register(PreCreate, func(ctx context.Context, u *domain.User) error {
+			calls = append(calls, "first")
+			if !strings.HasSuffix(u.Email, "@example.com") {
+				return errors.New("email domain not allowed")
+			}
+			return nil
+		})
+		hooks.Register(PreCreate, func(ctx context.Context, u *domain.User) error {
+			calls = append(calls, "second")
+			return nil
+		})
+		repo := NewMockUserRepository()
+		service := NewUserService(repo, WithHooks(hooks))
+
+		_, err := service.CreateUser(context.Background(), "John Doe", "john@other.org", "")
+		if err == nil || err.Error() != "email domain not allowed" {
+			t.Fatalf("expected hook error, got %v", err)
+		}
+		if len(repo.users) != 0 {
+			t.Errorf("expected rejected user not to be stored, got %d users", len(repo.users))
+		}
+		if _, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := strings.Join(calls, ","); got != "first,first,second" {
+			t.Errorf("expected first,first,second, got %s", got)
+		}
+	})
+
+	t.Run("Post-update hooks see the stored user", func(t *testing.T) {
+		var seen *domain.User
+		hooks := NewHooks()
+		hooks.Register(PostUpdate, func(ctx context.Context, u *domain.User) error {
+			seen = u
+			return nil
+		})
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		updated, err := service.UpdateUser(context.Background(), created.ID, "Jane Doe", "jane@example.com", "", 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if seen != updated {
+			t.Errorf("expected hook to receive the updated user, got %v", seen)
+		}
+	})
+
+	t.Run("Pre-delete hook can veto a delete", func(t *testing.T) {
+		hooks := NewHooks()
+		hooks.Register(PreDelete, func(ctx context.Context, u *domain.User) error { return errors.New("user is protected") })
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		if err := service.DeleteUser(context.Background(), created.ID, 0, false); err == nil {
+			t.Fatal("expected delete to be vetoed")
+		}
+		if _, err := service.GetUser(context.Background(), created.ID); err != nil {
+			t.Errorf("expected user to still exist, got %v", err)
+		}
+	})
+
+	t.Run("Dry runs skip post hooks and events", func(t *testing.T) {
+		var calls []string
+		hooks := NewHooks()
+		hooks.Register(PreCreate, func(ctx context.Context, u *domain.User) error {
+			calls = append(calls, "pre")
+			return nil
+		})
+		hooks.Register(PostCreate, func(ctx context.Context, u *domain.User) error {
+			calls = append(calls, "post")
+			return nil
+		})
+		bus := &recordingBus{}
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks), WithEvents(bus))
+
+		if _, err := service.CreateUser(domain.WithDryRun(context.Background()), "John Doe", "john@example.com", ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := strings.Join(calls, ","); got != "pre" {
+			t.Errorf("expected only the pre hook, got %s", got)
+		}
+		if len(bus.events) != 0 {
+			t.Errorf("expected no events, got %+v", bus.events)
+		}
+	})
+}
+
+func TestUserService_AnonymizeUser(t *testing.T) {
+	t.Run("Scrubs personal data and keeps the record", func(t *testing.T) {
+		bus := &recordingBus{}
+		log := memory.NewInMemoryAuditRepository()
+		service := NewUserService(NewMockUserRepository(), WithEvents(bus), WithAuditLog(log))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", domain.RoleAdmin)
+
+		got, err := service.AnonymizeUser(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.ID != created.ID || got.Name == "John Doe" || got.Email == "john@example.com" || got.Role != domain.RoleAdmin {
+			t.Errorf("unexpected anonymized user %+v", got)
+		}
+		if last := bus.events[len(bus.events)-1]; last.Type != domain.UserAnonymized || last.User.Email != got.Email {
+			t.Errorf("expected a user.anonymized event, got %+v", last)
+		}
+		page, _ := log.List(context.Background(), domain.AuditQuery{})
+		if page.Total != 2 || page.Items[0].Action != domain.AuditAnonymize || page.Items[0].Before != nil {
+			t.Errorf("expected an anonymize entry without the old data, got %+v", page.Items[0])
+		}
+		if created := page.Items[1].After; created.Name == "John Doe" || created.Email == "john@example.com" {
+			t.Errorf("expected the earlier entry to be redacted, got %+v", created)
+		}
+	})
+
+	t.Run("Anonymizing twice changes nothing", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		first, _ := service.AnonymizeUser(context.Background(), created.ID)
+		second, err := service.AnonymizeUser(context.Background(), created.ID)
+		if err != nil || second.Version != first.Version {
+			t.Errorf("expected the same version %d, got %d and %v", first.Version, second.Version, err)
+		}
+	})
+
+	t.Run("Update hooks can't block it", func(t *testing.T) {
+		hooks := NewHooks()
+		hooks.Register(PreUpdate, func(ctx context.Context, u *domain.User) error { return errors.New("frozen") })
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		if _, err := service.AnonymizeUser(context.Background(), created.ID); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		if _, err := service.AnonymizeUser(context.Background(), 999); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestUserService_SetUserTags(t *testing.T) {
+	t.Run("Tags are normalized and audited", func(t *testing.T) {
+		log := memory.NewInMemoryAuditRepository()
+		service := NewUserService(NewMockUserRepository(), WithAuditLog(log))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		got, err := service.SetUserTags(context.Background(), created.ID, []string{" Beta", "vip", "beta", ""}, created.Version)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if strings.Join(got.Tags, ",") != "beta,vip" {
+			t.Errorf("expected tags beta,vip, got %v", got.Tags)
+		}
+		page, _ := log.List(context.Background(), domain.AuditQuery{})
+		if page.Items[0].Action != domain.AuditUpdate || len(page.Items[0].Before.Tags) != 0 {
+			t.Errorf("expected an update entry from no tags, got %+v", page.Items[0])
+		}
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, err := service.SetUserTags(context.Background(), created.ID, []string{"beta"}, created.Version+1)
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("expected ErrVersionConflict, got %v", err)
+		}
+	})
+
+	t.Run("Malformed tags", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, err := service.SetUserTags(context.Background(), created.ID, []string{"early adopter"}, 0)
+		var invalid *domain.ValidationError
+		if !errors.As(err, &invalid) || invalid.Fields[0].Field != "tags" {
+			t.Errorf("expected a tags validation error, got %v", err)
+		}
+	})
+}
+
+func TestUserService_PatchUserMetadata(t *testing.T) {
+	t.Run("Patches merge into existing keys", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, err := service.PatchUserMetadata(context.Background(), created.ID, map[string]any{"plan": "pro", "seats": 5.0}, 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := service.PatchUserMetadata(context.Background(), created.ID, map[string]any{"plan": nil, "region": "eu"}, 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(got.Metadata) != 2 || got.Metadata["seats"] != 5.0 || got.Metadata["region"] != "eu" {
+			t.Errorf("expected seats and region, got %v", got.Metadata)
+		}
+	})
+
+	t.Run("Too many keys", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		patch := make(map[string]any)
+		for i := range domain.MaxMetadataKeys + 1 {
+			patch[fmt.Sprint(i)] = true
+		}
+		_, err := service.PatchUserMetadata(context.Background(), created.ID, patch, 0)
+		var invalid *domain.ValidationError
+		if !errors.As(err, &invalid) || invalid.Fields[0].Field != "metadata" {
+			t.Errorf("expected a metadata validation error, got %v", err)
+		}
+	})
+
+	t.Run("Oversized values", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		patch := map[string]any{"notes": strings.Repeat("x", domain.MaxMetadataBytes)}
+		if _, err := service.PatchUserMetadata(context.Background(), created.ID, patch, 0); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestUserService_ExternalIDs(t *testing.T) {
+	t.Run("Linked identities are found after normalization", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, err := service.SetUserExternalIDs(context.Background(), created.ID, map[string]string{"GitHub": " 583231 "}, 0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := service.GetUserByExternalID(context.Background(), " github", "583231 ")
+		if err != nil || got.ID != created.ID {
+			t.Errorf("expected user %d, got %v, %v", created.ID, got, err)
+		}
+	})
+
+	t.Run("Blank lookups are invalid", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		for _, q := range [][2]string{{"", "1"}, {"google", " "}} {
+			if _, err := service.GetUserByExternalID(context.Background(), q[0], q[1]); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("expected ErrInvalidInput for %q, got %v", q, err)
+			}
+		}
+	})
+
+	t.Run("Malformed providers", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, err := service.SetUserExternalIDs(context.Background(), created.ID, map[string]string{"open id": "1"}, 0)
+		if !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
+
+func TestUserService_UserStats(t *testing.T) {
+	t.Run("Stats are cached until users change", func(t *testing.T) {
+		repo := NewMockUserRepository()
+		service := NewUserService(repo)
+		_, _ = service.CreateUser(context.Background(), "Ann", "ann@corp.com", "")
+		_, _ = service.CreateUser(context.Background(), "Bob", "bob@example.com", "")
+
+		q := domain.StatsQuery{GroupBy: domain.GroupByEmailDomain}
+		stats, err := service.UserStats(context.Background(), q)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if stats.Total != 2 || len(stats.Buckets) != 2 || stats.Buckets[0].Key != "corp.com" {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+		_, _ = service.UserStats(context.Background(), q)
+		if repo.statsCalls != 1 {
+			t.Errorf("expected 1 repository call, got %d", repo.statsCalls)
+		}
+
+		repo.lastModified = repo.lastModified.Add(time.Second)
+		_, _ = service.UserStats(context.Background(), q)
+		if repo.statsCalls != 2 {
+			t.Errorf("expected cache to be invalidated, got %d repository calls", repo.statsCalls)
+		}
+	})
+
+	t.Run("Defaults to daily creation buckets", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		stats, err := service.UserStats(context.Background(), domain.StatsQuery{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if stats.GroupBy != domain.GroupByCreated || stats.Bucket != domain.BucketDay {
+			t.Errorf("unexpected defaults %+v", stats)
+		}
+	})
+
+	t.Run("Invalid query", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		if _, err := service.UserStats(context.Background(), domain.StatsQuery{GroupBy: "status"}); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
+
+func TestUserService_CountUsers(t *testing.T) {
+	t.Run("Counts the filtered users", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		_, _ = service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Bob", "bob@example.com", "")
+
+		n, err := service.CountUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{NameContains: "doe"}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected 2 users, got %d", n)
+		}
+	})
+
+	t.Run("Organization members", func(t *testing.T) {
+		orgs := memory.NewInMemoryOrganizationRepository()
+		service := NewUserService(memory.NewInMemoryUserRepository(), WithOrganizations(orgs))
+		john, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.CreateUser(context.Background(), "Jane Doe", "jane@example.com", "")
+		org, _ := orgs.Create(context.Background(), &domain.Organization{Name: "Acme"})
+		_ = orgs.AddMember(context.Background(), org.ID, john.ID)
+
+		n, err := service.CountUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: org.ID}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 member, got %d", n)
+		}
+		if _, err := service.CountUsers(context.Background(), domain.Filter{UserFilter: domain.UserFilter{OrgID: 999}}); !errors.Is(err, domain.ErrOrgNotFound) {
+			t.Errorf("expected ErrOrgNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		if _, err := service.CountUsers(context.Background(), domain.Filter{Status: "bogus"}); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+}
+
+func TestUserService_Status(t *testing.T) {
+	t.Run("New users are active", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		user, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		if user.Status != domain.StatusActive {
+			t.Errorf("expected status active, got %q", user.Status)
+		}
+	})
+
+	t.Run("Suspend then activate", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		suspended, err := service.SuspendUser(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if suspended.Status != domain.StatusSuspended {
+			t.Errorf("expected status suspended, got %q", suspended.Status)
+		}
+		activated, err := service.ActivateUser(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if activated.Status != domain.StatusActive {
+			t.Errorf("expected status active, got %q", activated.Status)
+		}
+	})
+
+	t.Run("Activating an active user is rejected", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		_, err := service.ActivateUser(context.Background(), created.ID)
+		if !errors.Is(err, domain.ErrInvalidTransition) {
+			t.Errorf("expected ErrInvalidTransition, got %v", err)
+		}
+	})
+
+	t.Run("Update hooks see the transition", func(t *testing.T) {
+		hooks := NewHooks()
+		var seen domain.UserStatus
+		hooks.Register(PreUpdate, func(ctx context.Context, u *domain.User) error {
+			seen = u.Status
+			return nil
+		})
+		service := NewUserService(NewMockUserRepository(), WithHooks(hooks))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+
+		_, _ = service.SuspendUser(context.Background(), created.ID)
+		if seen != domain.StatusSuspended {
+			t.Errorf("expected hook to see suspended, got %q", seen)
+		}
+	})
+
+	t.Run("Name updates keep the status", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		_, _ = service.SuspendUser(context.Background(), created.ID)
+
+		updated, _ := service.UpdateUser(context.Background(), created.ID, "Jane Doe", "jane@example.com", "", 0)
+		if updated.Status != domain.StatusSuspended {
+			t.Errorf("expected status suspended, got %q", updated.Status)
+		}
+	})
+}
+
+// recordingBus keeps published events for inspection.
+type recordingBus struct {
+	events []domain.Event
+}
+
+func (b *recordingBus) Publish(ctx context.Context, e domain.Event) {
+	b.events = append(b.events, e)
+}
+
+func (b *recordingBus) Subscribe(h domain.EventHandler, types ...domain.EventType) {}
+
+func TestUserService_Events(t *testing.T) {
+	t.Run("Publishes each stored mutation", func(t *testing.T) {
+		bus := &recordingBus{}
+		service := NewUserService(NewMockUserRepository(), WithEvents(bus))
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		id := created.ID
+		_, _ = service.UpdateUser(context.Background(), id, "Jane Doe", "jane@example.com", "", 0)
+		_, _ = service.SuspendUser(context.Background(), id)
+		_ = service.DeleteUser(context.Background(), id, 0, false)
+
+		want := []domain.EventType{domain.UserCreated, domain.UserUpdated, domain.UserUpdated, domain.UserDeleted}
+		if len(bus.events) != len(want) {
+			t.Fatalf("expected %d events, got %d", len(want), len(bus.events))
+		}
+		for i, e := range bus.events {
+			if e.Type != want[i] || e.User.ID != id || e.At.IsZero() {
+				t.Errorf("expected %s for user %d, got %+v", want[i], id, e)
+			}
+		}
+		if bus.events[2].User.Status != domain.StatusSuspended {
+			t.Errorf("expected the suspended user in the event, got %q", bus.events[2].User.Status)
+		}
+	})
+
+	t.Run("Events name the principal", func(t *testing.T) {
+		bus := &recordingBus{}
+		service := NewUserService(NewMockUserRepository(), WithEvents(bus))
+		by := domain.Principal{Service: "billing", SubjectID: 3}
+		_, _ = service.CreateUser(domain.WithPrincipal(context.Background(), by), "John Doe", "john@example.com", "")
+		if len(bus.events) != 1 || bus.events[0].By != by {
+			t.Errorf("expected an event by %v, got %+v", by, bus.events)
+		}
+	})
+
+	t.Run("Failed mutations publish nothing", func(t *testing.T) {
+		bus := &recordingBus{}
+		service := NewUserService(NewMockUserRepository(), WithEvents(bus))
+		_, _ = service.CreateUser(context.Background(), "", "john@example.com", "")
+		_ = service.DeleteUser(context.Background(), 999, 0, false)
+		if len(bus.events) != 0 {
+			t.Errorf("expected no events, got %v", bus.events)
+		}
+	})
+}
+
+func TestUserService_Role(t *testing.T) {
+	t.Run("Users are members by default", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		user, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		if user.Role != domain.RoleMember {
+			t.Errorf("expected role member, got %q", user.Role)
+		}
+	})
+
+	t.Run("Update changes the role only when given", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", domain.RoleViewer)
+		id := created.ID
+
+		updated, _ := service.UpdateUser(context.Background(), id, "Jane Doe", "jane@example.com", "", 0)
+		if updated.Role != domain.RoleViewer {
+			t.Errorf("expected role viewer, got %q", updated.Role)
+		}
+		updated, _ = service.UpdateUser(context.Background(), id, "Jane Doe", "jane@example.com", domain.RoleAdmin, 0)
+		if updated.Role != domain.RoleAdmin {
+			t.Errorf("expected role admin, got %q", updated.Role)
+		}
+	})
+
+	t.Run("Unknown roles are rejected", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository())
+		if _, err := service.CreateUser(context.Background(), "John Doe", "john@example.com", "owner"); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		created, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		if _, err := service.UpdateUser(context.Background(), created.ID, "John Doe", "john@example.com", "owner", 0); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("Only admins assign roles when guarded", func(t *testing.T) {
+		service := NewUserService(NewMockUserRepository(), WithRoleAuthorization())
+		asService := domain.WithPrincipal(context.Background(), domain.Principal{Service: "provisioning"})
+		admin, err := service.CreateUser(asService, "Ann Admin", "ann@example.com", domain.RoleAdmin)
+		if err != nil {
+			t.Fatalf("expected a service account to create an admin, got %v", err)
+		}
+		member, _ := service.CreateUser(context.Background(), "John Doe", "john@example.com", "")
+		asMember := domain.WithPrincipal(context.Background(), domain.Principal{ActorID: member.ID, SubjectID: member.ID})
+		asAdmin := domain.WithPrincipal(context.Background(), domain.Principal{ActorID: admin.ID, SubjectID: admin.ID})
+
+		if _, err := service.CreateUser(asMember, "Eve", "eve@example.com", domain.RoleAdmin); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("expected ErrForbidden creating an admin, got %v", err)
+		}
+		if _, err := service.UpdateUser(asMember, member.ID, "John Doe", "john@example.com", domain.RoleAdmin, 0); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("expected ErrForbidden promoting oneself, got %v", err)
+		}
+		if _, err := service.UpdateUser(asMember, admin.ID, "Ann Admin", "ann@example.com", domain.RoleMember, 0); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("expected ErrForbidden demoting an admin, got %v", err)
+		}
+		if _, err := service.UpdateUser(asMember, member.ID, "John Doe", "john@example.com", domain.RoleMember, 0); err != nil {
+			t.Errorf("expected an unchanged role to pass, got %v", err)
+		}
+		asImpersonator := domain.WithPrincipal(context.Background(), domain.Principal{Service: "provisioning", SubjectID: member.ID})
+		if _, err := service.CreateUser(asImpersonator, "Eve", "eve@example.com", domain.RoleViewer); !errors.Is(err, domain.ErrForbidden) {
+			t.Errorf("expected ErrForbidden for a service acting as a member, got %v", err)
+		}
+		updated, err := service.UpdateUser(asAdmin, member.ID, "John Doe", "john@example.com", domain.RoleViewer, 0)
+		if err != nil || updated.Role != domain.RoleViewer {
+			t.Errorf("expected an admin to change the role, got %v, %v", updated, err)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"cleanarch/internal/domain"
+)
+
+// ViewUsecase is the saved-view boundary consumed by delivery adapters.
+type ViewUsecase interface {
+	CreateView(ctx context.Context, name, filter string, sortBy domain.SortField) (*domain.View, error)
+	GetView(ctx context.Context, id int64) (*domain.View, error)
+	ListViews(ctx context.Context) ([]*domain.View, error)
+	DeleteView(ctx context.Context, id int64) error
+	Results(ctx context.Context, id int64, page domain.Filter) (*domain.View, *domain.Page[domain.User], error)
+}
+
+var _ ViewUsecase = (*ViewService)(nil)
+
+// ViewService manages saved views and executes them against the user use case.
+type ViewService struct {
+	views domain.ViewRepository
+	users UserUsecase
+}
+
+func NewViewService(views domain.ViewRepository, users UserUsecase) *ViewService {
+	return &ViewService{views: views, users: users}
+}
+
+// CreateView saves a named query after checking that it parses.
+func (s *ViewService) CreateView(ctx context.Context, name, filter string, sortBy domain.SortField) (*domain.View, error) {
+	view := &domain.View{Name: strings.TrimSpace(name), Filter: strings.TrimSpace(filter), SortBy: sortBy}
+	if err := domain.Validate(view); err != nil {
+		return nil, err
+	}
+	if _, err := view.Query(); err != nil {
+		return nil, err
+	}
+	return s.views.Create(ctx, view)
+}
+
+func (s *ViewService) GetView(ctx context.Context, id int64) (*domain.View, error) {
+	return s.views.GetByID(ctx, id)
+}
+
+func (s *ViewService) ListViews(ctx context.Context) ([]*domain.View, error) {
+	return s.views.List(ctx)
+}
+
+func (s *ViewService) DeleteView(ctx context.Context, id int64) error {
+	return s.views.Delete(ctx, id)
+}
+
+// Results lists the users matching the view. Only the page request and
+// Cursor are taken from page; the view supplies the filter and sort order.
+func (s *ViewService) Results(ctx context.Context, id int64, page domain.Filter) (*domain.View, *domain.Page[domain.User], error) {
+	view, err := s.views.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter, err := view.Query()
+	if err != nil {
+		return view, nil, err
+	}
+	filter.PageRequest = page.PageRequest
+	filter.Cursor = page.Cursor
+	users, err := s.users.ListUsers(ctx, filter)
+	return view, users, err
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cleanarch/internal/domain"
+)
+
+// mockViewRepository implements domain.ViewRepository for testing
+type mockViewRepository struct {
+	views map[int64]*domain.View
+}
+
+func (m *mockViewRepository) Create(ctx context.Context, view *domain.View) (*domain.View, error) {
+	if m.views == nil {
+		m.views = make(map[int64]*domain.View)
+	}
+	view.ID = int64(len(m.views) + 1)
+	m.views[view.ID] = view
+	return view, nil
+}
+
+func (m *mockViewRepository) GetByID(ctx context.Context, id int64) (*domain.View, error) {
+	if v, ok := m.views[id]; ok {
+		return v, nil
+	}
+	return nil, domain.ErrViewNotFound
+}
+
+func (m *mockViewRepository) List(ctx context.Context) ([]*domain.View, error) {
+	var result []*domain.View
+	for _, v := range m.views {
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (m *mockViewRepository) Delete(ctx context.Context, id int64) error {
+	delete(m.views, id)
+	return nil
+}
+
+func TestViewService(t *testing.T) {
+	t.Run("Create view with valid filter", func(t *testing.T) {
+		service := NewViewService(&mockViewRepository{}, NewUserService(NewMockUserRepository()))
+
+		view, err := service.CreateView(context.Background(), " corp ", `email endsWith "@corp.com"`, domain.SortByName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if view.Name != "corp" || view.ID == 0 {
+			t.Errorf("unexpected view %+v", view)
+		}
+	})
+
+	t.Run("Create view rejects invalid input", func(t *testing.T) {
+		service := NewViewService(&mockViewRepository{}, NewUserService(NewMockUserRepository()))
+
+		if _, err := service.CreateView(context.Background(), "", "", ""); err == nil {
+			t.Error("expected error for empty name")
+		}
+		if _, err := service.CreateView(context.Background(), "bad", `id >`, ""); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+		if _, err := service.CreateView(context.Background(), "bad", "", "password"); !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter for sort, got %v", err)
+		}
+	})
+
+	t.Run("Results apply the view filter and page", func(t *testing.T) {
+		var got domain.Filter
+		users := &filterRecorder{UserUsecase: NewUserService(NewMockUserRepository()), got: &got}
+		service := NewViewService(&mockViewRepository{}, users)
+		view, _ := service.CreateView(context.Background(), "corp", `email endsWith "@corp.com"`, domain.SortByEmail)
+
+		if _, _, err := service.Results(context.Background(), view.ID, domain.Filter{PageRequest: domain.PageRequest{Limit: 5}, UserFilter: domain.UserFilter{NameContains: "ignored"}}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Expr == nil || got.Sort.Field != domain.SortByEmail || got.Limit != 5 || got.NameContains != "" {
+			t.Errorf("unexpected filter %+v", got)
+		}
+	})
+}
+
+type filterRecorder struct {
+	UserUsecase
+	got *domain.Filter
+}
+
+func (f *filterRecorder) ListUsers(ctx context.Context, filter domain.Filter) (*domain.Page[domain.User], error) {
+	*f.got = filter
+	return f.UserUsecase.ListUsers(ctx, filter)
+}